@@ -0,0 +1,111 @@
+// allinone_server runs the greet, calculator and blog services in one process on one port.
+// Use --services to pick which ones get registered, e.g. --services=greet,calculator
+// runs without needing MongoDB
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/blog/blogservice"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/greet/greetservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/metrics"
+	"github.com/angel/golang_api_microservice/internal/tracing"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// knownServices are the names accepted by --services
+var knownServices = []string{"greet", "calculator", "blog"}
+
+// parseServices turns the --services value into a set, rejecting unknown names
+func parseServices(list string) (map[string]bool, error) {
+	enabled := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, k := range knownServices {
+			if k == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown service %q, must be one of %v", name, strings.Join(knownServices, ", "))
+		}
+		enabled[name] = true
+	}
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("no services selected")
+	}
+	return enabled, nil
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	services := flag.String("services", strings.Join(knownServices, ","), "comma separated list of services to run")
+	mongoCfg := blogservice.MongoConfig{}
+	mongoCfg.RegisterFlags(flag.CommandLine)
+
+	cfg, err := bootstrap.LoadConfig("allinone", "0.0.0.0:50051", "0.0.0.0:9050")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	enabled, err := parseServices(*services)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	s, err := bootstrap.New(cfg, bootstrap.WithAuthPolicy(blogservice.AuthPolicy))
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	if enabled["greet"] {
+		greetpb.RegisterGreetServiceServer(s.GRPC, greetservice.NewServer())
+	}
+	if enabled["calculator"] {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+	}
+	if enabled["blog"] {
+		if err := mongoCfg.Validate(); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+
+		fmt.Println("Connecting to MongoDb")
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		monitor := options.Client().SetMonitor(blogservice.Monitors(
+			metrics.NewMongoMonitor(s.Metrics.Registry),
+			tracing.MongoMonitor(),
+		))
+		client, collection, err := mongoCfg.Connect(ctx, monitor)
+		if err != nil {
+			log.Fatal(err)
+		}
+		blogpb.RegisterBlogServiceServer(s.GRPC, blogservice.NewServer(collection, blogservice.DefaultModerator()))
+
+		s.AddHealthCheck("blog.BlogService", mongoCfg.PingInterval, blogservice.HealthCheck(client))
+		s.OnShutdown(func(ctx context.Context) {
+			fmt.Println("Closing MongoDB Connection")
+			client.Disconnect(ctx)
+		})
+	}
+
+	fmt.Printf("Starting all-in-one server with %v\n", *services)
+	if err := s.Run(); err != nil {
+		log.Fatalf("Failed to serve: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+)
+
+func init() {
+	commands["blog create"] = command{usage: "--title TEXT [--author ID] [--content TEXT]", help: "write a blog, it waits for review if moderation flags it (CreateBlog)", run: blogCreate}
+	commands["blog read"] = command{usage: "ID", help: "show a blog with its content (ReadBlog)", run: blogRead}
+	commands["blog update"] = command{usage: "--title TEXT [--content TEXT] ID", help: "replace the title and content of a blog (UpdateBlog)", run: blogUpdate}
+	commands["blog delete"] = command{usage: "ID", help: "delete a blog, as its author or an admin (DeleteBlog)", run: blogDelete}
+	commands["blog list"] = command{usage: "", help: "list the published blogs (ListBlogs)", run: blogList}
+	commands["blog queue"] = command{usage: "", help: "list the blogs waiting for review (ListModerationQueue)", run: blogQueue}
+	commands["blog approve"] = command{usage: "ID", help: "publish a blog waiting for review (ApproveContent)", run: blogApprove}
+	commands["blog reject"] = command{usage: "[--reason TEXT] ID", help: "reject a blog waiting for review (RejectContent)", run: blogReject}
+}
+
+// blogText is the text output for a blog
+func blogText(b *blogpb.Blog) string {
+	text := fmt.Sprintf("%v %q by %v, %v", b.GetId(), b.GetTitle(), b.GetAuthorId(), b.GetStatus())
+	if len(b.GetModerationReasons()) > 0 {
+		text += fmt.Sprintf(", flagged for %v", b.GetModerationReasons())
+	}
+	return text
+}
+
+func blogCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("blog create", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	author := fs.String("author", "", "ID of the author")
+	title := fs.String("title", "", "title of the blog")
+	content := fs.String("content", "", "content of the blog")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("blog create takes no arguments")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	b, err := c.CreateBlog(ctx, &blogpb.Blog{AuthorId: *author, Title: *title, Content: *content})
+	if err != nil {
+		return err
+	}
+	return e.out.message(b, blogText(b))
+}
+
+func blogRead(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a blog ID")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	b, err := c.ReadBlog(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.out.message(b, blogText(b)+"\n"+b.GetContent())
+}
+
+func blogUpdate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("blog update", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	title := fs.String("title", "", "new title of the blog")
+	content := fs.String("content", "", "new content of the blog")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a blog ID")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	b, err := c.UpdateBlog(ctx, &blogpb.Blog{Id: fs.Arg(0), Title: *title, Content: *content})
+	if err != nil {
+		return err
+	}
+	return e.out.message(b, blogText(b))
+}
+
+func blogDelete(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a blog ID")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteBlog(ctx, args[0]); err != nil {
+		return err
+	}
+	return e.out.message(&blogpb.DeleteBlogResponse{BlogId: args[0]}, "deleted "+args[0])
+}
+
+func blogList(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("blog list takes no arguments")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	return c.ListBlogs(ctx, func(b *blogpb.Blog) error {
+		return e.out.message(b, blogText(b))
+	})
+}
+
+func blogQueue(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("blog queue takes no arguments")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	return c.ListModerationQueue(ctx, func(b *blogpb.Blog) error {
+		return e.out.message(b, blogText(b))
+	})
+}
+
+func blogApprove(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a blog ID")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	b, err := c.ApproveContent(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.out.message(b, blogText(b))
+}
+
+func blogReject(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("blog reject", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	reason := fs.String("reason", "", "why the blog is rejected, shown to its author")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a blog ID")
+	}
+	c, err := e.blogClient()
+	if err != nil {
+		return err
+	}
+	b, err := c.RejectContent(ctx, fs.Arg(0), *reason)
+	if err != nil {
+		return err
+	}
+	return e.out.message(b, blogText(b))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+func init() {
+	commands["calc sum"] = command{usage: "A B", help: "add two numbers (Sum)", run: calcSum}
+	commands["calc primes"] = command{usage: "N", help: "stream the prime factors of N (PrimeNumberDecomposition)", run: calcPrimes}
+	commands["calc avg"] = command{usage: "[N...]", help: "average the numbers sent (ComputeAverage)", run: calcAvg}
+	commands["calc max"] = command{usage: "[N...]", help: "running maximum of the numbers sent (FindMaximum)", run: calcMax}
+	commands["calc sqrt"] = command{usage: "N", help: "square root of N (SquareRoot)", run: calcSqrt}
+}
+
+func parseInt32(s string) (int32, error) {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return int32(n), nil
+}
+
+// numberArgs parses exactly count numeric arguments
+func numberArgs(args []string, count int) ([]int32, error) {
+	if len(args) != count {
+		return nil, fmt.Errorf("expected %v numbers, got %v", count, len(args))
+	}
+	numbers := make([]int32, 0, count)
+	for _, a := range args {
+		n, err := parseInt32(a)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+func calcSum(ctx context.Context, e *env, args []string) error {
+	numbers, err := numberArgs(args, 2)
+	if err != nil {
+		return err
+	}
+	c, err := e.calcClient()
+	if err != nil {
+		return err
+	}
+	sum, err := c.Sum(ctx, numbers[0], numbers[1])
+	if err != nil {
+		return err
+	}
+	return e.out.value("sum_result", sum)
+}
+
+func calcPrimes(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected 1 number, got %v", len(args))
+	}
+	n, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", args[0])
+	}
+	c, err := e.calcClient()
+	if err != nil {
+		return err
+	}
+	return c.PrimeFactors(ctx, n, func(factor int64) error {
+		return e.out.value("prime_factor", factor)
+	})
+}
+
+func calcAvg(ctx context.Context, e *env, args []string) error {
+	numbers := []int32{}
+	err := e.in.items(args, func(item string) error {
+		n, err := parseInt32(item)
+		numbers = append(numbers, n)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	c, err := e.calcClient()
+	if err != nil {
+		return err
+	}
+	avg, err := c.Average(ctx, numbers)
+	if err != nil {
+		return err
+	}
+	return e.out.value("average", avg)
+}
+
+// calcMax sends the numbers while printing every new maximum as it comes
+func calcMax(ctx context.Context, e *env, args []string) error {
+	c, err := e.calcClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.Maximum(ctx)
+	if err != nil {
+		return err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		err := e.in.items(args, func(item string) error {
+			n, err := parseInt32(item)
+			if err != nil {
+				return err
+			}
+			return stream.Send(n)
+		})
+		if err != nil && err != io.EOF {
+			// stop the call, the error is reported instead of the cancellation
+			sendErr <- err
+			cancel()
+			return
+		}
+		stream.CloseSend()
+	}()
+
+	for {
+		max, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			select {
+			case err = <-sendErr:
+			default:
+			}
+			return err
+		}
+		if err := e.out.value("maximum", max); err != nil {
+			return err
+		}
+	}
+}
+
+func calcSqrt(ctx context.Context, e *env, args []string) error {
+	numbers, err := numberArgs(args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := e.calcClient()
+	if err != nil {
+		return err
+	}
+	root, err := c.SquareRoot(ctx, numbers[0])
+	if err != nil {
+		return err
+	}
+	return e.out.value("number_root", root)
+}
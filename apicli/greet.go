@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+func init() {
+	commands["greet once"] = command{usage: "--first NAME [--last NAME]", help: "greet someone (Greet)", run: greetOnce}
+	commands["greet many"] = command{usage: "--first NAME [--last NAME]", help: "stream ten greetings (GreetManyTimes)", run: greetMany}
+	commands["greet long"] = command{usage: "[NAME...]", help: "greet everyone sent in one go (LongGreet)", run: greetLong}
+	commands["greet everyone"] = command{usage: "[NAME...]", help: "greet everyone as they are sent (GreetEveryone)", run: greetEveryone}
+	commands["greet deadline"] = command{usage: "--first NAME [--last NAME] [--deadline 5s]", help: "slow greeting with a deadline (GreetWithDeadline)", run: greetDeadline}
+}
+
+// greetingFlags parses --first and --last
+func greetingFlags(name string, args []string, extra func(fs *flag.FlagSet)) (*greetpb.Greeting, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	first := fs.String("first", "", "first name")
+	last := fs.String("last", "", "last name")
+	if extra != nil {
+		extra(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *first == "" {
+		return nil, fmt.Errorf("--first is required")
+	}
+	return &greetpb.Greeting{FirstName: *first, LastName: *last}, nil
+}
+
+// parseGreeting reads a streamed greeting, either "First Last" or a JSON Greeting
+func parseGreeting(s string) (*greetpb.Greeting, error) {
+	g := &greetpb.Greeting{}
+	if strings.HasPrefix(s, "{") {
+		if err := protojson.Unmarshal([]byte(s), protoadapt.MessageV2Of(g)); err != nil {
+			return nil, fmt.Errorf("invalid greeting %q: %v", s, err)
+		}
+		return g, nil
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty greeting")
+	}
+	g.FirstName = fields[0]
+	g.LastName = strings.Join(fields[1:], " ")
+	return g, nil
+}
+
+func greetOnce(ctx context.Context, e *env, args []string) error {
+	g, err := greetingFlags("greet once", args, nil)
+	if err != nil {
+		return err
+	}
+	c, err := e.greetClient()
+	if err != nil {
+		return err
+	}
+	res, err := c.Greet(ctx, g)
+	if err != nil {
+		return err
+	}
+	return e.out.value("result", res)
+}
+
+func greetMany(ctx context.Context, e *env, args []string) error {
+	g, err := greetingFlags("greet many", args, nil)
+	if err != nil {
+		return err
+	}
+	c, err := e.greetClient()
+	if err != nil {
+		return err
+	}
+	return c.GreetManyTimes(ctx, g, func(result string) error {
+		return e.out.value("result", result)
+	})
+}
+
+func greetLong(ctx context.Context, e *env, args []string) error {
+	greetings := []*greetpb.Greeting{}
+	err := e.in.items(args, func(item string) error {
+		g, err := parseGreeting(item)
+		if err != nil {
+			return err
+		}
+		greetings = append(greetings, g)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c, err := e.greetClient()
+	if err != nil {
+		return err
+	}
+	res, err := c.LongGreet(ctx, greetings)
+	if err != nil {
+		return err
+	}
+	return e.out.value("result", res)
+}
+
+// greetEveryone sends the greetings while printing the answers as they come
+func greetEveryone(ctx context.Context, e *env, args []string) error {
+	c, err := e.greetClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.GreetEveryone(ctx)
+	if err != nil {
+		return err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		err := e.in.items(args, func(item string) error {
+			g, err := parseGreeting(item)
+			if err != nil {
+				return err
+			}
+			return stream.Send(g)
+		})
+		if err != nil && err != io.EOF {
+			// stop the call, the error is reported instead of the cancellation
+			sendErr <- err
+			cancel()
+			return
+		}
+		stream.CloseSend()
+	}()
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			select {
+			case err = <-sendErr:
+			default:
+			}
+			return err
+		}
+		if err := e.out.value("result", res); err != nil {
+			return err
+		}
+	}
+}
+
+func greetDeadline(ctx context.Context, e *env, args []string) error {
+	var deadline time.Duration
+	g, err := greetingFlags("greet deadline", args, func(fs *flag.FlagSet) {
+		fs.DurationVar(&deadline, "deadline", 5*time.Second, "deadline of the call, the server takes about 3s")
+	})
+	if err != nil {
+		return err
+	}
+	c, err := e.greetClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withDeadline(ctx, deadline)
+	defer cancel()
+	res, err := c.GreetWithDeadline(ctx, g)
+	if err != nil {
+		return err
+	}
+	return e.out.value("result", res)
+}
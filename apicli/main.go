@@ -0,0 +1,329 @@
+// apicli calls every RPC of the greet, calculator and blog services from the command line:
+//
+//	apicli greet once --first Angel --last Dionisio
+//	apicli calc sum 3 4
+//	seq 1 10 | apicli calc max
+//	apicli --format json blog queue
+//	apicli repl
+//
+// Streaming commands take their input as arguments, or one per line from --input or stdin
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/angel/golang_api_microservice/blog/blogclient"
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/greet/greetclient"
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/config"
+	"github.com/angel/golang_api_microservice/internal/tlsutil"
+	"github.com/angel/golang_api_microservice/internal/tracing"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+)
+
+// command is a subcommand, e.g. "calc sum"
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+// commands are all the subcommands by name, filled in by greet.go, calc.go and blog.go
+var commands = map[string]command{}
+
+// env is what the commands share: the clients, dialed on first use, and where input and output go
+type env struct {
+	targets     map[string]string
+	dialOptions []sdk.Option
+
+	greet *greetclient.Client
+	calc  *calcclient.Client
+	blog  *blogclient.Client
+	conns []*grpc.ClientConn
+
+	out *output
+	in  *input
+}
+
+func main() {
+	log.SetFlags(0)
+
+	format := flag.String("format", "text", "output format: text or json")
+	inputFile := flag.String("input", "-", "file streaming commands read from when they get no arguments, - for stdin")
+	timeout := flag.Duration("timeout", sdk.DefaultTimeout, "deadline of unary calls, 0 for none")
+	greetTarget := flag.String("greet.target", "localhost:50051", "address of the greet server, see the README for targets with several backends")
+	calcTarget := flag.String("calculator.target", "localhost:50053", "address of the calculator server, see the README for targets with several backends")
+	blogTarget := flag.String("blog.target", "localhost:50052", "address of the blog server, see the README for targets with several backends")
+	balancer := flag.String("balancer", sdk.RoundRobin, "how calls are spread over the backends of a target: pick_first, round_robin or least_request")
+	tlsCfg := tlsutil.ClientConfig{}
+	tlsCfg.RegisterFlags(flag.CommandLine)
+	creds := auth.ClientCredentials{}
+	creds.RegisterFlags(flag.CommandLine)
+	tracingCfg := tracing.Config{}
+	tracingCfg.RegisterFlags(flag.CommandLine)
+	flag.Usage = usage
+	if err := config.Load(flag.CommandLine, "apicli", os.Args[1:]); err != nil {
+		if err == config.ErrPrintConfig {
+			return
+		}
+		log.Fatalf("could not load config: %v", err)
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("invalid format %q, must be text or json", *format)
+	}
+	if err := tlsCfg.Validate(); err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+	transport, err := tlsCfg.DialOption()
+	if err != nil {
+		log.Fatalf("could not load TLS credentials: %v", err)
+	}
+	if err := tracingCfg.Validate(); err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+	stopTracing, err := tracing.Setup(context.Background(), "apicli", tracingCfg)
+	if err != nil {
+		log.Fatalf("could not set up tracing: %v", err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *inputFile != "-" {
+		f, err := os.Open(*inputFile)
+		if err != nil {
+			log.Fatalf("could not open input: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	e := &env{
+		targets: map[string]string{
+			greetclient.ServiceName: *greetTarget,
+			calcclient.ServiceName:  *calcTarget,
+			blogclient.ServiceName:  *blogTarget,
+		},
+		dialOptions: []sdk.Option{
+			sdk.WithTransport(transport),
+			sdk.WithTimeout(*timeout),
+			sdk.WithBalancer(*balancer),
+			sdk.WithDialOptions(tracing.DialOption()),
+		},
+		out: &output{w: os.Stdout, json: *format == "json"},
+		in:  newInput(in, false),
+	}
+	// credentials that require TLS would fail every plain text connection, even unused
+	if creds.Enabled() {
+		e.dialOptions = append(e.dialOptions, sdk.WithPerRPCCredentials(creds))
+	}
+
+	args := flag.Args()
+	if len(args) == 1 && args[0] == "repl" {
+		err = e.repl(os.Stdin)
+	} else {
+		err = e.run(context.Background(), args)
+	}
+	e.close()
+	stopTracing(context.Background())
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+}
+
+// run looks the command up from its first two words and runs it with the rest
+func (e *env) run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("expected a command, run apicli -h for the list")
+	}
+	cmd, ok := commands[args[0]+" "+args[1]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run apicli -h for the list", args[0]+" "+args[1])
+	}
+	return cmd.run(ctx, e, args[2:])
+}
+
+// repl runs one command per line until EOF or exit. Streaming commands started
+// without arguments read their input from the next lines, up to an empty line
+func (e *env) repl(r io.Reader) error {
+	e.in = newInput(r, true)
+	fmt.Println("Type a command such as \"calc sum 3 4\", help for the list, exit to quit")
+	for {
+		fmt.Print("> ")
+		line, ok := e.in.next()
+		if !ok {
+			fmt.Println()
+			return nil
+		}
+		args := splitArgs(line)
+		switch {
+		case len(args) == 0:
+			continue
+		case args[0] == "exit" || args[0] == "quit":
+			return nil
+		case args[0] == "help":
+			printCommands(os.Stdout)
+			continue
+		}
+		if err := e.run(context.Background(), args); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+}
+
+func (e *env) dial(service string) (*grpc.ClientConn, error) {
+	cc, err := sdk.Dial(e.targets[service], []string{service}, e.dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %v: %v", e.targets[service], err)
+	}
+	e.conns = append(e.conns, cc)
+	return cc, nil
+}
+
+func (e *env) greetClient() (*greetclient.Client, error) {
+	if e.greet == nil {
+		cc, err := e.dial(greetclient.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		e.greet = greetclient.New(cc)
+	}
+	return e.greet, nil
+}
+
+func (e *env) calcClient() (*calcclient.Client, error) {
+	if e.calc == nil {
+		cc, err := e.dial(calcclient.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		e.calc = calcclient.New(cc)
+	}
+	return e.calc, nil
+}
+
+func (e *env) blogClient() (*blogclient.Client, error) {
+	if e.blog == nil {
+		cc, err := e.dial(blogclient.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		e.blog = blogclient.New(cc)
+	}
+	return e.blog, nil
+}
+
+func (e *env) close() {
+	for _, cc := range e.conns {
+		cc.Close()
+	}
+}
+
+// input hands out the lines streaming commands read, skipping empty ones.
+// In the REPL an empty line ends the input of the current command instead
+type input struct {
+	scanner *bufio.Scanner
+	repl    bool
+}
+
+func newInput(r io.Reader, repl bool) *input {
+	return &input{scanner: bufio.NewScanner(r), repl: repl}
+}
+
+// next returns the next line, false at the end of the input
+func (in *input) next() (string, bool) {
+	for in.scanner.Scan() {
+		line := strings.TrimSpace(in.scanner.Text())
+		if line != "" || in.repl {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// items calls fn with every argument or, without arguments, every input line
+func (in *input) items(args []string, fn func(item string) error) error {
+	if len(args) > 0 {
+		for _, a := range args {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		line, ok := in.next()
+		if !ok || (in.repl && line == "") {
+			return nil
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
+// splitArgs splits a REPL line on spaces, keeping double quoted strings together
+func splitArgs(line string) []string {
+	args := []string{}
+	var cur strings.Builder
+	quoted, started := false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case r == ' ' && !quoted:
+			if started {
+				args = append(args, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: apicli [flags] <command> [arguments]\n       apicli [flags] repl\n\nCommands:\n")
+	printCommands(w)
+	fmt.Fprintf(w, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %v %v\t%v\n", name, commands[name].usage, commands[name].help)
+	}
+	tw.Flush()
+}
+
+// withDeadline returns ctx with a deadline d from now, or ctx itself when d is 0
+func withDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
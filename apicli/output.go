@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// output prints results one per line, as plain text or as JSON objects
+type output struct {
+	w    io.Writer
+	json bool
+	mu   sync.Mutex
+}
+
+// value prints v as is, or as {"key": v} in JSON
+func (o *output) value(key string, v interface{}) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.json {
+		_, err := fmt.Fprintln(o.w, v)
+		return err
+	}
+	data, err := json.Marshal(map[string]interface{}{key: v})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.w, "%s\n", data)
+	return err
+}
+
+// message prints text, or m with its proto field names in JSON
+func (o *output) message(m proto.Message, text string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.json {
+		_, err := fmt.Fprintln(o.w, text)
+		return err
+	}
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,151 @@
+// Package blogclient is the Go client of the BlogService. Errors are *sdk.Error values
+package blogclient
+
+import (
+	"context"
+	"io"
+
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the full name of the BlogService, for sdk.Dial
+const ServiceName = "blog.BlogService"
+
+// IdempotentMethods are the methods safe to call twice, for sdk.HedgingPolicy.
+// The writes are left out: a hedged CreateBlog could store the blog twice
+var IdempotentMethods = []string{
+	"/blog.BlogService/ReadBlog",
+}
+
+// Client calls the BlogService, it is safe for concurrent use
+type Client struct {
+	rpc blogpb.BlogServiceClient
+	cc  *grpc.ClientConn
+}
+
+// Dial connects to the blog server at target
+func Dial(target string, opts ...sdk.Option) (*Client, error) {
+	cc, err := sdk.Dial(target, []string{ServiceName}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := New(cc)
+	c.cc = cc
+	return c, nil
+}
+
+// New returns a client using cc, which can be shared with other clients, see sdk.Dial
+func New(cc *grpc.ClientConn) *Client {
+	return &Client{rpc: blogpb.NewBlogServiceClient(cc)}
+}
+
+// Close closes the connection opened by Dial, it does nothing for clients made with New
+func (c *Client) Close() error {
+	if c.cc == nil {
+		return nil
+	}
+	return c.cc.Close()
+}
+
+// CreateBlog stores a new blog and returns it with its ID. Its status says whether
+// it was published or is waiting for review
+func (c *Client) CreateBlog(ctx context.Context, blog *blogpb.Blog) (*blogpb.Blog, error) {
+	res, err := c.rpc.CreateBlog(ctx, &blogpb.CreateBlogRequest{Blog: blog})
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return res.GetBlog(), nil
+}
+
+// ReadBlog returns the blog with the ID
+func (c *Client) ReadBlog(ctx context.Context, blogID string) (*blogpb.Blog, error) {
+	res, err := c.rpc.ReadBlog(ctx, &blogpb.ReadBlogRequest{BlogId: blogID})
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return res.GetBlog(), nil
+}
+
+// UpdateBlog replaces the title and content of the blog with blog's ID and returns it,
+// the caller must be its author or an admin
+func (c *Client) UpdateBlog(ctx context.Context, blog *blogpb.Blog) (*blogpb.Blog, error) {
+	res, err := c.rpc.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: blog})
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return res.GetBlog(), nil
+}
+
+// DeleteBlog deletes a blog, the caller must be its author or an admin
+func (c *Client) DeleteBlog(ctx context.Context, blogID string) error {
+	if _, err := c.rpc.DeleteBlog(ctx, &blogpb.DeleteBlogRequest{BlogId: blogID}); err != nil {
+		return sdk.FromError(err)
+	}
+	return nil
+}
+
+// ListBlogs calls fn with every published blog.
+// An error from fn cancels the stream and is returned
+func (c *Client) ListBlogs(ctx context.Context, fn func(blog *blogpb.Blog) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.ListBlogs(ctx, &blogpb.ListBlogsRequest{})
+	if err != nil {
+		return sdk.FromError(err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return sdk.FromError(err)
+		}
+		if err := fn(res.GetBlog()); err != nil {
+			return err
+		}
+	}
+}
+
+// ListModerationQueue calls fn with every blog waiting for review.
+// An error from fn cancels the stream and is returned
+func (c *Client) ListModerationQueue(ctx context.Context, fn func(blog *blogpb.Blog) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.ListModerationQueue(ctx, &blogpb.ListModerationQueueRequest{})
+	if err != nil {
+		return sdk.FromError(err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return sdk.FromError(err)
+		}
+		if err := fn(res.GetBlog()); err != nil {
+			return err
+		}
+	}
+}
+
+// ApproveContent publishes a blog waiting for review and returns it
+func (c *Client) ApproveContent(ctx context.Context, blogID string) (*blogpb.Blog, error) {
+	res, err := c.rpc.ApproveContent(ctx, &blogpb.ApproveContentRequest{BlogId: blogID})
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return res.GetBlog(), nil
+}
+
+// RejectContent rejects a blog waiting for review and returns it
+func (c *Client) RejectContent(ctx context.Context, blogID, reason string) (*blogpb.Blog, error) {
+	res, err := c.rpc.RejectContent(ctx, &blogpb.RejectContentRequest{BlogId: blogID, Reason: reason})
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return res.GetBlog(), nil
+}
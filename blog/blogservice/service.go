@@ -0,0 +1,313 @@
+// Package blogservice implements the BlogService defined in blog.proto on top of MongoDB
+package blogservice
+
+import (
+	"context"
+
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/blog/moderation"
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements blogpb.BlogServiceServer
+type Server struct {
+	blogpb.UnimplementedBlogServiceServer
+
+	collection *mongo.Collection
+	// moderator is the hook that checks blog content on writes
+	moderator moderation.Moderator
+}
+
+// NewServer returns a BlogService that stores blogs in collection and
+// runs moderator on writes, a nil moderator publishes everything
+func NewServer(collection *mongo.Collection, moderator moderation.Moderator) *Server {
+	return &Server{
+		collection: collection,
+		moderator:  moderator,
+	}
+}
+
+// AuthPolicy is the per-method authorization of the BlogService, methods that
+// aren't listed are open to any authenticated caller. Rules that depend on the
+// blog itself, like only the author or an admin may delete it, are checked in
+// ReadBlog, UpdateBlog and DeleteBlog with auth.RequireOwnerOrRole. With auth off
+// neither is enforced
+var AuthPolicy = auth.Policy{
+	"/blog.BlogService/ListModerationQueue": {Roles: []string{"moderator", "admin"}},
+	"/blog.BlogService/ApproveContent":      {Roles: []string{"moderator", "admin"}},
+	"/blog.BlogService/RejectContent":       {Roles: []string{"moderator", "admin"}},
+}
+
+type blogItem struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty"`
+	AuthorId          string             `bson:"author_id"`
+	Content           string             `bson:"content"`
+	Title             string             `bson:"title"`
+	Status            blogpb.BlogStatus  `bson:"status"`
+	ModerationReasons []string           `bson:"moderation_reasons"`
+}
+
+// defaultProfanity is the word list used by the profanity filter
+var defaultProfanity = []string{"damn", "crap", "idiot"}
+
+// DefaultModerator returns the moderation hook the blog server runs on writes
+func DefaultModerator() moderation.Moderator {
+	return moderation.Chain{
+		moderation.NewProfanityFilter(defaultProfanity),
+		moderation.LinkLimit{Max: 3},
+		moderation.SpamScore{Threshold: 0.5},
+	}
+}
+
+// moderate runs the moderation hook on a blog item before it is written.
+// Flagged items are put in PENDING_REVIEW so they show up in the moderation queue,
+// CreateBlog and UpdateBlog call it before writing
+func (s *Server) moderate(item *blogItem) {
+	if s.moderator == nil {
+		item.Status = blogpb.BlogStatus_PUBLISHED
+		return
+	}
+
+	res := s.moderator.Check(moderation.Content{Title: item.Title, Content: item.Content})
+	if res.Flagged {
+		item.Status = blogpb.BlogStatus_PENDING_REVIEW
+		item.ModerationReasons = res.Reasons
+		return
+	}
+	item.Status = blogpb.BlogStatus_PUBLISHED
+	item.ModerationReasons = nil
+}
+
+func dataToBlogPb(data *blogItem) *blogpb.Blog {
+	return &blogpb.Blog{
+		Id:                data.ID.Hex(),
+		AuthorId:          data.AuthorId,
+		Content:           data.Content,
+		Title:             data.Title,
+		Status:            data.Status,
+		ModerationReasons: data.ModerationReasons,
+	}
+}
+
+// CreateBlog stores a new blog, it is published unless the moderation hook flags it
+func (s *Server) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
+	logging.FromContext(ctx).Info("CreateBlog request")
+
+	blog := req.GetBlog()
+	if blog == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "blog is required")
+	}
+	data := &blogItem{
+		AuthorId: blog.GetAuthorId(),
+		Title:    blog.GetTitle(),
+		Content:  blog.GetContent(),
+	}
+	// with auth on, callers can only write blogs as themselves
+	if p, ok := auth.FromContext(ctx); ok {
+		data.AuthorId = p.Subject
+	}
+	s.moderate(data)
+
+	res, err := s.collection.InsertOne(ctx, data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot insert blog in MongoDB: %v", err)
+	}
+	oid, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "cannot convert to OID")
+	}
+	data.ID = oid
+	return &blogpb.CreateBlogResponse{Blog: dataToBlogPb(data)}, nil
+}
+
+// ReadBlog returns a blog. Blogs waiting for review or rejected are only shown to their
+// author, moderators and admins, anyone else gets NotFound
+func (s *Server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
+	logging.FromContext(ctx).Info("ReadBlog request", "blog_id", req.GetBlogId())
+
+	oid, err := primitive.ObjectIDFromHex(req.GetBlogId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot parse ID")
+	}
+
+	data := &blogItem{}
+	if err := s.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "cannot find blog with specified ID: %v", req.GetBlogId())
+		}
+		return nil, status.Errorf(codes.Internal, "cannot find blog in MongoDB: %v", err)
+	}
+	if data.Status != blogpb.BlogStatus_PUBLISHED && auth.RequireOwnerOrRole(ctx, data.AuthorId, "moderator", "admin") != nil {
+		return nil, status.Errorf(codes.NotFound, "cannot find blog with specified ID: %v", req.GetBlogId())
+	}
+	return &blogpb.ReadBlogResponse{Blog: dataToBlogPb(data)}, nil
+}
+
+// UpdateBlog replaces the title and content of a blog and runs the moderation hook on
+// them again, the author stays the same. Only the author or an admin may update a blog
+func (s *Server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
+	blog := req.GetBlog()
+	logging.FromContext(ctx).Info("UpdateBlog request", "blog_id", blog.GetId())
+
+	oid, err := primitive.ObjectIDFromHex(blog.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot parse ID")
+	}
+	if err := s.requireAuthorOrAdmin(ctx, oid); err != nil {
+		return nil, err
+	}
+	data := &blogItem{Title: blog.GetTitle(), Content: blog.GetContent()}
+	s.moderate(data)
+
+	update := bson.M{"$set": bson.M{
+		"title":              data.Title,
+		"content":            data.Content,
+		"status":             data.Status,
+		"moderation_reasons": data.ModerationReasons,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	updated := &blogItem{}
+	if err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": oid}, update, opts).Decode(updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "cannot find blog with specified ID: %v", blog.GetId())
+		}
+		return nil, status.Errorf(codes.Internal, "cannot update blog in MongoDB: %v", err)
+	}
+	return &blogpb.UpdateBlogResponse{Blog: dataToBlogPb(updated)}, nil
+}
+
+// DeleteBlog deletes a blog, only its author or an admin may delete it
+func (s *Server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
+	logging.FromContext(ctx).Info("DeleteBlog request", "blog_id", req.GetBlogId())
+
+	oid, err := primitive.ObjectIDFromHex(req.GetBlogId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot parse ID")
+	}
+	if err := s.requireAuthorOrAdmin(ctx, oid); err != nil {
+		return nil, err
+	}
+
+	res, err := s.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot delete blog in MongoDB: %v", err)
+	}
+	if res.DeletedCount == 0 {
+		return nil, status.Errorf(codes.NotFound, "cannot find blog with specified ID: %v", req.GetBlogId())
+	}
+	return &blogpb.DeleteBlogResponse{BlogId: req.GetBlogId()}, nil
+}
+
+// requireAuthorOrAdmin checks the caller wrote the blog or is an admin
+func (s *Server) requireAuthorOrAdmin(ctx context.Context, oid primitive.ObjectID) error {
+	// without a caller there is no need to look the author up
+	if _, ok := auth.FromContext(ctx); !ok {
+		return auth.RequireOwnerOrRole(ctx, "")
+	}
+
+	data := &blogItem{}
+	opts := options.FindOne().SetProjection(bson.M{"author_id": 1})
+	if err := s.collection.FindOne(ctx, bson.M{"_id": oid}, opts).Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return status.Errorf(codes.NotFound, "cannot find blog with specified ID: %v", oid.Hex())
+		}
+		return status.Errorf(codes.Internal, "cannot find blog in MongoDB: %v", err)
+	}
+	return auth.RequireOwnerOrRole(ctx, data.AuthorId, "admin")
+}
+
+// ListBlogs streams every published blog
+func (s *Server) ListBlogs(req *blogpb.ListBlogsRequest, stream blogpb.BlogService_ListBlogsServer) error {
+	logging.FromContext(stream.Context()).Info("ListBlogs request")
+
+	return s.sendBlogs(stream.Context(), blogpb.BlogStatus_PUBLISHED, func(blog *blogpb.Blog) error {
+		return stream.Send(&blogpb.ListBlogsResponse{Blog: blog})
+	})
+}
+
+// ListModerationQueue streams every blog that is waiting for review
+func (s *Server) ListModerationQueue(req *blogpb.ListModerationQueueRequest, stream blogpb.BlogService_ListModerationQueueServer) error {
+	logging.FromContext(stream.Context()).Info("ListModerationQueue request")
+
+	return s.sendBlogs(stream.Context(), blogpb.BlogStatus_PENDING_REVIEW, func(blog *blogpb.Blog) error {
+		return stream.Send(&blogpb.ListModerationQueueResponse{Blog: blog})
+	})
+}
+
+// sendBlogs calls send with every blog that has the status, as they come from MongoDB
+func (s *Server) sendBlogs(ctx context.Context, blogStatus blogpb.BlogStatus, send func(*blogpb.Blog) error) error {
+	cur, err := s.collection.Find(ctx, bson.M{"status": blogStatus})
+	if err != nil {
+		return status.Errorf(codes.Internal, "unknown internal error: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		if err := cur.Decode(data); err != nil {
+			return status.Errorf(codes.Internal, "error while decoding data from MongoDB: %v", err)
+		}
+		if err := send(dataToBlogPb(data)); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return status.Errorf(codes.Internal, "unknown internal error: %v", err)
+	}
+	return nil
+}
+
+// ApproveContent publishes a blog that is waiting for review
+func (s *Server) ApproveContent(ctx context.Context, req *blogpb.ApproveContentRequest) (*blogpb.ApproveContentResponse, error) {
+	logging.FromContext(ctx).Info("ApproveContent request", "blog_id", req.GetBlogId())
+
+	data, err := s.setReviewStatus(ctx, req.GetBlogId(), blogpb.BlogStatus_PUBLISHED, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &blogpb.ApproveContentResponse{Blog: dataToBlogPb(data)}, nil
+}
+
+// RejectContent rejects a blog that is waiting for review, the reason is kept with the blog
+func (s *Server) RejectContent(ctx context.Context, req *blogpb.RejectContentRequest) (*blogpb.RejectContentResponse, error) {
+	logging.FromContext(ctx).Info("RejectContent request", "blog_id", req.GetBlogId())
+
+	var reasons []string
+	if req.GetReason() != "" {
+		reasons = []string{req.GetReason()}
+	}
+	data, err := s.setReviewStatus(ctx, req.GetBlogId(), blogpb.BlogStatus_REJECTED, reasons)
+	if err != nil {
+		return nil, err
+	}
+	return &blogpb.RejectContentResponse{Blog: dataToBlogPb(data)}, nil
+}
+
+// setReviewStatus moves a blog out of PENDING_REVIEW and returns the updated blog
+func (s *Server) setReviewStatus(ctx context.Context, blogID string, newStatus blogpb.BlogStatus, reasons []string) (*blogItem, error) {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot parse ID")
+	}
+
+	filter := bson.M{"_id": oid, "status": blogpb.BlogStatus_PENDING_REVIEW}
+	update := bson.M{"$set": bson.M{"status": newStatus, "moderation_reasons": reasons}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	data := &blogItem{}
+	if err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(data); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.NotFound, "cannot find blog pending review with specified ID: %v", blogID)
+		}
+		return nil, status.Errorf(codes.Internal, "cannot update blog in MongoDB: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,479 @@
+package blogservice_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/blog/blogclient"
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/blog/blogservice"
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/auth/authtest"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// the tests run against a mock MongoDB deployment that answers every command with the
+// next mock response, in order
+
+const namespace = "mydb.blog"
+
+func blogDoc(id primitive.ObjectID, title string, status blogpb.BlogStatus, reasons ...string) bson.D {
+	return bson.D{
+		{Key: "_id", Value: id},
+		{Key: "author_id", Value: "angel"},
+		{Key: "title", Value: title},
+		{Key: "content", Value: "some content"},
+		{Key: "status", Value: status},
+		{Key: "moderation_reasons", Value: reasons},
+	}
+}
+
+func TestListModerationQueue(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	tests := []struct {
+		name      string
+		responses []bson.D
+		want      []string
+		wantErr   error
+	}{
+		{
+			name: "pending blogs",
+			responses: []bson.D{mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+				blogDoc(first, "first", blogpb.BlogStatus_PENDING_REVIEW, "profanity"),
+				blogDoc(second, "second", blogpb.BlogStatus_PENDING_REVIEW, "spam"),
+			)},
+			want: []string{first.Hex(), second.Hex()},
+		},
+		{
+			name:      "empty queue",
+			responses: []bson.D{mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch)},
+			want:      []string{},
+		},
+		{
+			name: "several batches",
+			responses: []bson.D{
+				mtest.CreateCursorResponse(1, namespace, mtest.FirstBatch, blogDoc(first, "first", blogpb.BlogStatus_PENDING_REVIEW)),
+				mtest.CreateCursorResponse(0, namespace, mtest.NextBatch, blogDoc(second, "second", blogpb.BlogStatus_PENDING_REVIEW)),
+			},
+			want: []string{first.Hex(), second.Hex()},
+		},
+		{
+			name:      "MongoDB fails",
+			responses: []bson.D{mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted at shutdown"})},
+			want:      []string{},
+			wantErr:   sdk.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			c := servertest.Blog(mt.T, mt.Coll, nil)
+
+			got := []string{}
+			err := c.ListModerationQueue(context.Background(), func(b *blogpb.Blog) error {
+				if b.GetStatus() != blogpb.BlogStatus_PENDING_REVIEW {
+					mt.Errorf("blog %v has status %v, want PENDING_REVIEW", b.GetId(), b.GetStatus())
+				}
+				got = append(got, b.GetId())
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				mt.Fatalf("ListModerationQueue() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				mt.Errorf("ListModerationQueue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListBlogs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("published blogs", func(mt *mtest.T) {
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+			blogDoc(first, "first", blogpb.BlogStatus_PUBLISHED),
+			blogDoc(second, "second", blogpb.BlogStatus_PUBLISHED),
+		))
+		c := servertest.Blog(mt.T, mt.Coll, nil)
+
+		got := []string{}
+		err := c.ListBlogs(context.Background(), func(b *blogpb.Blog) error {
+			got = append(got, b.GetId())
+			return nil
+		})
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if want := []string{first.Hex(), second.Hex()}; !slices.Equal(got, want) {
+			mt.Errorf("ListBlogs() = %v, want %v", got, want)
+		}
+
+		// only published blogs are asked for
+		e := mt.GetStartedEvent()
+		if e == nil || e.CommandName != "find" {
+			mt.Fatalf("first command = %v, want find", e)
+		}
+		if s := blogpb.BlogStatus(e.Command.Lookup("filter", "status").Int32()); s != blogpb.BlogStatus_PUBLISHED {
+			mt.Errorf("find filter status = %v, want PUBLISHED", s)
+		}
+	})
+}
+
+func TestReview(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name string
+		// reject rejects the blog with reason instead of approving it
+		reject    bool
+		reason    string
+		blogID    string
+		responses []bson.D
+		want      *blogpb.Blog
+		wantErr   error
+	}{
+		{
+			name:      "approve",
+			blogID:    id.Hex(),
+			responses: []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blogDoc(id, "title", blogpb.BlogStatus_PUBLISHED)})},
+			want:      &blogpb.Blog{Id: id.Hex(), Title: "title", Status: blogpb.BlogStatus_PUBLISHED},
+		},
+		{
+			name:      "reject with a reason",
+			reject:    true,
+			reason:    "off topic",
+			blogID:    id.Hex(),
+			responses: []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blogDoc(id, "title", blogpb.BlogStatus_REJECTED, "off topic")})},
+			want:      &blogpb.Blog{Id: id.Hex(), Title: "title", Status: blogpb.BlogStatus_REJECTED, ModerationReasons: []string{"off topic"}},
+		},
+		{
+			name:    "invalid ID",
+			blogID:  "not-an-id",
+			wantErr: sdk.ErrInvalidArgument,
+		},
+		{
+			name:      "not pending review",
+			blogID:    id.Hex(),
+			responses: []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil})},
+			wantErr:   sdk.ErrNotFound,
+		},
+		{
+			name:      "MongoDB fails",
+			reject:    true,
+			blogID:    id.Hex(),
+			responses: []bson.D{mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted at shutdown"})},
+			wantErr:   sdk.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			c := servertest.Blog(mt.T, mt.Coll, nil)
+
+			var got *blogpb.Blog
+			var err error
+			if tt.reject {
+				got, err = c.RejectContent(context.Background(), tt.blogID, tt.reason)
+			} else {
+				got, err = c.ApproveContent(context.Background(), tt.blogID)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				mt.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				return
+			}
+			if got.GetId() != tt.want.GetId() || got.GetTitle() != tt.want.GetTitle() || got.GetStatus() != tt.want.GetStatus() ||
+				!slices.Equal(got.GetModerationReasons(), tt.want.GetModerationReasons()) {
+				mt.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateBlog(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	tests := []struct {
+		name        string
+		blog        *blogpb.Blog
+		responses   []bson.D
+		wantStatus  blogpb.BlogStatus
+		wantReasons []string
+		wantErr     error
+	}{
+		{
+			name:       "published",
+			blog:       &blogpb.Blog{AuthorId: "angel", Title: "Hello", Content: "my first post"},
+			responses:  []bson.D{mtest.CreateSuccessResponse()},
+			wantStatus: blogpb.BlogStatus_PUBLISHED,
+		},
+		{
+			name:        "flagged",
+			blog:        &blogpb.Blog{AuthorId: "angel", Title: "Hello", Content: "you idiot"},
+			responses:   []bson.D{mtest.CreateSuccessResponse()},
+			wantStatus:  blogpb.BlogStatus_PENDING_REVIEW,
+			wantReasons: []string{"contains profanity: idiot"},
+		},
+		{
+			name:    "no blog",
+			wantErr: sdk.ErrInvalidArgument,
+		},
+		{
+			name:      "MongoDB fails",
+			blog:      &blogpb.Blog{Title: "Hello"},
+			responses: []bson.D{mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted at shutdown"})},
+			wantErr:   sdk.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			c := servertest.Blog(mt.T, mt.Coll, blogservice.DefaultModerator())
+
+			got, err := c.CreateBlog(context.Background(), tt.blog)
+			if !errors.Is(err, tt.wantErr) {
+				mt.Fatalf("CreateBlog() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if _, err := primitive.ObjectIDFromHex(got.GetId()); err != nil {
+				mt.Errorf("CreateBlog() ID = %q, want an ObjectID", got.GetId())
+			}
+			if got.GetStatus() != tt.wantStatus || !slices.Equal(got.GetModerationReasons(), tt.wantReasons) {
+				mt.Errorf("CreateBlog() = %v %v, want %v %v", got.GetStatus(), got.GetModerationReasons(), tt.wantStatus, tt.wantReasons)
+			}
+
+			// the stored document has the moderation outcome
+			doc := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+			if s := blogpb.BlogStatus(doc.Lookup("status").Int32()); s != tt.wantStatus {
+				mt.Errorf("stored status = %v, want %v", s, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// authBlog starts a blog server with auth on and returns a function making a client
+// that calls it as subject with roles
+func authBlog(mt *mtest.T) func(subject string, roles ...string) *blogclient.Client {
+	issuer := authtest.NewIssuer()
+	secretFile, err := issuer.WriteFile(mt.TempDir())
+	if err != nil {
+		mt.Fatal(err)
+	}
+	cfg := bootstrap.Config{Auth: auth.Config{HMACSecretFile: secretFile}}
+	s := servertest.StartBlog(mt.T, cfg, mt.Coll, blogservice.DefaultModerator())
+	return func(subject string, roles ...string) *blogclient.Client {
+		// bufconn is plain text
+		creds := auth.ClientCredentials{Token: issuer.Token(subject, roles...), Insecure: true}
+		return blogclient.New(s.Dial(mt.T, []string{blogclient.ServiceName}, sdk.WithPerRPCCredentials(creds)))
+	}
+}
+
+// authorDoc is the answer to the lookup of the author of a blog
+func authorDoc(id primitive.ObjectID, author string) bson.D {
+	return mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch, bson.D{{Key: "_id", Value: id}, {Key: "author_id", Value: author}})
+}
+
+func TestCreateBlogAuthor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("author is the caller", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		c := authBlog(mt)("angel")
+
+		got, err := c.CreateBlog(context.Background(), &blogpb.Blog{AuthorId: "someone-else", Title: "Hello", Content: "my first post"})
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if got.GetAuthorId() != "angel" {
+			mt.Errorf("CreateBlog() author = %q, want the caller angel", got.GetAuthorId())
+		}
+	})
+}
+
+func TestReadBlog(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	found := func(status blogpb.BlogStatus) []bson.D {
+		return []bson.D{mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch, blogDoc(id, "Hello", status))}
+	}
+	tests := []struct {
+		name string
+		// caller and roles call the server, no caller calls it with auth off
+		caller    string
+		roles     []string
+		blogID    string
+		responses []bson.D
+		wantErr   error
+	}{
+		{"published", "", nil, id.Hex(), found(blogpb.BlogStatus_PUBLISHED), nil},
+		{"published, with auth", "mallory", nil, id.Hex(), found(blogpb.BlogStatus_PUBLISHED), nil},
+		{"pending, author", "angel", nil, id.Hex(), found(blogpb.BlogStatus_PENDING_REVIEW), nil},
+		{"pending, moderator", "mod", []string{"moderator"}, id.Hex(), found(blogpb.BlogStatus_PENDING_REVIEW), nil},
+		{"pending, someone else", "mallory", nil, id.Hex(), found(blogpb.BlogStatus_PENDING_REVIEW), sdk.ErrNotFound},
+		{"rejected, auth off", "", nil, id.Hex(), found(blogpb.BlogStatus_REJECTED), nil},
+		{"invalid ID", "", nil, "not-an-id", nil, sdk.ErrInvalidArgument},
+		{"not found", "", nil, id.Hex(), []bson.D{mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch)}, sdk.ErrNotFound},
+		{"MongoDB fails", "", nil, id.Hex(), []bson.D{mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted at shutdown"})}, sdk.ErrInternal},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			c := servertest.Blog(mt.T, mt.Coll, nil)
+			if tt.caller != "" {
+				c = authBlog(mt)(tt.caller, tt.roles...)
+			}
+
+			got, err := c.ReadBlog(context.Background(), tt.blogID)
+			if !errors.Is(err, tt.wantErr) {
+				mt.Fatalf("ReadBlog() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (got.GetId() != id.Hex() || got.GetContent() != "some content") {
+				mt.Errorf("ReadBlog() = %v, want blog %v with its content", got, id.Hex())
+			}
+		})
+	}
+}
+
+func TestUpdateBlog(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name string
+		// caller and roles call the server, no caller calls it with auth off
+		caller     string
+		roles      []string
+		blog       *blogpb.Blog
+		responses  []bson.D
+		wantStatus blogpb.BlogStatus
+		wantErr    error
+	}{
+		{
+			name:       "published",
+			caller:     "angel",
+			blog:       &blogpb.Blog{Id: id.Hex(), Title: "Hello", Content: "an edited post"},
+			responses:  []bson.D{authorDoc(id, "angel"), mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blogDoc(id, "Hello", blogpb.BlogStatus_PUBLISHED)})},
+			wantStatus: blogpb.BlogStatus_PUBLISHED,
+		},
+		{
+			name:       "flagged",
+			caller:     "angel",
+			blog:       &blogpb.Blog{Id: id.Hex(), Title: "Hello", Content: "what crap"},
+			responses:  []bson.D{authorDoc(id, "angel"), mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blogDoc(id, "Hello", blogpb.BlogStatus_PENDING_REVIEW)})},
+			wantStatus: blogpb.BlogStatus_PENDING_REVIEW,
+		},
+		{
+			name:       "admin",
+			caller:     "root",
+			roles:      []string{"admin"},
+			blog:       &blogpb.Blog{Id: id.Hex(), Title: "Hello", Content: "an edited post"},
+			responses:  []bson.D{authorDoc(id, "angel"), mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blogDoc(id, "Hello", blogpb.BlogStatus_PUBLISHED)})},
+			wantStatus: blogpb.BlogStatus_PUBLISHED,
+		},
+		{
+			name:      "not the author",
+			caller:    "mallory",
+			roles:     []string{"moderator"},
+			blog:      &blogpb.Blog{Id: id.Hex(), Title: "Hello"},
+			responses: []bson.D{authorDoc(id, "angel")},
+			wantErr:   sdk.ErrPermissionDenied,
+		},
+		{
+			name:       "auth off",
+			blog:       &blogpb.Blog{Id: id.Hex(), Title: "Hello", Content: "an edited post"},
+			responses:  []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blogDoc(id, "Hello", blogpb.BlogStatus_PUBLISHED)})},
+			wantStatus: blogpb.BlogStatus_PUBLISHED,
+		},
+		{
+			name:    "invalid ID",
+			caller:  "angel",
+			blog:    &blogpb.Blog{Id: "not-an-id"},
+			wantErr: sdk.ErrInvalidArgument,
+		},
+		{
+			name:      "not found",
+			caller:    "angel",
+			blog:      &blogpb.Blog{Id: id.Hex()},
+			responses: []bson.D{mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch)},
+			wantErr:   sdk.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			c := servertest.Blog(mt.T, mt.Coll, blogservice.DefaultModerator())
+			if tt.caller != "" {
+				c = authBlog(mt)(tt.caller, tt.roles...)
+			}
+
+			got, err := c.UpdateBlog(context.Background(), tt.blog)
+			if !errors.Is(err, tt.wantErr) {
+				mt.Fatalf("UpdateBlog() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.GetId() != id.Hex() {
+				mt.Errorf("UpdateBlog() ID = %v, want %v", got.GetId(), id.Hex())
+			}
+
+			// the update, after the lookup of the author, sets the moderation outcome of the new content
+			e := mt.GetStartedEvent()
+			for e != nil && e.CommandName != "findAndModify" {
+				e = mt.GetStartedEvent()
+			}
+			if e == nil {
+				mt.Fatal("no findAndModify command was sent")
+			}
+			set := e.Command.Lookup("update", "$set").Document()
+			if s := blogpb.BlogStatus(set.Lookup("status").Int32()); s != tt.wantStatus {
+				mt.Errorf("updated status = %v, want %v", s, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDeleteBlog(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	deleted := func(n int32) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n})
+	}
+	tests := []struct {
+		name      string
+		caller    string
+		roles     []string
+		blogID    string
+		responses []bson.D
+		wantErr   error
+	}{
+		{"author", "angel", nil, id.Hex(), []bson.D{authorDoc(id, "angel"), deleted(1)}, nil},
+		{"admin", "root", []string{"admin"}, id.Hex(), []bson.D{authorDoc(id, "angel"), deleted(1)}, nil},
+		{"not the author", "mallory", []string{"moderator"}, id.Hex(), []bson.D{authorDoc(id, "angel")}, sdk.ErrPermissionDenied},
+		{"auth off", "", nil, id.Hex(), []bson.D{deleted(1)}, nil},
+		{"invalid ID", "angel", nil, "not-an-id", nil, sdk.ErrInvalidArgument},
+		{"not found", "angel", nil, id.Hex(), []bson.D{mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch)}, sdk.ErrNotFound},
+		{"deleted meanwhile", "angel", nil, id.Hex(), []bson.D{authorDoc(id, "angel"), deleted(0)}, sdk.ErrNotFound},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			c := servertest.Blog(mt.T, mt.Coll, nil)
+			if tt.caller != "" {
+				c = authBlog(mt)(tt.caller, tt.roles...)
+			}
+			if err := c.DeleteBlog(context.Background(), tt.blogID); !errors.Is(err, tt.wantErr) {
+				mt.Errorf("DeleteBlog() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package moderation checks blog posts as they are written. A Moderator flags content
+// with the reasons why, and the blog server puts flagged posts in the moderation queue
+package moderation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Content is the part of a blog post that gets checked by a Moderator
+type Content struct {
+	Title   string
+	Content string
+}
+
+// Result is what a Moderator decides about a piece of content.
+// When Flagged is true, Reasons says why so a moderator can review it
+type Result struct {
+	Flagged bool
+	Reasons []string
+}
+
+// Moderator is the hook that runs on blog writes, any type that
+// implements Check can be plugged into the blog server
+type Moderator interface {
+	Check(c Content) Result
+}
+
+// Chain runs every moderator in order and merges their results
+type Chain []Moderator
+
+// Check implements Moderator
+func (ch Chain) Check(c Content) Result {
+	res := Result{}
+	for _, m := range ch {
+		r := m.Check(c)
+		if r.Flagged {
+			res.Flagged = true
+			res.Reasons = append(res.Reasons, r.Reasons...)
+		}
+	}
+	return res
+}
+
+// ProfanityFilter flags content containing any of the words in its list,
+// matching is case insensitive and done on whole words
+type ProfanityFilter struct {
+	words map[string]bool
+}
+
+// NewProfanityFilter returns a ProfanityFilter for the given word list
+func NewProfanityFilter(words []string) *ProfanityFilter {
+	f := &ProfanityFilter{words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		f.words[strings.ToLower(strings.TrimSpace(w))] = true
+	}
+	return f
+}
+
+// Check implements Moderator
+func (f *ProfanityFilter) Check(c Content) Result {
+	for _, w := range splitWords(c.Title + " " + c.Content) {
+		if f.words[w] {
+			return Result{Flagged: true, Reasons: []string{"contains profanity: " + w}}
+		}
+	}
+	return Result{}
+}
+
+var linkRegexp = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+
+// LinkLimit flags content that has more than Max links in its title and body together
+type LinkLimit struct {
+	Max int
+}
+
+// Check implements Moderator
+func (l LinkLimit) Check(c Content) Result {
+	if n := countLinks(c.Title + " " + c.Content); n > l.Max {
+		return Result{Flagged: true, Reasons: []string{"too many links"}}
+	}
+	return Result{}
+}
+
+// SpamScore gives content a score between 0 and 1 based on a few simple signals
+// (shouting, links, repeated words and exclamation marks) and flags it
+// when the score reaches Threshold
+type SpamScore struct {
+	Threshold float64
+}
+
+// Check implements Moderator
+func (s SpamScore) Check(c Content) Result {
+	if Score(c) >= s.Threshold {
+		return Result{Flagged: true, Reasons: []string{"spam score too high"}}
+	}
+	return Result{}
+}
+
+// Score returns the spam score of the content, 0 means it looks fine
+// and 1 means it looks like spam
+func Score(c Content) float64 {
+	text := c.Title + " " + c.Content
+	words := splitWords(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	letters, upper := 0, 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	counts := map[string]int{}
+	repeated := 0
+	for _, w := range words {
+		counts[w]++
+		if counts[w] > 1 {
+			repeated++
+		}
+	}
+
+	score := 0.0
+	if letters > 0 {
+		score += 0.3 * float64(upper) / float64(letters)
+	}
+	score += 0.3 * float64(repeated) / float64(len(words))
+	score += 0.2 * clamp(float64(countLinks(text))/float64(len(words))*5)
+	score += 0.2 * clamp(float64(strings.Count(text, "!"))/float64(len(words))*2)
+	return score
+}
+
+func countLinks(text string) int {
+	return len(linkRegexp.FindAllStringIndex(text, -1))
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func clamp(v float64) float64 {
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+package moderation_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/blog/moderation"
+)
+
+func TestProfanityFilter(t *testing.T) {
+	f := moderation.NewProfanityFilter([]string{"damn", " Crap "})
+	tests := []struct {
+		name        string
+		content     moderation.Content
+		wantReasons []string
+	}{
+		{"clean", moderation.Content{Title: "Hello", Content: "my first post"}, nil},
+		{"in the content", moderation.Content{Title: "Hello", Content: "what a damn day"}, []string{"contains profanity: damn"}},
+		{"in the title", moderation.Content{Title: "Crap!", Content: "my first post"}, []string{"contains profanity: crap"}},
+		{"case insensitive", moderation.Content{Content: "DAMN"}, []string{"contains profanity: damn"}},
+		{"whole words only", moderation.Content{Content: "damnation and crapshoot"}, nil},
+		{"empty", moderation.Content{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResult(t, f.Check(tt.content), tt.wantReasons)
+		})
+	}
+}
+
+func TestLinkLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		max         int
+		content     moderation.Content
+		wantReasons []string
+	}{
+		{"no links", 1, moderation.Content{Content: "no links here"}, nil},
+		{"at the limit", 2, moderation.Content{Content: "see http://a.example and https://b.example"}, nil},
+		{"over the limit", 2, moderation.Content{Content: "see http://a.example, https://b.example and www.c.example"}, []string{"too many links"}},
+		{"case insensitive", 0, moderation.Content{Content: "HTTPS://A.EXAMPLE"}, []string{"too many links"}},
+		{"not a link", 0, moderation.Content{Content: "the www is not a link, nor is http alone"}, nil},
+		{"in the title", 0, moderation.Content{Title: "www.a.example", Content: "no links here"}, []string{"too many links"}},
+		{"title and content together", 1, moderation.Content{Title: "http://a.example", Content: "see https://b.example"}, []string{"too many links"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := moderation.LinkLimit{Max: tt.max}
+			checkResult(t, l.Check(tt.content), tt.wantReasons)
+		})
+	}
+}
+
+func TestSpamScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   float64
+		content     moderation.Content
+		wantReasons []string
+	}{
+		{"plain text", 0.5, moderation.Content{Title: "Hello", Content: "my first post about gardening"}, nil},
+		{"empty", 0.5, moderation.Content{}, nil},
+		{"shouting and repeats", 0.5, moderation.Content{Title: "BUY NOW", Content: "BUY NOW BUY NOW!!! http://cheap.example"}, []string{"spam score too high"}},
+		{"threshold reached", 0, moderation.Content{Content: "anything"}, []string{"spam score too high"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := moderation.SpamScore{Threshold: tt.threshold}
+			checkResult(t, s.Check(tt.content), tt.wantReasons)
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		content moderation.Content
+		min     float64
+		max     float64
+	}{
+		{"no words", moderation.Content{Content: "!!! ..."}, 0, 0},
+		{"lower case, no repeats", moderation.Content{Content: "a quiet little post"}, 0, 0},
+		{"all caps", moderation.Content{Content: "LOUD POST"}, 0.3, 0.3},
+		{"everything", moderation.Content{Content: "WIN WIN WIN!!! HTTP://X HTTP://X"}, 0.8, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moderation.Score(tt.content); got < tt.min-1e-9 || got > tt.max+1e-9 {
+				t.Errorf("Score(%q) = %v, want between %v and %v", tt.content.Content, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := moderation.Chain{
+		moderation.NewProfanityFilter([]string{"damn"}),
+		moderation.LinkLimit{Max: 1},
+		moderation.SpamScore{Threshold: 0.5},
+	}
+	tests := []struct {
+		name        string
+		chain       moderation.Chain
+		content     moderation.Content
+		wantReasons []string
+	}{
+		{"nothing flagged", chain, moderation.Content{Title: "Hello", Content: "my first post"}, nil},
+		{"one flags", chain, moderation.Content{Title: "Hello", Content: "damn it"}, []string{"contains profanity: damn"}},
+		{
+			name:        "reasons in order",
+			chain:       chain,
+			content:     moderation.Content{Title: "Hello", Content: "damn, see http://a.example and http://b.example"},
+			wantReasons: []string{"contains profanity: damn", "too many links"},
+		},
+		{"empty chain", moderation.Chain{}, moderation.Content{Content: "damn"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResult(t, tt.chain.Check(tt.content), tt.wantReasons)
+		})
+	}
+}
+
+// checkResult checks res is flagged for wantReasons, or not flagged when there are none
+func checkResult(t *testing.T, res moderation.Result, wantReasons []string) {
+	t.Helper()
+	if res.Flagged != (len(wantReasons) > 0) || !slices.Equal(res.Reasons, wantReasons) {
+		t.Errorf("Check() = %+v, want reasons %v", res, wantReasons)
+	}
+}
@@ -0,0 +1,152 @@
+// Package calcclient is the Go client of the CalculatorService. Errors are *sdk.Error values
+package calcclient
+
+import (
+	"context"
+	"io"
+
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the full name of the CalculatorService, for sdk.Dial
+const ServiceName = "calculator.CalculatorService"
+
+// IdempotentMethods are the unary methods safe to call twice, for sdk.HedgingPolicy.
+// Each client lists its own service, see blogclient.IdempotentMethods for the blog reads
+var IdempotentMethods = []string{
+	"/calculator.CalculatorService/Sum",
+	"/calculator.CalculatorService/SquareRoot",
+}
+
+// Client calls the CalculatorService, it is safe for concurrent use
+type Client struct {
+	rpc calculatorpb.CalculatorServiceClient
+	cc  *grpc.ClientConn
+}
+
+// Dial connects to the calculator server at target
+func Dial(target string, opts ...sdk.Option) (*Client, error) {
+	cc, err := sdk.Dial(target, []string{ServiceName}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := New(cc)
+	c.cc = cc
+	return c, nil
+}
+
+// New returns a client using cc, which can be shared with other clients, see sdk.Dial
+func New(cc *grpc.ClientConn) *Client {
+	return &Client{rpc: calculatorpb.NewCalculatorServiceClient(cc)}
+}
+
+// Close closes the connection opened by Dial, it does nothing for clients made with New
+func (c *Client) Close() error {
+	if c.cc == nil {
+		return nil
+	}
+	return c.cc.Close()
+}
+
+// Sum returns a + b
+func (c *Client) Sum(ctx context.Context, a, b int32) (int32, error) {
+	res, err := c.rpc.Sum(ctx, &calculatorpb.SumRequest{FirstNumber: a, SecondNumber: b})
+	if err != nil {
+		return 0, sdk.FromError(err)
+	}
+	return res.GetSumResult(), nil
+}
+
+// PrimeFactors calls fn with every prime factor of n, smallest first.
+// An error from fn cancels the stream and is returned
+func (c *Client) PrimeFactors(ctx context.Context, n int64, fn func(factor int64) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.PrimeNumberDecomposition(ctx, &calculatorpb.PrimeNumberDecompositionRequest{Number: n})
+	if err != nil {
+		return sdk.FromError(err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return sdk.FromError(err)
+		}
+		if err := fn(res.GetPrimeFactor()); err != nil {
+			return err
+		}
+	}
+}
+
+// Average returns the average of numbers, ErrInvalidArgument without any
+func (c *Client) Average(ctx context.Context, numbers []int32) (float64, error) {
+	stream, err := c.rpc.ComputeAverage(ctx)
+	if err != nil {
+		return 0, sdk.FromError(err)
+	}
+	for _, n := range numbers {
+		if err := stream.Send(&calculatorpb.ComputeAverageRequest{Number: n}); err != nil {
+			// the reason comes back from CloseAndRecv
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, sdk.FromError(err)
+	}
+	return res.GetAverage(), nil
+}
+
+// Maximum opens a stream answering with the new maximum whenever a number sent raises it
+func (c *Client) Maximum(ctx context.Context) (*MaximumStream, error) {
+	stream, err := c.rpc.FindMaximum(ctx)
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return &MaximumStream{stream: stream}, nil
+}
+
+// SquareRoot returns the square root of n, an sdk.ErrInvalidArgument error when n is negative
+func (c *Client) SquareRoot(ctx context.Context, n int32) (float64, error) {
+	res, err := c.rpc.SquareRoot(ctx, &calculatorpb.SquareRootRequest{Number: n})
+	if err != nil {
+		return 0, sdk.FromError(err)
+	}
+	return res.GetNumberRoot(), nil
+}
+
+// MaximumStream is an open FindMaximum call. Send and Recv may be called from
+// different goroutines, but neither from more than one at a time
+type MaximumStream struct {
+	stream calculatorpb.CalculatorService_FindMaximumClient
+}
+
+// Send sends a number, the server only answers when it is a new maximum
+func (s *MaximumStream) Send(n int32) error {
+	if err := s.stream.Send(&calculatorpb.FindMaximumRequest{Number: n}); err != nil {
+		if err == io.EOF {
+			// the stream is over, Recv returns why
+			return err
+		}
+		return sdk.FromError(err)
+	}
+	return nil
+}
+
+// CloseSend tells the server no more numbers are coming
+func (s *MaximumStream) CloseSend() error {
+	return s.stream.CloseSend()
+}
+
+// Recv returns the next maximum, io.EOF once the server is done
+func (s *MaximumStream) Recv() (int32, error) {
+	res, err := s.stream.Recv()
+	if err != nil {
+		return 0, sdk.FromError(err)
+	}
+	return res.GetMaximum(), nil
+}
@@ -1,804 +1,575 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
 // source: calculator/calculatorpb/calculator.proto
 
 package calculatorpb
 
 import (
-	context "context"
-	fmt "fmt"
-	proto "github.com/golang/protobuf/proto"
-	grpc "google.golang.org/grpc"
-	codes "google.golang.org/grpc/codes"
-	status "google.golang.org/grpc/status"
-	math "math"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
 )
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ = proto.Marshal
-var _ = fmt.Errorf
-var _ = math.Inf
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
 
 type SumRequest struct {
-	FirstNumber          int32    `protobuf:"varint,1,opt,name=first_number,json=firstNumber,proto3" json:"first_number,omitempty"`
-	SecondNumber         int32    `protobuf:"varint,2,opt,name=second_number,json=secondNumber,proto3" json:"second_number,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstNumber   int32                  `protobuf:"varint,1,opt,name=first_number,json=firstNumber,proto3" json:"first_number,omitempty"`
+	SecondNumber  int32                  `protobuf:"varint,2,opt,name=second_number,json=secondNumber,proto3" json:"second_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *SumRequest) Reset()         { *m = SumRequest{} }
-func (m *SumRequest) String() string { return proto.CompactTextString(m) }
-func (*SumRequest) ProtoMessage()    {}
-func (*SumRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{0}
+func (x *SumRequest) Reset() {
+	*x = SumRequest{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *SumRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SumRequest.Unmarshal(m, b)
-}
-func (m *SumRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SumRequest.Marshal(b, m, deterministic)
-}
-func (m *SumRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SumRequest.Merge(m, src)
+func (x *SumRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-func (m *SumRequest) XXX_Size() int {
-	return xxx_messageInfo_SumRequest.Size(m)
-}
-func (m *SumRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_SumRequest.DiscardUnknown(m)
+
+func (*SumRequest) ProtoMessage() {}
+
+func (x *SumRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var xxx_messageInfo_SumRequest proto.InternalMessageInfo
+// Deprecated: Use SumRequest.ProtoReflect.Descriptor instead.
+func (*SumRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{0}
+}
 
-func (m *SumRequest) GetFirstNumber() int32 {
-	if m != nil {
-		return m.FirstNumber
+func (x *SumRequest) GetFirstNumber() int32 {
+	if x != nil {
+		return x.FirstNumber
 	}
 	return 0
 }
 
-func (m *SumRequest) GetSecondNumber() int32 {
-	if m != nil {
-		return m.SecondNumber
+func (x *SumRequest) GetSecondNumber() int32 {
+	if x != nil {
+		return x.SecondNumber
 	}
 	return 0
 }
 
 type SumResponse struct {
-	SumResult            int32    `protobuf:"varint,1,opt,name=sum_result,json=sumResult,proto3" json:"sum_result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SumResult     int32                  `protobuf:"varint,1,opt,name=sum_result,json=sumResult,proto3" json:"sum_result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *SumResponse) Reset()         { *m = SumResponse{} }
-func (m *SumResponse) String() string { return proto.CompactTextString(m) }
-func (*SumResponse) ProtoMessage()    {}
-func (*SumResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{1}
+func (x *SumResponse) Reset() {
+	*x = SumResponse{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *SumResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SumResponse.Unmarshal(m, b)
-}
-func (m *SumResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SumResponse.Marshal(b, m, deterministic)
-}
-func (m *SumResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SumResponse.Merge(m, src)
-}
-func (m *SumResponse) XXX_Size() int {
-	return xxx_messageInfo_SumResponse.Size(m)
-}
-func (m *SumResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_SumResponse.DiscardUnknown(m)
+func (x *SumResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_SumResponse proto.InternalMessageInfo
+func (*SumResponse) ProtoMessage() {}
 
-func (m *SumResponse) GetSumResult() int32 {
-	if m != nil {
-		return m.SumResult
+func (x *SumResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
-}
-
-type PrimeNumberDecompositionRequest struct {
-	Number               int64    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	return mi.MessageOf(x)
 }
 
-func (m *PrimeNumberDecompositionRequest) Reset()         { *m = PrimeNumberDecompositionRequest{} }
-func (m *PrimeNumberDecompositionRequest) String() string { return proto.CompactTextString(m) }
-func (*PrimeNumberDecompositionRequest) ProtoMessage()    {}
-func (*PrimeNumberDecompositionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{2}
-}
-
-func (m *PrimeNumberDecompositionRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_PrimeNumberDecompositionRequest.Unmarshal(m, b)
-}
-func (m *PrimeNumberDecompositionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_PrimeNumberDecompositionRequest.Marshal(b, m, deterministic)
-}
-func (m *PrimeNumberDecompositionRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_PrimeNumberDecompositionRequest.Merge(m, src)
-}
-func (m *PrimeNumberDecompositionRequest) XXX_Size() int {
-	return xxx_messageInfo_PrimeNumberDecompositionRequest.Size(m)
-}
-func (m *PrimeNumberDecompositionRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_PrimeNumberDecompositionRequest.DiscardUnknown(m)
+// Deprecated: Use SumResponse.ProtoReflect.Descriptor instead.
+func (*SumResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{1}
 }
 
-var xxx_messageInfo_PrimeNumberDecompositionRequest proto.InternalMessageInfo
-
-func (m *PrimeNumberDecompositionRequest) GetNumber() int64 {
-	if m != nil {
-		return m.Number
+func (x *SumResponse) GetSumResult() int32 {
+	if x != nil {
+		return x.SumResult
 	}
 	return 0
 }
 
-type PrimeNumberDecompositionResponse struct {
-	PrimeFactor          int64    `protobuf:"varint,1,opt,name=prime_factor,json=primeFactor,proto3" json:"prime_factor,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type PrimeNumberDecompositionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *PrimeNumberDecompositionResponse) Reset()         { *m = PrimeNumberDecompositionResponse{} }
-func (m *PrimeNumberDecompositionResponse) String() string { return proto.CompactTextString(m) }
-func (*PrimeNumberDecompositionResponse) ProtoMessage()    {}
-func (*PrimeNumberDecompositionResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{3}
+func (x *PrimeNumberDecompositionRequest) Reset() {
+	*x = PrimeNumberDecompositionRequest{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *PrimeNumberDecompositionResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_PrimeNumberDecompositionResponse.Unmarshal(m, b)
-}
-func (m *PrimeNumberDecompositionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_PrimeNumberDecompositionResponse.Marshal(b, m, deterministic)
-}
-func (m *PrimeNumberDecompositionResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_PrimeNumberDecompositionResponse.Merge(m, src)
-}
-func (m *PrimeNumberDecompositionResponse) XXX_Size() int {
-	return xxx_messageInfo_PrimeNumberDecompositionResponse.Size(m)
-}
-func (m *PrimeNumberDecompositionResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_PrimeNumberDecompositionResponse.DiscardUnknown(m)
+func (x *PrimeNumberDecompositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_PrimeNumberDecompositionResponse proto.InternalMessageInfo
+func (*PrimeNumberDecompositionRequest) ProtoMessage() {}
 
-func (m *PrimeNumberDecompositionResponse) GetPrimeFactor() int64 {
-	if m != nil {
-		return m.PrimeFactor
+func (x *PrimeNumberDecompositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-type ComputeAverageRequest struct {
-	Number               int32    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
-}
-
-func (m *ComputeAverageRequest) Reset()         { *m = ComputeAverageRequest{} }
-func (m *ComputeAverageRequest) String() string { return proto.CompactTextString(m) }
-func (*ComputeAverageRequest) ProtoMessage()    {}
-func (*ComputeAverageRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{4}
-}
-
-func (m *ComputeAverageRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ComputeAverageRequest.Unmarshal(m, b)
-}
-func (m *ComputeAverageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ComputeAverageRequest.Marshal(b, m, deterministic)
-}
-func (m *ComputeAverageRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ComputeAverageRequest.Merge(m, src)
-}
-func (m *ComputeAverageRequest) XXX_Size() int {
-	return xxx_messageInfo_ComputeAverageRequest.Size(m)
-}
-func (m *ComputeAverageRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ComputeAverageRequest.DiscardUnknown(m)
+// Deprecated: Use PrimeNumberDecompositionRequest.ProtoReflect.Descriptor instead.
+func (*PrimeNumberDecompositionRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{2}
 }
 
-var xxx_messageInfo_ComputeAverageRequest proto.InternalMessageInfo
-
-func (m *ComputeAverageRequest) GetNumber() int32 {
-	if m != nil {
-		return m.Number
+func (x *PrimeNumberDecompositionRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
 	}
 	return 0
 }
 
-type ComputeAverageResponse struct {
-	Average              float64  `protobuf:"fixed64,1,opt,name=average,proto3" json:"average,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type PrimeNumberDecompositionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PrimeFactor   int64                  `protobuf:"varint,1,opt,name=prime_factor,json=primeFactor,proto3" json:"prime_factor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *ComputeAverageResponse) Reset()         { *m = ComputeAverageResponse{} }
-func (m *ComputeAverageResponse) String() string { return proto.CompactTextString(m) }
-func (*ComputeAverageResponse) ProtoMessage()    {}
-func (*ComputeAverageResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{5}
+func (x *PrimeNumberDecompositionResponse) Reset() {
+	*x = PrimeNumberDecompositionResponse{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *ComputeAverageResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ComputeAverageResponse.Unmarshal(m, b)
-}
-func (m *ComputeAverageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ComputeAverageResponse.Marshal(b, m, deterministic)
-}
-func (m *ComputeAverageResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ComputeAverageResponse.Merge(m, src)
-}
-func (m *ComputeAverageResponse) XXX_Size() int {
-	return xxx_messageInfo_ComputeAverageResponse.Size(m)
-}
-func (m *ComputeAverageResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ComputeAverageResponse.DiscardUnknown(m)
+func (x *PrimeNumberDecompositionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_ComputeAverageResponse proto.InternalMessageInfo
+func (*PrimeNumberDecompositionResponse) ProtoMessage() {}
 
-func (m *ComputeAverageResponse) GetAverage() float64 {
-	if m != nil {
-		return m.Average
+func (x *PrimeNumberDecompositionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
-}
-
-type FindMaximumRequest struct {
-	Number               int32    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
-}
-
-func (m *FindMaximumRequest) Reset()         { *m = FindMaximumRequest{} }
-func (m *FindMaximumRequest) String() string { return proto.CompactTextString(m) }
-func (*FindMaximumRequest) ProtoMessage()    {}
-func (*FindMaximumRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{6}
+	return mi.MessageOf(x)
 }
 
-func (m *FindMaximumRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_FindMaximumRequest.Unmarshal(m, b)
-}
-func (m *FindMaximumRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_FindMaximumRequest.Marshal(b, m, deterministic)
-}
-func (m *FindMaximumRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_FindMaximumRequest.Merge(m, src)
-}
-func (m *FindMaximumRequest) XXX_Size() int {
-	return xxx_messageInfo_FindMaximumRequest.Size(m)
-}
-func (m *FindMaximumRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_FindMaximumRequest.DiscardUnknown(m)
+// Deprecated: Use PrimeNumberDecompositionResponse.ProtoReflect.Descriptor instead.
+func (*PrimeNumberDecompositionResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{3}
 }
 
-var xxx_messageInfo_FindMaximumRequest proto.InternalMessageInfo
-
-func (m *FindMaximumRequest) GetNumber() int32 {
-	if m != nil {
-		return m.Number
+func (x *PrimeNumberDecompositionResponse) GetPrimeFactor() int64 {
+	if x != nil {
+		return x.PrimeFactor
 	}
 	return 0
 }
 
-type FindMaximumResponse struct {
-	Maximum              int32    `protobuf:"varint,1,opt,name=maximum,proto3" json:"maximum,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type ComputeAverageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *FindMaximumResponse) Reset()         { *m = FindMaximumResponse{} }
-func (m *FindMaximumResponse) String() string { return proto.CompactTextString(m) }
-func (*FindMaximumResponse) ProtoMessage()    {}
-func (*FindMaximumResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{7}
+func (x *ComputeAverageRequest) Reset() {
+	*x = ComputeAverageRequest{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *FindMaximumResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_FindMaximumResponse.Unmarshal(m, b)
-}
-func (m *FindMaximumResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_FindMaximumResponse.Marshal(b, m, deterministic)
-}
-func (m *FindMaximumResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_FindMaximumResponse.Merge(m, src)
-}
-func (m *FindMaximumResponse) XXX_Size() int {
-	return xxx_messageInfo_FindMaximumResponse.Size(m)
-}
-func (m *FindMaximumResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_FindMaximumResponse.DiscardUnknown(m)
+func (x *ComputeAverageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_FindMaximumResponse proto.InternalMessageInfo
+func (*ComputeAverageRequest) ProtoMessage() {}
 
-func (m *FindMaximumResponse) GetMaximum() int32 {
-	if m != nil {
-		return m.Maximum
+func (x *ComputeAverageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-type SquareRootRequest struct {
-	Number               int32    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
-}
-
-func (m *SquareRootRequest) Reset()         { *m = SquareRootRequest{} }
-func (m *SquareRootRequest) String() string { return proto.CompactTextString(m) }
-func (*SquareRootRequest) ProtoMessage()    {}
-func (*SquareRootRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{8}
-}
-
-func (m *SquareRootRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SquareRootRequest.Unmarshal(m, b)
-}
-func (m *SquareRootRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SquareRootRequest.Marshal(b, m, deterministic)
-}
-func (m *SquareRootRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SquareRootRequest.Merge(m, src)
-}
-func (m *SquareRootRequest) XXX_Size() int {
-	return xxx_messageInfo_SquareRootRequest.Size(m)
-}
-func (m *SquareRootRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_SquareRootRequest.DiscardUnknown(m)
+// Deprecated: Use ComputeAverageRequest.ProtoReflect.Descriptor instead.
+func (*ComputeAverageRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{4}
 }
 
-var xxx_messageInfo_SquareRootRequest proto.InternalMessageInfo
-
-func (m *SquareRootRequest) GetNumber() int32 {
-	if m != nil {
-		return m.Number
+func (x *ComputeAverageRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
 	}
 	return 0
 }
 
-type SquareRootResponse struct {
-	NumberRoot           float64  `protobuf:"fixed64,1,opt,name=number_root,json=numberRoot,proto3" json:"number_root,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type ComputeAverageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Average       float64                `protobuf:"fixed64,1,opt,name=average,proto3" json:"average,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *SquareRootResponse) Reset()         { *m = SquareRootResponse{} }
-func (m *SquareRootResponse) String() string { return proto.CompactTextString(m) }
-func (*SquareRootResponse) ProtoMessage()    {}
-func (*SquareRootResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_7f42938f8c8365cf, []int{9}
+func (x *ComputeAverageResponse) Reset() {
+	*x = ComputeAverageResponse{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *SquareRootResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SquareRootResponse.Unmarshal(m, b)
-}
-func (m *SquareRootResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SquareRootResponse.Marshal(b, m, deterministic)
-}
-func (m *SquareRootResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SquareRootResponse.Merge(m, src)
-}
-func (m *SquareRootResponse) XXX_Size() int {
-	return xxx_messageInfo_SquareRootResponse.Size(m)
-}
-func (m *SquareRootResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_SquareRootResponse.DiscardUnknown(m)
+func (x *ComputeAverageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_SquareRootResponse proto.InternalMessageInfo
+func (*ComputeAverageResponse) ProtoMessage() {}
 
-func (m *SquareRootResponse) GetNumberRoot() float64 {
-	if m != nil {
-		return m.NumberRoot
+func (x *ComputeAverageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func init() {
-	proto.RegisterType((*SumRequest)(nil), "calculator.SumRequest")
-	proto.RegisterType((*SumResponse)(nil), "calculator.SumResponse")
-	proto.RegisterType((*PrimeNumberDecompositionRequest)(nil), "calculator.PrimeNumberDecompositionRequest")
-	proto.RegisterType((*PrimeNumberDecompositionResponse)(nil), "calculator.PrimeNumberDecompositionResponse")
-	proto.RegisterType((*ComputeAverageRequest)(nil), "calculator.ComputeAverageRequest")
-	proto.RegisterType((*ComputeAverageResponse)(nil), "calculator.ComputeAverageResponse")
-	proto.RegisterType((*FindMaximumRequest)(nil), "calculator.FindMaximumRequest")
-	proto.RegisterType((*FindMaximumResponse)(nil), "calculator.FindMaximumResponse")
-	proto.RegisterType((*SquareRootRequest)(nil), "calculator.SquareRootRequest")
-	proto.RegisterType((*SquareRootResponse)(nil), "calculator.SquareRootResponse")
-}
-
-func init() {
-	proto.RegisterFile("calculator/calculatorpb/calculator.proto", fileDescriptor_7f42938f8c8365cf)
-}
-
-var fileDescriptor_7f42938f8c8365cf = []byte{
-	// 433 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x5b, 0x6b, 0xd4, 0x40,
-	0x14, 0x36, 0x2e, 0xad, 0x78, 0x12, 0x0b, 0x3d, 0xe2, 0xba, 0x04, 0xda, 0xed, 0x8e, 0x2f, 0x0b,
-	0x5d, 0xba, 0xa5, 0x22, 0xe8, 0xa3, 0x56, 0xfb, 0x56, 0x91, 0xa4, 0x4f, 0xfa, 0x10, 0xb2, 0xe9,
-	0x54, 0x02, 0x99, 0x4c, 0x3a, 0x97, 0xa2, 0x7f, 0xd1, 0x5f, 0x25, 0x9d, 0xc9, 0x6c, 0x66, 0x2f,
-	0x71, 0x7d, 0xcb, 0xf9, 0xe6, 0xbb, 0x0c, 0x39, 0x1f, 0x03, 0xd3, 0x22, 0xaf, 0x0a, 0x5d, 0xe5,
-	0x8a, 0x8b, 0x79, 0xf7, 0xd9, 0x2c, 0xbc, 0xe1, 0xac, 0x11, 0x5c, 0x71, 0x84, 0x0e, 0x21, 0x37,
-	0x00, 0xa9, 0x66, 0x09, 0xbd, 0xd7, 0x54, 0x2a, 0x9c, 0x40, 0x74, 0x57, 0x0a, 0xa9, 0xb2, 0x5a,
-	0xb3, 0x05, 0x15, 0xa3, 0xe0, 0x24, 0x98, 0xee, 0x25, 0xa1, 0xc1, 0xbe, 0x1a, 0x08, 0xdf, 0xc0,
-	0x0b, 0x49, 0x0b, 0x5e, 0xdf, 0x3a, 0xce, 0x53, 0xc3, 0x89, 0x2c, 0x68, 0x49, 0x64, 0x06, 0xa1,
-	0x71, 0x95, 0x0d, 0xaf, 0x25, 0xc5, 0x23, 0x00, 0xa9, 0x59, 0x26, 0xa8, 0xd4, 0x95, 0x6a, 0x4d,
-	0x9f, 0x4b, 0x43, 0xd0, 0x95, 0x22, 0x1f, 0x60, 0xfc, 0x4d, 0x94, 0x8c, 0x5a, 0xf1, 0x67, 0x5a,
-	0x70, 0xd6, 0x70, 0x59, 0xaa, 0x92, 0xd7, 0xee, 0x62, 0x43, 0xd8, 0xf7, 0xae, 0x34, 0x48, 0xda,
-	0x89, 0x7c, 0x81, 0x93, 0x7e, 0x69, 0x9b, 0x3e, 0x81, 0xa8, 0x79, 0xe4, 0x64, 0x77, 0x79, 0xa1,
-	0xb8, 0x73, 0x08, 0x0d, 0x76, 0x65, 0x20, 0x32, 0x87, 0x57, 0x97, 0x9c, 0x35, 0x5a, 0xd1, 0x8f,
-	0x0f, 0x54, 0xe4, 0x3f, 0xe9, 0xf6, 0xdc, 0xbd, 0x65, 0xee, 0x05, 0x0c, 0xd7, 0x05, 0x6d, 0xda,
-	0x08, 0x9e, 0xe5, 0x16, 0x32, 0x92, 0x20, 0x71, 0x23, 0x99, 0x01, 0x5e, 0x95, 0xf5, 0xed, 0x75,
-	0xfe, 0xab, 0x64, 0xdd, 0x2f, 0xef, 0x4b, 0x98, 0xc3, 0xcb, 0x15, 0x76, 0x67, 0xcf, 0x2c, 0xd4,
-	0xf2, 0xdd, 0x48, 0x4e, 0xe1, 0x30, 0xbd, 0xd7, 0xb9, 0xa0, 0x09, 0xe7, 0x6a, 0x97, 0xfb, 0x3b,
-	0x40, 0x9f, 0xdc, 0x9a, 0x8f, 0x21, 0xb4, 0xe7, 0x99, 0xe0, 0x5c, 0xb5, 0xf7, 0x07, 0x0b, 0x3d,
-	0x12, 0x2f, 0xfe, 0x0c, 0xe0, 0xf0, 0x72, 0x59, 0x9e, 0x94, 0x8a, 0x87, 0xb2, 0xa0, 0xf8, 0x1e,
-	0x06, 0xa9, 0x66, 0x38, 0x3c, 0xf3, 0x9a, 0xd6, 0x95, 0x2a, 0x7e, 0xbd, 0x81, 0xdb, 0x38, 0xf2,
-	0x04, 0x7f, 0xc3, 0xa8, 0x6f, 0x7d, 0x78, 0xea, 0xcb, 0x76, 0xf4, 0x23, 0x9e, 0xfd, 0x1f, 0xd9,
-	0x05, 0x9f, 0x07, 0xf8, 0x03, 0x0e, 0x56, 0x37, 0x88, 0x13, 0xdf, 0x63, 0x6b, 0x1d, 0x62, 0xf2,
-	0x2f, 0x8a, 0x33, 0x9f, 0x06, 0x78, 0x03, 0xa1, 0xb7, 0x3c, 0x3c, 0xf6, 0x65, 0x9b, 0x1d, 0x88,
-	0xc7, 0xbd, 0xe7, 0x9d, 0xe7, 0x79, 0x80, 0xd7, 0x00, 0xdd, 0xd2, 0xf0, 0x68, 0xe5, 0xb7, 0xae,
-	0x6f, 0x3e, 0x3e, 0xee, 0x3b, 0x76, 0x96, 0x9f, 0x0e, 0xbe, 0x47, 0xfe, 0x3b, 0xb1, 0xd8, 0x37,
-	0xaf, 0xc3, 0xdb, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x9e, 0x96, 0xbb, 0x6e, 0x49, 0x04, 0x00,
-	0x00,
-}
-
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// CalculatorServiceClient is the client API for CalculatorService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type CalculatorServiceClient interface {
-	// Unary
-	Sum(ctx context.Context, in *SumRequest, opts ...grpc.CallOption) (*SumResponse, error)
-	// Server Streaming
-	PrimeNumberDecomposition(ctx context.Context, in *PrimeNumberDecompositionRequest, opts ...grpc.CallOption) (CalculatorService_PrimeNumberDecompositionClient, error)
-	// Client Streaming
-	ComputeAverage(ctx context.Context, opts ...grpc.CallOption) (CalculatorService_ComputeAverageClient, error)
-	// Bi-directional Streaming
-	FindMaximum(ctx context.Context, opts ...grpc.CallOption) (CalculatorService_FindMaximumClient, error)
-	// Error handling
-	// This RPC will throw an exception if the sent number is negative
-	// The error being sent is of type INVALID_ARGUMENT
-	SquareRoot(ctx context.Context, in *SquareRootRequest, opts ...grpc.CallOption) (*SquareRootResponse, error)
-}
-
-type calculatorServiceClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewCalculatorServiceClient(cc *grpc.ClientConn) CalculatorServiceClient {
-	return &calculatorServiceClient{cc}
-}
-
-func (c *calculatorServiceClient) Sum(ctx context.Context, in *SumRequest, opts ...grpc.CallOption) (*SumResponse, error) {
-	out := new(SumResponse)
-	err := c.cc.Invoke(ctx, "/calculator.CalculatorService/Sum", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+// Deprecated: Use ComputeAverageResponse.ProtoReflect.Descriptor instead.
+func (*ComputeAverageResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{5}
 }
 
-func (c *calculatorServiceClient) PrimeNumberDecomposition(ctx context.Context, in *PrimeNumberDecompositionRequest, opts ...grpc.CallOption) (CalculatorService_PrimeNumberDecompositionClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_CalculatorService_serviceDesc.Streams[0], "/calculator.CalculatorService/PrimeNumberDecomposition", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &calculatorServicePrimeNumberDecompositionClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+func (x *ComputeAverageResponse) GetAverage() float64 {
+	if x != nil {
+		return x.Average
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+	return 0
 }
 
-type CalculatorService_PrimeNumberDecompositionClient interface {
-	Recv() (*PrimeNumberDecompositionResponse, error)
-	grpc.ClientStream
+type FindMaximumRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type calculatorServicePrimeNumberDecompositionClient struct {
-	grpc.ClientStream
+func (x *FindMaximumRequest) Reset() {
+	*x = FindMaximumRequest{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *calculatorServicePrimeNumberDecompositionClient) Recv() (*PrimeNumberDecompositionResponse, error) {
-	m := new(PrimeNumberDecompositionResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
+func (x *FindMaximumRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (c *calculatorServiceClient) ComputeAverage(ctx context.Context, opts ...grpc.CallOption) (CalculatorService_ComputeAverageClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_CalculatorService_serviceDesc.Streams[1], "/calculator.CalculatorService/ComputeAverage", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &calculatorServiceComputeAverageClient{stream}
-	return x, nil
-}
+func (*FindMaximumRequest) ProtoMessage() {}
 
-type CalculatorService_ComputeAverageClient interface {
-	Send(*ComputeAverageRequest) error
-	CloseAndRecv() (*ComputeAverageResponse, error)
-	grpc.ClientStream
-}
-
-type calculatorServiceComputeAverageClient struct {
-	grpc.ClientStream
+func (x *FindMaximumRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *calculatorServiceComputeAverageClient) Send(m *ComputeAverageRequest) error {
-	return x.ClientStream.SendMsg(m)
+// Deprecated: Use FindMaximumRequest.ProtoReflect.Descriptor instead.
+func (*FindMaximumRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *calculatorServiceComputeAverageClient) CloseAndRecv() (*ComputeAverageResponse, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+func (x *FindMaximumRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
 	}
-	m := new(ComputeAverageResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
+	return 0
 }
 
-func (c *calculatorServiceClient) FindMaximum(ctx context.Context, opts ...grpc.CallOption) (CalculatorService_FindMaximumClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_CalculatorService_serviceDesc.Streams[2], "/calculator.CalculatorService/FindMaximum", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &calculatorServiceFindMaximumClient{stream}
-	return x, nil
+type FindMaximumResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Maximum       int32                  `protobuf:"varint,1,opt,name=maximum,proto3" json:"maximum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type CalculatorService_FindMaximumClient interface {
-	Send(*FindMaximumRequest) error
-	Recv() (*FindMaximumResponse, error)
-	grpc.ClientStream
+func (x *FindMaximumResponse) Reset() {
+	*x = FindMaximumResponse{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type calculatorServiceFindMaximumClient struct {
-	grpc.ClientStream
+func (x *FindMaximumResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *calculatorServiceFindMaximumClient) Send(m *FindMaximumRequest) error {
-	return x.ClientStream.SendMsg(m)
-}
+func (*FindMaximumResponse) ProtoMessage() {}
 
-func (x *calculatorServiceFindMaximumClient) Recv() (*FindMaximumResponse, error) {
-	m := new(FindMaximumResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *FindMaximumResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return m, nil
+	return mi.MessageOf(x)
 }
 
-func (c *calculatorServiceClient) SquareRoot(ctx context.Context, in *SquareRootRequest, opts ...grpc.CallOption) (*SquareRootResponse, error) {
-	out := new(SquareRootResponse)
-	err := c.cc.Invoke(ctx, "/calculator.CalculatorService/SquareRoot", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+// Deprecated: Use FindMaximumResponse.ProtoReflect.Descriptor instead.
+func (*FindMaximumResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{7}
 }
 
-// CalculatorServiceServer is the server API for CalculatorService service.
-type CalculatorServiceServer interface {
-	// Unary
-	Sum(context.Context, *SumRequest) (*SumResponse, error)
-	// Server Streaming
-	PrimeNumberDecomposition(*PrimeNumberDecompositionRequest, CalculatorService_PrimeNumberDecompositionServer) error
-	// Client Streaming
-	ComputeAverage(CalculatorService_ComputeAverageServer) error
-	// Bi-directional Streaming
-	FindMaximum(CalculatorService_FindMaximumServer) error
-	// Error handling
-	// This RPC will throw an exception if the sent number is negative
-	// The error being sent is of type INVALID_ARGUMENT
-	SquareRoot(context.Context, *SquareRootRequest) (*SquareRootResponse, error)
+func (x *FindMaximumResponse) GetMaximum() int32 {
+	if x != nil {
+		return x.Maximum
+	}
+	return 0
 }
 
-// UnimplementedCalculatorServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedCalculatorServiceServer struct {
+type SquareRootRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (*UnimplementedCalculatorServiceServer) Sum(ctx context.Context, req *SumRequest) (*SumResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Sum not implemented")
-}
-func (*UnimplementedCalculatorServiceServer) PrimeNumberDecomposition(req *PrimeNumberDecompositionRequest, srv CalculatorService_PrimeNumberDecompositionServer) error {
-	return status.Errorf(codes.Unimplemented, "method PrimeNumberDecomposition not implemented")
-}
-func (*UnimplementedCalculatorServiceServer) ComputeAverage(srv CalculatorService_ComputeAverageServer) error {
-	return status.Errorf(codes.Unimplemented, "method ComputeAverage not implemented")
-}
-func (*UnimplementedCalculatorServiceServer) FindMaximum(srv CalculatorService_FindMaximumServer) error {
-	return status.Errorf(codes.Unimplemented, "method FindMaximum not implemented")
-}
-func (*UnimplementedCalculatorServiceServer) SquareRoot(ctx context.Context, req *SquareRootRequest) (*SquareRootResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SquareRoot not implemented")
+func (x *SquareRootRequest) Reset() {
+	*x = SquareRootRequest{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func RegisterCalculatorServiceServer(s *grpc.Server, srv CalculatorServiceServer) {
-	s.RegisterService(&_CalculatorService_serviceDesc, srv)
+func (x *SquareRootRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func _CalculatorService_Sum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SumRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(CalculatorServiceServer).Sum(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/calculator.CalculatorService/Sum",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CalculatorServiceServer).Sum(ctx, req.(*SumRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
+func (*SquareRootRequest) ProtoMessage() {}
 
-func _CalculatorService_PrimeNumberDecomposition_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(PrimeNumberDecompositionRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func (x *SquareRootRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return srv.(CalculatorServiceServer).PrimeNumberDecomposition(m, &calculatorServicePrimeNumberDecompositionServer{stream})
-}
-
-type CalculatorService_PrimeNumberDecompositionServer interface {
-	Send(*PrimeNumberDecompositionResponse) error
-	grpc.ServerStream
+	return mi.MessageOf(x)
 }
 
-type calculatorServicePrimeNumberDecompositionServer struct {
-	grpc.ServerStream
+// Deprecated: Use SquareRootRequest.ProtoReflect.Descriptor instead.
+func (*SquareRootRequest) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *calculatorServicePrimeNumberDecompositionServer) Send(m *PrimeNumberDecompositionResponse) error {
-	return x.ServerStream.SendMsg(m)
+func (x *SquareRootRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
 }
 
-func _CalculatorService_ComputeAverage_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(CalculatorServiceServer).ComputeAverage(&calculatorServiceComputeAverageServer{stream})
+type SquareRootResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NumberRoot    float64                `protobuf:"fixed64,1,opt,name=number_root,json=numberRoot,proto3" json:"number_root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type CalculatorService_ComputeAverageServer interface {
-	SendAndClose(*ComputeAverageResponse) error
-	Recv() (*ComputeAverageRequest, error)
-	grpc.ServerStream
+func (x *SquareRootResponse) Reset() {
+	*x = SquareRootResponse{}
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type calculatorServiceComputeAverageServer struct {
-	grpc.ServerStream
+func (x *SquareRootResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *calculatorServiceComputeAverageServer) SendAndClose(m *ComputeAverageResponse) error {
-	return x.ServerStream.SendMsg(m)
-}
+func (*SquareRootResponse) ProtoMessage() {}
 
-func (x *calculatorServiceComputeAverageServer) Recv() (*ComputeAverageRequest, error) {
-	m := new(ComputeAverageRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *SquareRootResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_calculator_calculatorpb_calculator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return m, nil
-}
-
-func _CalculatorService_FindMaximum_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(CalculatorServiceServer).FindMaximum(&calculatorServiceFindMaximumServer{stream})
-}
-
-type CalculatorService_FindMaximumServer interface {
-	Send(*FindMaximumResponse) error
-	Recv() (*FindMaximumRequest, error)
-	grpc.ServerStream
+	return mi.MessageOf(x)
 }
 
-type calculatorServiceFindMaximumServer struct {
-	grpc.ServerStream
-}
-
-func (x *calculatorServiceFindMaximumServer) Send(m *FindMaximumResponse) error {
-	return x.ServerStream.SendMsg(m)
+// Deprecated: Use SquareRootResponse.ProtoReflect.Descriptor instead.
+func (*SquareRootResponse) Descriptor() ([]byte, []int) {
+	return file_calculator_calculatorpb_calculator_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *calculatorServiceFindMaximumServer) Recv() (*FindMaximumRequest, error) {
-	m := new(FindMaximumRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *SquareRootResponse) GetNumberRoot() float64 {
+	if x != nil {
+		return x.NumberRoot
 	}
-	return m, nil
+	return 0
 }
 
-func _CalculatorService_SquareRoot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SquareRootRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(CalculatorServiceServer).SquareRoot(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/calculator.CalculatorService/SquareRoot",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CalculatorServiceServer).SquareRoot(ctx, req.(*SquareRootRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
+var File_calculator_calculatorpb_calculator_proto protoreflect.FileDescriptor
+
+const file_calculator_calculatorpb_calculator_proto_rawDesc = "" +
+	"\n" +
+	"(calculator/calculatorpb/calculator.proto\x12\n" +
+	"calculator\"T\n" +
+	"\n" +
+	"SumRequest\x12!\n" +
+	"\ffirst_number\x18\x01 \x01(\x05R\vfirstNumber\x12#\n" +
+	"\rsecond_number\x18\x02 \x01(\x05R\fsecondNumber\",\n" +
+	"\vSumResponse\x12\x1d\n" +
+	"\n" +
+	"sum_result\x18\x01 \x01(\x05R\tsumResult\"9\n" +
+	"\x1fPrimeNumberDecompositionRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\"E\n" +
+	" PrimeNumberDecompositionResponse\x12!\n" +
+	"\fprime_factor\x18\x01 \x01(\x03R\vprimeFactor\"/\n" +
+	"\x15ComputeAverageRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\"2\n" +
+	"\x16ComputeAverageResponse\x12\x18\n" +
+	"\aaverage\x18\x01 \x01(\x01R\aaverage\",\n" +
+	"\x12FindMaximumRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\"/\n" +
+	"\x13FindMaximumResponse\x12\x18\n" +
+	"\amaximum\x18\x01 \x01(\x05R\amaximum\"+\n" +
+	"\x11SquareRootRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\"5\n" +
+	"\x12SquareRootResponse\x12\x1f\n" +
+	"\vnumber_root\x18\x01 \x01(\x01R\n" +
+	"numberRoot2\xd9\x03\n" +
+	"\x11CalculatorService\x12;\n" +
+	"\x03Sum\x12\x16.calculator.SumRequest\x1a\x17.calculator.SumResponse\"\x03\x90\x02\x01\x12|\n" +
+	"\x18PrimeNumberDecomposition\x12+.calculator.PrimeNumberDecompositionRequest\x1a,.calculator.PrimeNumberDecompositionResponse\"\x03\x90\x02\x010\x01\x12^\n" +
+	"\x0eComputeAverage\x12!.calculator.ComputeAverageRequest\x1a\".calculator.ComputeAverageResponse\"\x03\x90\x02\x01(\x01\x12W\n" +
+	"\vFindMaximum\x12\x1e.calculator.FindMaximumRequest\x1a\x1f.calculator.FindMaximumResponse\"\x03\x90\x02\x01(\x010\x01\x12P\n" +
+	"\n" +
+	"SquareRoot\x12\x1d.calculator.SquareRootRequest\x1a\x1e.calculator.SquareRootResponse\"\x03\x90\x02\x01BBZ@github.com/angel/golang_api_microservice/calculator/calculatorpbb\x06proto3"
+
+var (
+	file_calculator_calculatorpb_calculator_proto_rawDescOnce sync.Once
+	file_calculator_calculatorpb_calculator_proto_rawDescData []byte
+)
 
-var _CalculatorService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "calculator.CalculatorService",
-	HandlerType: (*CalculatorServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Sum",
-			Handler:    _CalculatorService_Sum_Handler,
-		},
-		{
-			MethodName: "SquareRoot",
-			Handler:    _CalculatorService_SquareRoot_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "PrimeNumberDecomposition",
-			Handler:       _CalculatorService_PrimeNumberDecomposition_Handler,
-			ServerStreams: true,
-		},
-		{
-			StreamName:    "ComputeAverage",
-			Handler:       _CalculatorService_ComputeAverage_Handler,
-			ClientStreams: true,
-		},
-		{
-			StreamName:    "FindMaximum",
-			Handler:       _CalculatorService_FindMaximum_Handler,
-			ServerStreams: true,
-			ClientStreams: true,
+func file_calculator_calculatorpb_calculator_proto_rawDescGZIP() []byte {
+	file_calculator_calculatorpb_calculator_proto_rawDescOnce.Do(func() {
+		file_calculator_calculatorpb_calculator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_calculator_calculatorpb_calculator_proto_rawDesc), len(file_calculator_calculatorpb_calculator_proto_rawDesc)))
+	})
+	return file_calculator_calculatorpb_calculator_proto_rawDescData
+}
+
+var file_calculator_calculatorpb_calculator_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_calculator_calculatorpb_calculator_proto_goTypes = []any{
+	(*SumRequest)(nil),                       // 0: calculator.SumRequest
+	(*SumResponse)(nil),                      // 1: calculator.SumResponse
+	(*PrimeNumberDecompositionRequest)(nil),  // 2: calculator.PrimeNumberDecompositionRequest
+	(*PrimeNumberDecompositionResponse)(nil), // 3: calculator.PrimeNumberDecompositionResponse
+	(*ComputeAverageRequest)(nil),            // 4: calculator.ComputeAverageRequest
+	(*ComputeAverageResponse)(nil),           // 5: calculator.ComputeAverageResponse
+	(*FindMaximumRequest)(nil),               // 6: calculator.FindMaximumRequest
+	(*FindMaximumResponse)(nil),              // 7: calculator.FindMaximumResponse
+	(*SquareRootRequest)(nil),                // 8: calculator.SquareRootRequest
+	(*SquareRootResponse)(nil),               // 9: calculator.SquareRootResponse
+}
+var file_calculator_calculatorpb_calculator_proto_depIdxs = []int32{
+	0, // 0: calculator.CalculatorService.Sum:input_type -> calculator.SumRequest
+	2, // 1: calculator.CalculatorService.PrimeNumberDecomposition:input_type -> calculator.PrimeNumberDecompositionRequest
+	4, // 2: calculator.CalculatorService.ComputeAverage:input_type -> calculator.ComputeAverageRequest
+	6, // 3: calculator.CalculatorService.FindMaximum:input_type -> calculator.FindMaximumRequest
+	8, // 4: calculator.CalculatorService.SquareRoot:input_type -> calculator.SquareRootRequest
+	1, // 5: calculator.CalculatorService.Sum:output_type -> calculator.SumResponse
+	3, // 6: calculator.CalculatorService.PrimeNumberDecomposition:output_type -> calculator.PrimeNumberDecompositionResponse
+	5, // 7: calculator.CalculatorService.ComputeAverage:output_type -> calculator.ComputeAverageResponse
+	7, // 8: calculator.CalculatorService.FindMaximum:output_type -> calculator.FindMaximumResponse
+	9, // 9: calculator.CalculatorService.SquareRoot:output_type -> calculator.SquareRootResponse
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_calculator_calculatorpb_calculator_proto_init() }
+func file_calculator_calculatorpb_calculator_proto_init() {
+	if File_calculator_calculatorpb_calculator_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_calculator_calculatorpb_calculator_proto_rawDesc), len(file_calculator_calculatorpb_calculator_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
 		},
-	},
-	Metadata: "calculator/calculatorpb/calculator.proto",
+		GoTypes:           file_calculator_calculatorpb_calculator_proto_goTypes,
+		DependencyIndexes: file_calculator_calculatorpb_calculator_proto_depIdxs,
+		MessageInfos:      file_calculator_calculatorpb_calculator_proto_msgTypes,
+	}.Build()
+	File_calculator_calculatorpb_calculator_proto = out.File
+	file_calculator_calculatorpb_calculator_proto_goTypes = nil
+	file_calculator_calculatorpb_calculator_proto_depIdxs = nil
 }
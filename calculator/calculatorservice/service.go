@@ -0,0 +1,170 @@
+// Package calculatorservice implements the CalculatorService defined in calculator.proto
+package calculatorservice
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"github.com/angel/golang_api_microservice/internal/shutdown"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server needs to implement the functions of the
+// target interface (in this case "CalculatorServiceServer")
+// which makes this server also of type CalculatorServiceServer
+// we can achieve this by creating the functions defined
+// by CalculatorServiceServer with methods
+type Server struct {
+	calculatorpb.UnimplementedCalculatorServiceServer
+}
+
+// NewServer returns a CalculatorService implementation ready to be registered on a grpc.Server
+func NewServer() *Server {
+	return &Server{}
+}
+
+func (*Server) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	logging.FromContext(ctx).Info("Received Sum RPC", logging.Proto("request", req))
+	firstNumber := req.FirstNumber
+	secondNumber := req.SecondNumber
+
+	sum := firstNumber + secondNumber
+
+	res := &calculatorpb.SumResponse{
+		SumResult: sum,
+	}
+
+	return res, nil
+}
+
+func (*Server) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
+	logging.FromContext(stream.Context()).Info("Received PrimeNumberDecomposition RPC", logging.Proto("request", req))
+
+	number := req.GetNumber()
+	divisor := int64(2)
+
+	for number > 1 {
+		if number%divisor == 0 {
+			err := stream.Send(&calculatorpb.PrimeNumberDecompositionResponse{
+				PrimeFactor: divisor,
+			})
+			if err != nil {
+				logging.FromContext(stream.Context()).Warn("Error while sending data to client", "error", err)
+				return err
+			}
+			number = number / divisor
+		} else {
+			divisor++
+			logging.FromContext(stream.Context()).Debug("Divisor has increased", "divisor", divisor)
+
+			// a large prime keeps this loop busy for a long time, stop when the
+			// client goes away or the server is shutting down
+			select {
+			case <-stream.Context().Done():
+				return status.FromContextError(stream.Context().Err()).Err()
+			case <-shutdown.Draining(stream.Context()):
+				return status.Error(codes.Unavailable, "server is shutting down")
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+func (*Server) ComputeAverage(stream calculatorpb.CalculatorService_ComputeAverageServer) error {
+	logging.FromContext(stream.Context()).Info("Received ComputeAverage RPC")
+
+	sum := int32(0)
+	count := 0
+
+	for {
+		req, err := stream.Recv()
+		// when finished getting all requests, then sendAndClose the stream with the ComputeAverageResponse
+		if err == io.EOF {
+			if count == 0 {
+				return status.Error(codes.InvalidArgument, "no numbers were sent to average")
+			}
+			average := float64(sum) / float64(count)
+			return stream.SendAndClose(&calculatorpb.ComputeAverageResponse{
+				Average: average,
+			})
+		}
+		if err != nil {
+			// stop here, req is nil and the stream is done
+			logging.FromContext(stream.Context()).Warn("Error while reading client stream", "error", err)
+			return err
+		}
+
+		sum += req.GetNumber()
+		count++
+	}
+
+}
+
+// MaxIntSlice return the maximum interger in a slice
+func MaxIntSlice(v []int) int {
+	sort.Ints(v)
+	return v[len(v)-1]
+}
+
+// MinIntSlice return the minimum interger in a slice
+func MinIntSlice(v []int) int {
+	sort.Ints(v)
+	return v[0]
+}
+
+func (*Server) FindMaximum(stream calculatorpb.CalculatorService_FindMaximumServer) error {
+	logging.FromContext(stream.Context()).Info("Received FindMaximum RPC call")
+
+	currentMax := int32(0)
+
+	// receive messages from client
+	for {
+		req, err := stream.Recv()
+		// when we receive the last of the messages, return the max
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			logging.FromContext(stream.Context()).Warn("Error while reading FindMaximum client stream", "error", err)
+			return err
+		}
+
+		incomingNumber := req.GetNumber()
+		logging.FromContext(stream.Context()).Debug("Server received a new number from stream", "number", incomingNumber)
+		// compare incoming value to current max, if its greater, send this new max
+		if incomingNumber > currentMax {
+			currentMax = incomingNumber
+			sendErr := stream.Send(&calculatorpb.FindMaximumResponse{
+				Maximum: currentMax,
+			})
+			if sendErr != nil {
+				logging.FromContext(stream.Context()).Warn("Error while trying to send data to client", "error", sendErr)
+				return sendErr
+			}
+		}
+	}
+
+}
+
+func (*Server) SquareRoot(ctx context.Context, req *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
+	logging.FromContext(ctx).Info("Received SquareRoot RPC")
+
+	number := req.GetNumber()
+
+	if number < 0 {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"received a negative number: %v", number,
+		)
+	}
+
+	return &calculatorpb.SquareRootResponse{
+		NumberRoot: math.Sqrt(float64(number)),
+	}, nil
+}
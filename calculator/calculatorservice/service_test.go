@@ -0,0 +1,216 @@
+package calculatorservice_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+)
+
+// bigPrime keeps PrimeNumberDecomposition busy until the call ends
+const bigPrime = 2305843009213693951
+
+func TestSum(t *testing.T) {
+	c := servertest.Calculator(t)
+	tests := []struct {
+		name string
+		a, b int32
+		want int32
+	}{
+		{"positive", 3, 10, 13},
+		{"negative", -3, -10, -13},
+		{"zero", 0, 0, 0},
+		{"mixed", 7, -10, -3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Sum(context.Background(), tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Sum() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sum(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrimeFactors(t *testing.T) {
+	c := servertest.Calculator(t)
+	tests := []struct {
+		name string
+		n    int64
+		want []int64
+	}{
+		{"composite", 120, []int64{2, 2, 2, 3, 5}},
+		{"prime", 97, []int64{97}},
+		{"power of two", 1024, []int64{2, 2, 2, 2, 2, 2, 2, 2, 2, 2}},
+		{"one is an empty stream", 1, []int64{}},
+		{"zero is an empty stream", 0, []int64{}},
+		{"negative is an empty stream", -12, []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int64{}
+			err := c.PrimeFactors(context.Background(), tt.n, func(factor int64) error {
+				got = append(got, factor)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("PrimeFactors() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("PrimeFactors(%v) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrimeFactorsEndsEarly(t *testing.T) {
+	c := servertest.Calculator(t)
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{"client cancels", func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+			return ctx, cancel
+		}, sdk.ErrCanceled},
+		{"deadline exceeded", func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		}, sdk.ErrDeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			err := c.PrimeFactors(ctx, bigPrime, func(int64) error { return nil })
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("PrimeFactors() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAverage(t *testing.T) {
+	c := servertest.Calculator(t)
+	tests := []struct {
+		name    string
+		numbers []int32
+		want    float64
+		wantErr error
+	}{
+		{name: "several", numbers: []int32{1, 2, 3, 4}, want: 2.5},
+		{name: "one", numbers: []int32{5}, want: 5},
+		{name: "negative", numbers: []int32{-1, -2}, want: -1.5},
+		{name: "empty stream", wantErr: sdk.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Average(context.Background(), tt.numbers)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Average() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Average(%v) = %v, want %v", tt.numbers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaximum(t *testing.T) {
+	c := servertest.Calculator(t)
+	tests := []struct {
+		name    string
+		numbers []int32
+		want    []int32
+	}{
+		{"new maximums only", []int32{1, 5, 3, 6, 2, 20}, []int32{1, 5, 6, 20}},
+		{"decreasing", []int32{9, 8, 7}, []int32{9}},
+		{"empty stream", nil, []int32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := c.Maximum(context.Background())
+			if err != nil {
+				t.Fatalf("Maximum() error = %v", err)
+			}
+			for _, n := range tt.numbers {
+				if err := stream.Send(n); err != nil {
+					t.Fatalf("Send() error = %v", err)
+				}
+			}
+			if err := stream.CloseSend(); err != nil {
+				t.Fatalf("CloseSend() error = %v", err)
+			}
+			got := []int32{}
+			for {
+				max, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Recv() error = %v", err)
+				}
+				got = append(got, max)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Maximum(%v) = %v, want %v", tt.numbers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaximumCanceled(t *testing.T) {
+	c := servertest.Calculator(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.Maximum(ctx)
+	if err != nil {
+		t.Fatalf("Maximum() error = %v", err)
+	}
+	if err := stream.Send(3); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	cancel()
+	if _, err := stream.Recv(); !errors.Is(err, sdk.ErrCanceled) {
+		t.Errorf("Recv() after cancel error = %v, want %v", err, sdk.ErrCanceled)
+	}
+}
+
+func TestSquareRoot(t *testing.T) {
+	c := servertest.Calculator(t)
+	tests := []struct {
+		name    string
+		n       int32
+		want    float64
+		wantErr error
+	}{
+		{name: "perfect square", n: 16, want: 4},
+		{name: "zero", n: 0, want: 0},
+		{name: "irrational", n: 2, want: math.Sqrt2},
+		{name: "negative", n: -1, wantErr: sdk.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.SquareRoot(context.Background(), tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SquareRoot() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SquareRoot(%v) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
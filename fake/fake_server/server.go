@@ -0,0 +1,44 @@
+// fake_server serves scripted fakes of the services, see package fake for the script format:
+//
+//	go run fake/fake_server/server.go --script fake/examples/calculator.yaml --addr 0.0.0.0:50053
+//
+// It has the health service, reflection and every setting of the real servers, e.g. auth or TLS
+package main
+
+import (
+	"flag"
+	"log"
+
+	_ "github.com/angel/golang_api_microservice/blog/blogpb"
+	_ "github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/fake"
+	_ "github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+)
+
+func main() {
+	script := flag.String("script", "", "YAML or JSON file with the rules answering the calls")
+
+	cfg, err := bootstrap.LoadConfig("fake", "0.0.0.0:50051", "0.0.0.0:9059")
+	if err != nil {
+		log.Fatalf("Server could not load config: %v", err)
+	}
+	if *script == "" {
+		log.Fatalf("Server could not load config: --script is required")
+	}
+	f, err := fake.Load(*script)
+	if err != nil {
+		log.Fatalf("Server could not load the script: %v", err)
+	}
+
+	s, err := bootstrap.New(cfg)
+	if err != nil {
+		log.Fatalf("Server could not be created: %v", err)
+	}
+	f.Register(s.GRPC)
+	log.Printf("Faking %v", f.Services())
+
+	if err := s.Run(); err != nil {
+		log.Fatalf("server failed to serve: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/blog/blogclient"
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/fake"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+)
+
+// start serves f over bufconn, calls are not retried so every scripted error shows
+func start(t *testing.T, f *fake.Server) *servertest.Server {
+	t.Helper()
+	return servertest.Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		f.Register(s.GRPC)
+	})
+}
+
+func noRetries() sdk.Option {
+	return sdk.WithRetryPolicy(sdk.RetryPolicy{MaxAttempts: 1})
+}
+
+func TestCalculatorExample(t *testing.T) {
+	f, err := fake.Load("examples/calculator.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := start(t, f)
+	c := calcclient.New(s.Dial(t, []string{calcclient.ServiceName}, noRetries(), sdk.WithBreakerPolicy(sdk.BreakerPolicy{})))
+	ctx := context.Background()
+
+	t.Run("Sum", func(t *testing.T) {
+		tests := []struct {
+			a, b    int32
+			want    int32
+			wantErr error
+		}{
+			{3, 4, 0, sdk.ErrUnavailable},
+			{3, 4, 0, sdk.ErrUnavailable},
+			{1, 1, 2, nil},
+			{3, 4, 42, nil},
+		}
+		for _, tt := range tests {
+			got, err := c.Sum(ctx, tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Sum(%v, %v) = %v, %v, want %v, %v", tt.a, tt.b, got, err, tt.want, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("PrimeFactors", func(t *testing.T) {
+		got := []int64{}
+		err := c.PrimeFactors(ctx, 120, func(f int64) error {
+			got = append(got, f)
+			return nil
+		})
+		if err != nil || !slices.Equal(got, []int64{2, 2, 2, 3, 5}) {
+			t.Errorf("PrimeFactors(120) = %v, %v, want [2 2 2 3 5]", got, err)
+		}
+	})
+
+	t.Run("Average", func(t *testing.T) {
+		if got, err := c.Average(ctx, []int32{1, 2, 3, 4}); err != nil || got != 2.5 {
+			t.Errorf("Average() = %v, %v, want 2.5", got, err)
+		}
+	})
+
+	t.Run("Maximum", func(t *testing.T) {
+		stream, err := c.Maximum(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []int32{1, 2, 3, 4} {
+			if err := stream.Send(n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stream.CloseSend()
+		got := []int32{}
+		for {
+			m, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, m)
+		}
+		if !slices.Equal(got, []int32{3, 8, 10}) {
+			t.Errorf("Maximum() = %v, want [3 8 10]", got)
+		}
+	})
+
+	t.Run("SquareRoot", func(t *testing.T) {
+		if _, err := c.SquareRoot(ctx, -1); !errors.Is(err, sdk.ErrInvalidArgument) {
+			t.Errorf("SquareRoot(-1) error = %v, want InvalidArgument", err)
+		}
+		// the answer takes 2s
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, err := c.SquareRoot(ctx, 9); !errors.Is(err, sdk.ErrDeadlineExceeded) {
+			t.Errorf("SquareRoot(9) error = %v, want DeadlineExceeded", err)
+		}
+	})
+}
+
+func TestBlogExample(t *testing.T) {
+	f, err := fake.Load("examples/blog.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := start(t, f)
+	c := blogclient.New(s.Dial(t, []string{blogclient.ServiceName}, noRetries()))
+	ctx := context.Background()
+
+	got := []string{}
+	err = c.ListModerationQueue(ctx, func(b *blogpb.Blog) error {
+		got = append(got, b.GetTitle())
+		return nil
+	})
+	if err != nil || !slices.Equal(got, []string{"First post", "Second post"}) {
+		t.Errorf("ListModerationQueue() = %v, %v, want both posts", got, err)
+	}
+
+	b, err := c.ApproveContent(ctx, "64b7f0c2e1a4b5c6d7e8f901")
+	if err != nil || b.GetStatus() != blogpb.BlogStatus_PUBLISHED {
+		t.Errorf("ApproveContent() = %v, %v, want a published blog", b, err)
+	}
+	if _, err := c.ApproveContent(ctx, "64b7f0c2e1a4b5c6d7e8f999"); !errors.Is(err, sdk.ErrNotFound) {
+		t.Errorf("ApproveContent() of an unknown blog error = %v, want NotFound", err)
+	}
+	if _, err := c.RejectContent(ctx, "64b7f0c2e1a4b5c6d7e8f901", "spam"); !errors.Is(err, sdk.ErrPermissionDenied) {
+		t.Errorf("RejectContent() error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestUnscripted(t *testing.T) {
+	f, err := fake.Parse([]byte(`
+methods:
+  calculator.CalculatorService/Sum:
+    - match: {first_number: 1}
+      response: {sum_result: 1}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := start(t, f)
+	c := calcclient.New(s.Dial(t, []string{calcclient.ServiceName}, noRetries()))
+
+	if _, err := c.Sum(context.Background(), 2, 2); !errors.Is(err, sdk.ErrUnimplemented) {
+		t.Errorf("Sum() no rule matches, error = %v, want Unimplemented", err)
+	}
+	if _, err := c.SquareRoot(context.Background(), 4); !errors.Is(err, sdk.ErrUnimplemented) {
+		t.Errorf("SquareRoot() not scripted, error = %v, want Unimplemented", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"unknown method", `methods: {calculator.CalculatorService/Product: [{response: {}}]}`, "has no method Product"},
+		{"unknown field", `methods: {calculator.CalculatorService/Sum: [{respones: {}}]}`, `unknown field "respones"`},
+		{"wrong message field", `methods: {calculator.CalculatorService/Sum: [{response: {result: 1}}]}`, "invalid response"},
+		{"stream on unary", `methods: {calculator.CalculatorService/Sum: [{stream: [{message: {}}]}]}`, "use response instead of stream"},
+		{"response on stream", `methods: {calculator.CalculatorService/PrimeNumberDecomposition: [{response: {}}]}`, "use stream instead of response"},
+		{"no response", `methods: {calculator.CalculatorService/Sum: [{delay: 1s}]}`, "a response is needed"},
+		{"unknown code", `methods: {calculator.CalculatorService/Sum: [{error: {code: BROKEN}}]}`, `unknown error code "BROKEN"`},
+		{"invalid delay", `methods: {calculator.CalculatorService/Sum: [{response: {}, delay: soon}]}`, "invalid duration"},
+		{"error rate without error", `methods: {calculator.CalculatorService/Sum: [{response: {}, error_rate: 0.5}]}`, "error_rate needs an error"},
+		{"after on server stream", `methods: {calculator.CalculatorService/PrimeNumberDecomposition: [{stream: [{message: {}, after: 1}]}]}`, "only for bidi streams"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fake.Parse([]byte(tt.script))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// Package fake serves scripted fakes of the greet, calculator and blog services, so
+// clients can be tested without MongoDB or the real servers. A script, in YAML or JSON,
+// lists for each method the rules answering its calls:
+//
+//	methods:
+//	  calculator.CalculatorService/Sum:
+//	    - match: {first_number: 1}
+//	      error: {code: INVALID_ARGUMENT, message: one is not allowed}
+//	    - response: {sum_result: 42}
+//	      delay: 50ms
+//	  calculator.CalculatorService/PrimeNumberDecomposition:
+//	    - stream:
+//	        - message: {prime_factor: 2}
+//	        - message: {prime_factor: 5}
+//	          delay: 1s
+//	      error: {code: UNAVAILABLE, message: connection reset}
+//	      error_rate: 0.5
+//
+// A call is answered by the first rule whose match has the same values as its first
+// request. Messages are written as protojson, with the field names of the .proto files
+package fake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"gopkg.in/yaml.v3"
+)
+
+// Script holds the rules of every faked method
+type Script struct {
+	// Methods maps methods, e.g. calculator.CalculatorService/Sum, to their rules, in order
+	Methods map[string][]Rule `json:"methods"`
+}
+
+// Rule is how a fake answers the calls it matches
+type Rule struct {
+	// Match holds request fields the first request of a call must have, empty matches every call.
+	// Nested messages only need the fields they set
+	Match json.RawMessage `json:"match,omitempty"`
+	// Times is how many calls the rule answers before the next rules take over, 0 for no limit
+	Times int `json:"times,omitempty"`
+	// Delay is how long the fake waits before answering
+	Delay Duration `json:"delay,omitempty"`
+	// Response answers unary and client streaming calls. Client streams get it once the
+	// client is done sending
+	Response json.RawMessage `json:"response,omitempty"`
+	// Stream is sent on server and bidi streams, in order
+	Stream []StreamMessage `json:"stream,omitempty"`
+	// Error ends the call after the stream messages, without waiting for the client to be done sending
+	Error *Status `json:"error,omitempty"`
+	// ErrorRate is the fraction of calls ending with Error, the others succeed. 0 means all of them
+	ErrorRate float64 `json:"error_rate,omitempty"`
+}
+
+// StreamMessage is a response sent on a stream
+type StreamMessage struct {
+	Message json.RawMessage `json:"message"`
+	// Delay is how long the fake waits before sending the message
+	Delay Duration `json:"delay,omitempty"`
+	// After holds the message back until the client sent that many requests, for bidi streams.
+	// It is sent anyway once the client is done sending
+	After int `json:"after,omitempty"`
+}
+
+// Status is a gRPC status, the code is a name such as NOT_FOUND or NotFound
+type Status struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as 50ms
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as 50ms, got %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Load reads a script from a YAML or JSON file and returns the server faking it
+func Load(path string) (*Server, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return s, nil
+}
+
+// Parse reads a script in YAML or JSON, JSON being valid YAML, and returns the server faking it
+func Parse(data []byte) (*Server, error) {
+	// YAML is turned into JSON so messages can be read with protojson
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var script Script
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&script); err != nil {
+		return nil, fmt.Errorf("invalid script: %v", err)
+	}
+	return New(script)
+}
+
+// method is a faked method with its compiled rules
+type method struct {
+	name  string
+	desc  protoreflect.MethodDescriptor
+	rules []*rule
+}
+
+// rule is a Rule with its messages parsed
+type rule struct {
+	Rule
+	match    proto.Message
+	response proto.Message
+	stream   []proto.Message
+	err      error
+	// used counts the calls answered, guarded by the Server's mutex
+	used int
+}
+
+// New returns the server faking script, every message of it is checked against the
+// method's request and response types
+func New(script Script) (*Server, error) {
+	s := &Server{methods: map[string]*method{}}
+	for name, rules := range script.Methods {
+		md, err := findMethod(name)
+		if err != nil {
+			return nil, err
+		}
+		m := &method{name: fmt.Sprintf("/%v/%v", md.Parent().FullName(), md.Name()), desc: md}
+		for i, r := range rules {
+			compiled, err := compile(md, r)
+			if err != nil {
+				return nil, fmt.Errorf("%v rule %v: %v", name, i+1, err)
+			}
+			m.rules = append(m.rules, compiled)
+		}
+		if _, ok := s.methods[m.name]; ok {
+			return nil, fmt.Errorf("method %v is scripted twice", m.name)
+		}
+		s.methods[m.name] = m
+	}
+	return s, nil
+}
+
+func compile(md protoreflect.MethodDescriptor, r Rule) (*rule, error) {
+	c := &rule{Rule: r}
+	var err error
+	switch {
+	case r.Times < 0:
+		return nil, fmt.Errorf("times must not be negative")
+	case r.Delay < 0:
+		return nil, fmt.Errorf("delay must not be negative")
+	case r.ErrorRate < 0 || r.ErrorRate > 1:
+		return nil, fmt.Errorf("error_rate must be between 0 and 1, got %v", r.ErrorRate)
+	case r.ErrorRate > 0 && r.Error == nil:
+		return nil, fmt.Errorf("error_rate needs an error")
+	case md.IsStreamingServer() && len(r.Response) > 0:
+		return nil, fmt.Errorf("%v streams its responses, use stream instead of response", md.Name())
+	case !md.IsStreamingServer() && len(r.Stream) > 0:
+		return nil, fmt.Errorf("%v has a single response, use response instead of stream", md.Name())
+	case !md.IsStreamingServer() && len(r.Response) == 0 && (r.Error == nil || r.ErrorRate > 0):
+		return nil, fmt.Errorf("a response is needed for the calls not failing")
+	}
+
+	if len(r.Match) > 0 {
+		if c.match, err = unmarshal(md.Input(), r.Match); err != nil {
+			return nil, fmt.Errorf("invalid match: %v", err)
+		}
+	}
+	if len(r.Response) > 0 {
+		if c.response, err = unmarshal(md.Output(), r.Response); err != nil {
+			return nil, fmt.Errorf("invalid response: %v", err)
+		}
+	}
+	for i, sm := range r.Stream {
+		switch {
+		case sm.Delay < 0:
+			return nil, fmt.Errorf("stream message %v: delay must not be negative", i+1)
+		case sm.After < 0:
+			return nil, fmt.Errorf("stream message %v: after must not be negative", i+1)
+		case sm.After > 0 && !md.IsStreamingClient():
+			return nil, fmt.Errorf("stream message %v: after is only for bidi streams", i+1)
+		}
+		msg, err := unmarshal(md.Output(), sm.Message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stream message %v: %v", i+1, err)
+		}
+		c.stream = append(c.stream, msg)
+	}
+	if r.Error != nil {
+		code, err := parseCode(r.Error.Code)
+		if err != nil {
+			return nil, err
+		}
+		if code == codes.OK {
+			return nil, fmt.Errorf("error code must not be OK")
+		}
+		c.err = status.Error(code, r.Error.Message)
+	}
+	return c, nil
+}
+
+// findMethod resolves calculator.CalculatorService/Sum, with or without the leading
+// slash, or calculator.CalculatorService.Sum
+func findMethod(name string) (protoreflect.MethodDescriptor, error) {
+	name = strings.TrimPrefix(name, "/")
+	i := strings.LastIndexAny(name, "/.")
+	if i < 0 {
+		return nil, fmt.Errorf("method %q must be service/method, e.g. calculator.CalculatorService/Sum", name)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name[:i]))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find service %v: %v", name[:i], err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a service", name[:i])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(name[i+1:]))
+	if md == nil {
+		return nil, fmt.Errorf("service %v has no method %v", name[:i], name[i+1:])
+	}
+	return md, nil
+}
+
+func unmarshal(md protoreflect.MessageDescriptor, data json.RawMessage) (proto.Message, error) {
+	m := dynamicpb.NewMessage(md)
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseCode accepts the names of the gRPC spec, NOT_FOUND, and of package codes, NotFound
+func parseCode(name string) (codes.Code, error) {
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(`"` + strings.ToUpper(name) + `"`)); err == nil {
+		return c, nil
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), name) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown error code %q", name)
+}
@@ -0,0 +1,301 @@
+package fake
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Server answers calls from a script, register it on a grpc.Server:
+//
+//	f, err := fake.Load("calculator.yaml")
+//	if err != nil { ... }
+//	s := grpc.NewServer()
+//	f.Register(s)
+type Server struct {
+	methods map[string]*method
+
+	mu sync.Mutex
+}
+
+// Services lists the services of the scripted methods
+func (s *Server) Services() []string {
+	var out []string
+	for name := range s.services() {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Register registers every service with a scripted method. Their methods without
+// rules fail with Unimplemented
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	services := s.services()
+	for _, name := range s.Services() {
+		r.RegisterService(s.serviceDesc(services[name]), s)
+	}
+}
+
+func (s *Server) services() map[string]protoreflect.ServiceDescriptor {
+	out := map[string]protoreflect.ServiceDescriptor{}
+	for _, m := range s.methods {
+		sd := m.desc.Parent().(protoreflect.ServiceDescriptor)
+		out[string(sd.FullName())] = sd
+	}
+	return out
+}
+
+// serviceDesc builds the grpc.ServiceDesc of sd with handlers answering from the script
+func (s *Server) serviceDesc(sd protoreflect.ServiceDescriptor) *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{
+		ServiceName: string(sd.FullName()),
+		HandlerType: (*interface{})(nil),
+		Metadata:    sd.ParentFile().Path(),
+	}
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		fullMethod := "/" + string(sd.FullName()) + "/" + string(md.Name())
+		if !md.IsStreamingClient() && !md.IsStreamingServer() {
+			desc.Methods = append(desc.Methods, grpc.MethodDesc{
+				MethodName: string(md.Name()),
+				Handler:    s.unaryHandler(md, fullMethod),
+			})
+			continue
+		}
+		desc.Streams = append(desc.Streams, grpc.StreamDesc{
+			StreamName:    string(md.Name()),
+			ServerStreams: md.IsStreamingServer(),
+			ClientStreams: md.IsStreamingClient(),
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				return s.stream(stream, md, fullMethod)
+			},
+		})
+	}
+	return desc
+}
+
+func (s *Server) unaryHandler(md protoreflect.MethodDescriptor, fullMethod string) grpc.MethodHandler {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := dynamicpb.NewMessage(md.Input())
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return s.unary(ctx, fullMethod, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.unary(ctx, fullMethod, req.(proto.Message))
+		})
+	}
+}
+
+func (s *Server) unary(ctx context.Context, fullMethod string, req proto.Message) (interface{}, error) {
+	r, err := s.pick(fullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, time.Duration(r.Delay)); err != nil {
+		return nil, err
+	}
+	if err := r.outcome(); err != nil {
+		return nil, err
+	}
+	return proto.Clone(r.response), nil
+}
+
+func (s *Server) stream(stream grpc.ServerStream, md protoreflect.MethodDescriptor, fullMethod string) error {
+	ctx := stream.Context()
+
+	// the first request picks the rule, client streams only wait for it when a rule matches on it
+	var first proto.Message
+	received, clientDone := 0, false
+	if !md.IsStreamingClient() || s.matchesRequests(fullMethod) {
+		first = dynamicpb.NewMessage(md.Input())
+		err := stream.RecvMsg(first)
+		switch {
+		case err == io.EOF:
+			first, clientDone = nil, true
+		case err != nil:
+			return err
+		default:
+			received = 1
+		}
+	}
+	r, err := s.pick(fullMethod, first)
+	if err != nil {
+		return err
+	}
+	if err := sleep(ctx, time.Duration(r.Delay)); err != nil {
+		return err
+	}
+
+	// the requests of client streams are read on the side, counted for the messages sent after some
+	var reqs *requestCounter
+	if md.IsStreamingClient() && !clientDone {
+		reqs = countRequests(ctx, stream, md.Input(), received)
+	}
+
+	for i, msg := range r.stream {
+		if err := sleep(ctx, time.Duration(r.Stream[i].Delay)); err != nil {
+			return err
+		}
+		if reqs != nil {
+			if err := reqs.waitFor(ctx, r.Stream[i].After); err != nil {
+				return err
+			}
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+	if err := r.outcome(); err != nil {
+		return err
+	}
+
+	// client and bidi streams end once the client is done sending, like the real services
+	if reqs != nil {
+		if err := reqs.waitFor(ctx, -1); err != nil {
+			return err
+		}
+	}
+	if !md.IsStreamingServer() {
+		return stream.SendMsg(proto.Clone(r.response))
+	}
+	return nil
+}
+
+// matchesRequests reports whether a rule of the method has a match
+func (s *Server) matchesRequests(fullMethod string) bool {
+	m, ok := s.methods[fullMethod]
+	if !ok {
+		return false
+	}
+	for _, r := range m.rules {
+		if r.match != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// pick returns the first rule answering a call with req as its first request,
+// req is nil when the client sent none
+func (s *Server) pick(fullMethod string, req proto.Message) (*rule, error) {
+	m, ok := s.methods[fullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "%v is not scripted", fullMethod)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range m.rules {
+		if r.Times > 0 && r.used >= r.Times {
+			continue
+		}
+		if r.match != nil && (req == nil || !matches(r.match.ProtoReflect(), req.ProtoReflect())) {
+			continue
+		}
+		r.used++
+		return r, nil
+	}
+	return nil, status.Errorf(codes.Unimplemented, "no rule of the script answers this call of %v", fullMethod)
+}
+
+// outcome returns the error ending the call, nil when it succeeds
+func (r *rule) outcome() error {
+	if r.err == nil || r.ErrorRate > 0 && rand.Float64() >= r.ErrorRate {
+		return nil
+	}
+	return r.err
+}
+
+// matches reports whether got has the fields set in want, with the same values.
+// Nested messages are compared the same way
+func matches(want, got protoreflect.Message) bool {
+	ok := true
+	want.Range(func(fd protoreflect.FieldDescriptor, wv protoreflect.Value) bool {
+		switch {
+		case fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			ok = got.Has(fd) && matches(wv.Message(), got.Get(fd).Message())
+		default:
+			ok = wv.Equal(got.Get(fd))
+		}
+		return ok
+	})
+	return ok
+}
+
+// requestCounter reads the requests of a client stream and counts them
+type requestCounter struct {
+	received int
+	done     bool
+	// counted gets a value per request, it is closed when the client is done sending
+	counted chan struct{}
+	err     error
+}
+
+func countRequests(ctx context.Context, stream grpc.ServerStream, md protoreflect.MessageDescriptor, received int) *requestCounter {
+	c := &requestCounter{received: received, counted: make(chan struct{}, 16)}
+	go func() {
+		defer close(c.counted)
+		for {
+			err := stream.RecvMsg(dynamicpb.NewMessage(md))
+			if err != nil {
+				if err != io.EOF {
+					c.err = err
+				}
+				return
+			}
+			select {
+			case c.counted <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// waitFor waits until n requests were received, or the client is done sending for n < 0
+func (c *requestCounter) waitFor(ctx context.Context, n int) error {
+	for !c.done && (n < 0 || c.received < n) {
+		select {
+		case _, ok := <-c.counted:
+			if !ok {
+				c.done = true
+				return c.err
+			}
+			c.received++
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return nil
+}
+
+// sleep waits for d, or returns the status of ctx when it is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+// gateway_server serves the greet, calculator and blog services as HTTP/JSON and
+// gRPC-Web for clients that cannot speak gRPC, browsers included. It forwards every
+// call to the gRPC servers, along with the Authorization, X-Api-Key and X-Request-Id headers
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/angel/golang_api_microservice/blog/blogclient"
+	_ "github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	_ "github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	_ "github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/internal/config"
+	"github.com/angel/golang_api_microservice/internal/gateway"
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"github.com/angel/golang_api_microservice/internal/tlsutil"
+	"github.com/angel/golang_api_microservice/internal/tracing"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	addr := flag.String("addr", "0.0.0.0:8080", "HTTP address to listen on")
+	drainTimeout := flag.Duration("drain-timeout", 30*time.Second, "how long in-flight requests get to finish on shutdown")
+	dumpOpenAPI := flag.String("dump-openapi", "", "write the OpenAPI spec to this file (- for stdout) and exit")
+	targets := map[string]*string{
+		"greet.GreetService":           flag.String("greet.target", "localhost:50051", "address of the greet server, empty to leave its routes out"),
+		"calculator.CalculatorService": flag.String("calculator.target", "localhost:50053", "address of the calculator server, empty to leave its routes out"),
+		"blog.BlogService":             flag.String("blog.target", "localhost:50052", "address of the blog server, empty to leave its routes out"),
+	}
+	balancer := flag.String("balancer", sdk.RoundRobin, "how requests are spread over the backends of a target: pick_first, round_robin or least_request")
+	hedge := flag.Bool("hedge", false, "hedge the idempotent calls (Sum, SquareRoot, ReadBlog) against slow backends")
+	corsCfg := gateway.CORSConfig{}
+	corsCfg.RegisterFlags(flag.CommandLine)
+	tlsCfg := tlsutil.ClientConfig{}
+	tlsCfg.RegisterFlags(flag.CommandLine)
+	logCfg := logging.Config{}
+	logCfg.RegisterFlags(flag.CommandLine)
+	tracingCfg := tracing.Config{}
+	tracingCfg.RegisterFlags(flag.CommandLine)
+	if err := config.Load(flag.CommandLine, "gateway", os.Args[1:]); err != nil {
+		if err == config.ErrPrintConfig {
+			return
+		}
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	for _, validate := range []func() error{corsCfg.Validate, tlsCfg.Validate, logCfg.Validate, tracingCfg.Validate} {
+		if err := validate(); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+	}
+	if err := logging.Setup(logCfg, "gateway"); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	stopTracing, err := tracing.Setup(context.Background(), "gateway", tracingCfg)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer stopTracing(context.Background())
+
+	transport, err := tlsCfg.DialOption()
+	if err != nil {
+		log.Fatalf("Failed to load TLS credentials: %v", err)
+	}
+	hedging := sdk.HedgingPolicy{}
+	if *hedge {
+		hedging.Methods = slices.Concat(calcclient.IdempotentMethods, blogclient.IdempotentMethods)
+	}
+	conns := map[string]grpc.ClientConnInterface{}
+	for service, target := range targets {
+		if *target == "" {
+			continue
+		}
+		// HTTP clients bring their own deadlines and retries, only the balancing is wanted here
+		cc, err := sdk.Dial(*target, []string{service},
+			sdk.WithTransport(transport),
+			sdk.WithTimeout(0),
+			sdk.WithRetryPolicy(sdk.RetryPolicy{MaxAttempts: 1}),
+			sdk.WithBalancer(*balancer),
+			sdk.WithHedging(hedging),
+			sdk.WithDialOptions(tracing.DialOption()),
+		)
+		if err != nil {
+			log.Fatalf("Failed to connect to %v: %v", *target, err)
+		}
+		defer cc.Close()
+		conns[service] = cc
+	}
+
+	gw, err := gateway.New(conns, gateway.DefaultRoutes, gateway.WithCheckOrigin(corsCfg.CheckOrigin))
+	if err != nil {
+		log.Fatalf("Failed to create gateway: %v", err)
+	}
+
+	if *dumpOpenAPI != "" {
+		spec, err := gw.OpenAPI()
+		if err != nil {
+			log.Fatalf("Failed to build the OpenAPI spec: %v", err)
+		}
+		spec = append(spec, '\n')
+		if *dumpOpenAPI == "-" {
+			os.Stdout.Write(spec)
+			return
+		}
+		if err := os.WriteFile(*dumpOpenAPI, spec, 0644); err != nil {
+			log.Fatalf("Failed to write the OpenAPI spec: %v", err)
+		}
+		return
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: corsCfg.Handler(gw)}
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Printf("Starting gateway on %v\n", *addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-errCh:
+		log.Fatalf("Failed to serve: %v", err)
+	case <-ch:
+	}
+
+	fmt.Println("Stopping the gateway")
+	ctx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Printf("Gateway did not drain in %v: %v\n", *drainTimeout, err)
+	}
+}
@@ -1,8 +1,14 @@
 #!/bin/bash
 
-protoc greet/greetpb/greet.proto --go_out=plugins=grpc:.
-protoc calculator/calculatorpb/calculator.proto --go_out=plugins=grpc:.
-protoc blog/blogpb/blog.proto --go_out=plugins=grpc:.
+# needs protoc-gen-go and protoc-gen-go-grpc on the PATH:
+# go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.9
+# go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+for proto in greet/greetpb/greet.proto calculator/calculatorpb/calculator.proto blog/blogpb/blog.proto; do
+    protoc $proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative
+done
+
+# the HTTP gateway's OpenAPI spec is built from the compiled descriptors
+go run gateway_server/server.go --dump-openapi gateway_server/openapi.json
 
 # to start mongodb database
 # from /Users/angeldionisio/Documents/mongodb-macos-x86_64-4.2.0
@@ -1,137 +1,29 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"io"
 	"log"
-	"net"
-	"strconv"
-	"time"
 
 	"github.com/angel/golang_api_microservice/greet/greetpb"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/angel/golang_api_microservice/greet/greetservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
 )
 
-type server struct{}
-
-// Greet implements the `GreetServiceServer` interface in greet.pb.go (greet protobuff)
-// this defines our server, takes context, a pointer to GreetRequest and returns
-// a pointer to GreetResponse and an error
-func (*server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
-	fmt.Printf("Greet function was invoked with %v\n", req)
-	// we can get GetGreeting because request (req) is a greetRequest and it contains a greeting
-	firstName := req.GetGreeting().GetFirstName()
-	result := "Hello " + firstName
-
-	// GreetResponse is a struct that returns key `Result` of type string
-	res := &greetpb.GreetResponse{
-		Result: result,
-	}
-
-	return res, nil
-}
-
-func (s *server) GreetManyTimes(req *greetpb.GreetManyTimesRequest, stream greetpb.GreetService_GreetManyTimesServer) error {
-	fmt.Printf("GreetManyTimes function was invoked with %v\n", req)
-	firstName := req.GetGreeting().GetFirstName()
-	for i := 0; i < 10; i++ {
-		result := "Hello " + firstName + " number " + strconv.Itoa(i)
-		res := &greetpb.GreetManyTimesResponse{
-			Result: result,
-		}
-		stream.Send(res)
-		time.Sleep(1000 * time.Millisecond)
-	}
-	return nil
-}
-
-func (s *server) LongGreet(stream greetpb.GreetService_LongGreetServer) error {
-	fmt.Printf("LongGreet function was invoked with a streaming request\n")
-	result := ""
-
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			// we have finished reading the client stream
-			return stream.SendAndClose(&greetpb.LongGreetResponse{
-				Result: result,
-			})
-		}
-		if err != nil {
-			log.Fatalf("Error while reading client stream: %v", err)
-		}
-
-		firstName := req.GetGreeting().GetFirstName()
-		result += "Hello " + firstName + "! "
-	}
-
-}
-
-// GreetEveryone is a bi-directional API that sends a greeting everytime it gets a message
-func (s *server) GreetEveryone(stream greetpb.GreetService_GreetEveryoneServer) error {
-	fmt.Printf("GreetEveryone function was invoked with a streaming request\n")
-
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			log.Fatalf("Error while reading client stream: %v", err)
-			return err
-		}
-
-		firstName := req.GetGreeting().GetFirstName()
-		result := fmt.Sprintf("Hello %v! ", firstName)
-		// send back response from server
-		sendErr := stream.Send(&greetpb.GreetEveryoneResponse{
-			Result: result,
-		})
-		if sendErr != nil {
-			log.Fatalf("Error while sending data to client: %v", sendErr)
-		}
-	}
-}
-
-func (*server) GreetWithDeadline(ctx context.Context, req *greetpb.GreetWithDeadlineRequest) (*greetpb.GreetWithDeadlineResponse, error) {
-	fmt.Printf("GreetWithDeadline function was invoked with %v\n", req)
-	// having server wait three seconds, and checking within context if the client has cancelled the request
-	// this allows us to test the timeout functionality
-	for i := 0; i < 3; i++ {
-		if ctx.Err() == context.Canceled {
-			// the client has cancelled the request
-			fmt.Println("the client has cancelled the request!")
-			return nil, status.Error(codes.Canceled, "the client has cancelled the request")
-		}
-		time.Sleep(1 * time.Second)
-	}
-
-	firstName := req.GetGreeting().GetFirstName()
-	lastName := req.GetGreeting().GetLastName()
-	result := fmt.Sprintf("Hello %v, %v", firstName, lastName)
-
-	res := &greetpb.GreetWithDeadlineResponse{
-		Result: result,
-	}
-
-	return res, nil
-}
-
 func main() {
 	fmt.Println("Hello from server!")
 
-	lis, err := net.Listen("tcp", "0.0.0.0:50051")
+	cfg, err := bootstrap.LoadConfig("greet", "0.0.0.0:50051", "0.0.0.0:9051")
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	s := grpc.NewServer()
-	greetpb.RegisterGreetServiceServer(s, &server{})
+	s, err := bootstrap.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+	greetpb.RegisterGreetServiceServer(s.GRPC, greetservice.NewServer())
 
-	if err := s.Serve(lis); err != nil {
+	if err := s.Run(); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
@@ -0,0 +1,146 @@
+// Package greetclient is the Go client of the GreetService. Errors are *sdk.Error values
+package greetclient
+
+import (
+	"context"
+	"io"
+
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the full name of the GreetService, for sdk.Dial
+const ServiceName = "greet.GreetService"
+
+// Client calls the GreetService, it is safe for concurrent use
+type Client struct {
+	rpc greetpb.GreetServiceClient
+	cc  *grpc.ClientConn
+}
+
+// Dial connects to the greet server at target
+func Dial(target string, opts ...sdk.Option) (*Client, error) {
+	cc, err := sdk.Dial(target, []string{ServiceName}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := New(cc)
+	c.cc = cc
+	return c, nil
+}
+
+// New returns a client using cc, which can be shared with other clients, see sdk.Dial
+func New(cc *grpc.ClientConn) *Client {
+	return &Client{rpc: greetpb.NewGreetServiceClient(cc)}
+}
+
+// Close closes the connection opened by Dial, it does nothing for clients made with New
+func (c *Client) Close() error {
+	if c.cc == nil {
+		return nil
+	}
+	return c.cc.Close()
+}
+
+// Greet returns the greeting for g
+func (c *Client) Greet(ctx context.Context, g *greetpb.Greeting) (string, error) {
+	res, err := c.rpc.Greet(ctx, &greetpb.GreetRequest{Greeting: g})
+	if err != nil {
+		return "", sdk.FromError(err)
+	}
+	return res.GetResult(), nil
+}
+
+// GreetManyTimes calls fn with every greeting the server streams back.
+// An error from fn cancels the stream and is returned
+func (c *Client) GreetManyTimes(ctx context.Context, g *greetpb.Greeting, fn func(result string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.GreetManyTimes(ctx, &greetpb.GreetManyTimesRequest{Greeting: g})
+	if err != nil {
+		return sdk.FromError(err)
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return sdk.FromError(err)
+		}
+		if err := fn(res.GetResult()); err != nil {
+			return err
+		}
+	}
+}
+
+// LongGreet sends every greeting and returns the combined result
+func (c *Client) LongGreet(ctx context.Context, greetings []*greetpb.Greeting) (string, error) {
+	stream, err := c.rpc.LongGreet(ctx)
+	if err != nil {
+		return "", sdk.FromError(err)
+	}
+	for _, g := range greetings {
+		if err := stream.Send(&greetpb.LongGreetRequest{Greeting: g}); err != nil {
+			// the reason comes back from CloseAndRecv
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", sdk.FromError(err)
+	}
+	return res.GetResult(), nil
+}
+
+// GreetEveryone opens a stream answering every greeting sent as soon as it arrives
+func (c *Client) GreetEveryone(ctx context.Context) (*EveryoneStream, error) {
+	stream, err := c.rpc.GreetEveryone(ctx)
+	if err != nil {
+		return nil, sdk.FromError(err)
+	}
+	return &EveryoneStream{stream: stream}, nil
+}
+
+// GreetWithDeadline returns the greeting for g after about three seconds,
+// use a context deadline to see the call time out
+func (c *Client) GreetWithDeadline(ctx context.Context, g *greetpb.Greeting) (string, error) {
+	res, err := c.rpc.GreetWithDeadline(ctx, &greetpb.GreetWithDeadlineRequest{Greeting: g})
+	if err != nil {
+		return "", sdk.FromError(err)
+	}
+	return res.GetResult(), nil
+}
+
+// EveryoneStream is an open GreetEveryone call. Send and Recv may be called from
+// different goroutines, but neither from more than one at a time
+type EveryoneStream struct {
+	stream greetpb.GreetService_GreetEveryoneClient
+}
+
+// Send sends a greeting, its answer comes from Recv
+func (s *EveryoneStream) Send(g *greetpb.Greeting) error {
+	if err := s.stream.Send(&greetpb.GreetEveryoneRequest{Greeting: g}); err != nil {
+		if err == io.EOF {
+			// the stream is over, Recv returns why
+			return err
+		}
+		return sdk.FromError(err)
+	}
+	return nil
+}
+
+// CloseSend tells the server no more greetings are coming
+func (s *EveryoneStream) CloseSend() error {
+	return s.stream.CloseSend()
+}
+
+// Recv returns the next answer, io.EOF once the server has answered everything
+func (s *EveryoneStream) Recv() (string, error) {
+	res, err := s.stream.Recv()
+	if err != nil {
+		return "", sdk.FromError(err)
+	}
+	return res.GetResult(), nil
+}
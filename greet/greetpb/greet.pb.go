@@ -1,834 +1,623 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
 // source: greet/greetpb/greet.proto
 
 package greetpb
 
 import (
-	context "context"
-	fmt "fmt"
-	proto "github.com/golang/protobuf/proto"
-	grpc "google.golang.org/grpc"
-	codes "google.golang.org/grpc/codes"
-	status "google.golang.org/grpc/status"
-	math "math"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
 )
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ = proto.Marshal
-var _ = fmt.Errorf
-var _ = math.Inf
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
 
 type Greeting struct {
-	FirstName            string   `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
-	LastName             string   `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstName     string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *Greeting) Reset()         { *m = Greeting{} }
-func (m *Greeting) String() string { return proto.CompactTextString(m) }
-func (*Greeting) ProtoMessage()    {}
-func (*Greeting) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{0}
+func (x *Greeting) Reset() {
+	*x = Greeting{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *Greeting) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Greeting.Unmarshal(m, b)
-}
-func (m *Greeting) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Greeting.Marshal(b, m, deterministic)
-}
-func (m *Greeting) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Greeting.Merge(m, src)
-}
-func (m *Greeting) XXX_Size() int {
-	return xxx_messageInfo_Greeting.Size(m)
+func (x *Greeting) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-func (m *Greeting) XXX_DiscardUnknown() {
-	xxx_messageInfo_Greeting.DiscardUnknown(m)
+
+func (*Greeting) ProtoMessage() {}
+
+func (x *Greeting) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var xxx_messageInfo_Greeting proto.InternalMessageInfo
+// Deprecated: Use Greeting.ProtoReflect.Descriptor instead.
+func (*Greeting) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{0}
+}
 
-func (m *Greeting) GetFirstName() string {
-	if m != nil {
-		return m.FirstName
+func (x *Greeting) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
 	}
 	return ""
 }
 
-func (m *Greeting) GetLastName() string {
-	if m != nil {
-		return m.LastName
+func (x *Greeting) GetLastName() string {
+	if x != nil {
+		return x.LastName
 	}
 	return ""
 }
 
 type GreetRequest struct {
-	Greeting             *Greeting `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Greeting      *Greeting              `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *GreetRequest) Reset()         { *m = GreetRequest{} }
-func (m *GreetRequest) String() string { return proto.CompactTextString(m) }
-func (*GreetRequest) ProtoMessage()    {}
-func (*GreetRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{1}
+func (x *GreetRequest) Reset() {
+	*x = GreetRequest{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *GreetRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetRequest.Unmarshal(m, b)
-}
-func (m *GreetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetRequest.Marshal(b, m, deterministic)
-}
-func (m *GreetRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetRequest.Merge(m, src)
+func (x *GreetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-func (m *GreetRequest) XXX_Size() int {
-	return xxx_messageInfo_GreetRequest.Size(m)
-}
-func (m *GreetRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetRequest.DiscardUnknown(m)
+
+func (*GreetRequest) ProtoMessage() {}
+
+func (x *GreetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var xxx_messageInfo_GreetRequest proto.InternalMessageInfo
+// Deprecated: Use GreetRequest.ProtoReflect.Descriptor instead.
+func (*GreetRequest) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{1}
+}
 
-func (m *GreetRequest) GetGreeting() *Greeting {
-	if m != nil {
-		return m.Greeting
+func (x *GreetRequest) GetGreeting() *Greeting {
+	if x != nil {
+		return x.Greeting
 	}
 	return nil
 }
 
 type GreetResponse struct {
-	Result               string   `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *GreetResponse) Reset()         { *m = GreetResponse{} }
-func (m *GreetResponse) String() string { return proto.CompactTextString(m) }
-func (*GreetResponse) ProtoMessage()    {}
-func (*GreetResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{2}
+func (x *GreetResponse) Reset() {
+	*x = GreetResponse{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *GreetResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetResponse.Unmarshal(m, b)
-}
-func (m *GreetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetResponse.Marshal(b, m, deterministic)
-}
-func (m *GreetResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetResponse.Merge(m, src)
-}
-func (m *GreetResponse) XXX_Size() int {
-	return xxx_messageInfo_GreetResponse.Size(m)
-}
-func (m *GreetResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetResponse.DiscardUnknown(m)
+func (x *GreetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_GreetResponse proto.InternalMessageInfo
+func (*GreetResponse) ProtoMessage() {}
 
-func (m *GreetResponse) GetResult() string {
-	if m != nil {
-		return m.Result
+func (x *GreetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
-}
-
-type GreetManyTimesRequest struct {
-	Greeting             *Greeting `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
-}
-
-func (m *GreetManyTimesRequest) Reset()         { *m = GreetManyTimesRequest{} }
-func (m *GreetManyTimesRequest) String() string { return proto.CompactTextString(m) }
-func (*GreetManyTimesRequest) ProtoMessage()    {}
-func (*GreetManyTimesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{3}
+	return mi.MessageOf(x)
 }
 
-func (m *GreetManyTimesRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetManyTimesRequest.Unmarshal(m, b)
-}
-func (m *GreetManyTimesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetManyTimesRequest.Marshal(b, m, deterministic)
-}
-func (m *GreetManyTimesRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetManyTimesRequest.Merge(m, src)
-}
-func (m *GreetManyTimesRequest) XXX_Size() int {
-	return xxx_messageInfo_GreetManyTimesRequest.Size(m)
-}
-func (m *GreetManyTimesRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetManyTimesRequest.DiscardUnknown(m)
+// Deprecated: Use GreetResponse.ProtoReflect.Descriptor instead.
+func (*GreetResponse) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{2}
 }
 
-var xxx_messageInfo_GreetManyTimesRequest proto.InternalMessageInfo
-
-func (m *GreetManyTimesRequest) GetGreeting() *Greeting {
-	if m != nil {
-		return m.Greeting
+func (x *GreetResponse) GetResult() string {
+	if x != nil {
+		return x.Result
 	}
-	return nil
+	return ""
 }
 
-type GreetManyTimesResponse struct {
-	Result               string   `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type GreetManyTimesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Greeting      *Greeting              `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *GreetManyTimesResponse) Reset()         { *m = GreetManyTimesResponse{} }
-func (m *GreetManyTimesResponse) String() string { return proto.CompactTextString(m) }
-func (*GreetManyTimesResponse) ProtoMessage()    {}
-func (*GreetManyTimesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{4}
+func (x *GreetManyTimesRequest) Reset() {
+	*x = GreetManyTimesRequest{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *GreetManyTimesResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetManyTimesResponse.Unmarshal(m, b)
-}
-func (m *GreetManyTimesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetManyTimesResponse.Marshal(b, m, deterministic)
-}
-func (m *GreetManyTimesResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetManyTimesResponse.Merge(m, src)
-}
-func (m *GreetManyTimesResponse) XXX_Size() int {
-	return xxx_messageInfo_GreetManyTimesResponse.Size(m)
-}
-func (m *GreetManyTimesResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetManyTimesResponse.DiscardUnknown(m)
+func (x *GreetManyTimesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_GreetManyTimesResponse proto.InternalMessageInfo
+func (*GreetManyTimesRequest) ProtoMessage() {}
 
-func (m *GreetManyTimesResponse) GetResult() string {
-	if m != nil {
-		return m.Result
+func (x *GreetManyTimesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-type LongGreetRequest struct {
-	Greeting             *Greeting `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
-}
-
-func (m *LongGreetRequest) Reset()         { *m = LongGreetRequest{} }
-func (m *LongGreetRequest) String() string { return proto.CompactTextString(m) }
-func (*LongGreetRequest) ProtoMessage()    {}
-func (*LongGreetRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{5}
-}
-
-func (m *LongGreetRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_LongGreetRequest.Unmarshal(m, b)
-}
-func (m *LongGreetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_LongGreetRequest.Marshal(b, m, deterministic)
-}
-func (m *LongGreetRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_LongGreetRequest.Merge(m, src)
-}
-func (m *LongGreetRequest) XXX_Size() int {
-	return xxx_messageInfo_LongGreetRequest.Size(m)
-}
-func (m *LongGreetRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_LongGreetRequest.DiscardUnknown(m)
+// Deprecated: Use GreetManyTimesRequest.ProtoReflect.Descriptor instead.
+func (*GreetManyTimesRequest) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{3}
 }
 
-var xxx_messageInfo_LongGreetRequest proto.InternalMessageInfo
-
-func (m *LongGreetRequest) GetGreeting() *Greeting {
-	if m != nil {
-		return m.Greeting
+func (x *GreetManyTimesRequest) GetGreeting() *Greeting {
+	if x != nil {
+		return x.Greeting
 	}
 	return nil
 }
 
-type LongGreetResponse struct {
-	Result               string   `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type GreetManyTimesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *LongGreetResponse) Reset()         { *m = LongGreetResponse{} }
-func (m *LongGreetResponse) String() string { return proto.CompactTextString(m) }
-func (*LongGreetResponse) ProtoMessage()    {}
-func (*LongGreetResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{6}
+func (x *GreetManyTimesResponse) Reset() {
+	*x = GreetManyTimesResponse{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *LongGreetResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_LongGreetResponse.Unmarshal(m, b)
-}
-func (m *LongGreetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_LongGreetResponse.Marshal(b, m, deterministic)
-}
-func (m *LongGreetResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_LongGreetResponse.Merge(m, src)
-}
-func (m *LongGreetResponse) XXX_Size() int {
-	return xxx_messageInfo_LongGreetResponse.Size(m)
-}
-func (m *LongGreetResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_LongGreetResponse.DiscardUnknown(m)
+func (x *GreetManyTimesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_LongGreetResponse proto.InternalMessageInfo
+func (*GreetManyTimesResponse) ProtoMessage() {}
 
-func (m *LongGreetResponse) GetResult() string {
-	if m != nil {
-		return m.Result
+func (x *GreetManyTimesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
-}
-
-type GreetEveryoneRequest struct {
-	Greeting             *Greeting `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
-}
-
-func (m *GreetEveryoneRequest) Reset()         { *m = GreetEveryoneRequest{} }
-func (m *GreetEveryoneRequest) String() string { return proto.CompactTextString(m) }
-func (*GreetEveryoneRequest) ProtoMessage()    {}
-func (*GreetEveryoneRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{7}
+	return mi.MessageOf(x)
 }
 
-func (m *GreetEveryoneRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetEveryoneRequest.Unmarshal(m, b)
-}
-func (m *GreetEveryoneRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetEveryoneRequest.Marshal(b, m, deterministic)
-}
-func (m *GreetEveryoneRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetEveryoneRequest.Merge(m, src)
-}
-func (m *GreetEveryoneRequest) XXX_Size() int {
-	return xxx_messageInfo_GreetEveryoneRequest.Size(m)
-}
-func (m *GreetEveryoneRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetEveryoneRequest.DiscardUnknown(m)
+// Deprecated: Use GreetManyTimesResponse.ProtoReflect.Descriptor instead.
+func (*GreetManyTimesResponse) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{4}
 }
 
-var xxx_messageInfo_GreetEveryoneRequest proto.InternalMessageInfo
-
-func (m *GreetEveryoneRequest) GetGreeting() *Greeting {
-	if m != nil {
-		return m.Greeting
+func (x *GreetManyTimesResponse) GetResult() string {
+	if x != nil {
+		return x.Result
 	}
-	return nil
+	return ""
 }
 
-type GreetEveryoneResponse struct {
-	Result               string   `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type LongGreetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Greeting      *Greeting              `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *GreetEveryoneResponse) Reset()         { *m = GreetEveryoneResponse{} }
-func (m *GreetEveryoneResponse) String() string { return proto.CompactTextString(m) }
-func (*GreetEveryoneResponse) ProtoMessage()    {}
-func (*GreetEveryoneResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{8}
+func (x *LongGreetRequest) Reset() {
+	*x = LongGreetRequest{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *GreetEveryoneResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetEveryoneResponse.Unmarshal(m, b)
-}
-func (m *GreetEveryoneResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetEveryoneResponse.Marshal(b, m, deterministic)
-}
-func (m *GreetEveryoneResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetEveryoneResponse.Merge(m, src)
-}
-func (m *GreetEveryoneResponse) XXX_Size() int {
-	return xxx_messageInfo_GreetEveryoneResponse.Size(m)
-}
-func (m *GreetEveryoneResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetEveryoneResponse.DiscardUnknown(m)
+func (x *LongGreetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_GreetEveryoneResponse proto.InternalMessageInfo
+func (*LongGreetRequest) ProtoMessage() {}
 
-func (m *GreetEveryoneResponse) GetResult() string {
-	if m != nil {
-		return m.Result
+func (x *LongGreetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-type GreetWithDeadlineRequest struct {
-	Greeting             *Greeting `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
-}
-
-func (m *GreetWithDeadlineRequest) Reset()         { *m = GreetWithDeadlineRequest{} }
-func (m *GreetWithDeadlineRequest) String() string { return proto.CompactTextString(m) }
-func (*GreetWithDeadlineRequest) ProtoMessage()    {}
-func (*GreetWithDeadlineRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{9}
-}
-
-func (m *GreetWithDeadlineRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetWithDeadlineRequest.Unmarshal(m, b)
-}
-func (m *GreetWithDeadlineRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetWithDeadlineRequest.Marshal(b, m, deterministic)
-}
-func (m *GreetWithDeadlineRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetWithDeadlineRequest.Merge(m, src)
-}
-func (m *GreetWithDeadlineRequest) XXX_Size() int {
-	return xxx_messageInfo_GreetWithDeadlineRequest.Size(m)
-}
-func (m *GreetWithDeadlineRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetWithDeadlineRequest.DiscardUnknown(m)
+// Deprecated: Use LongGreetRequest.ProtoReflect.Descriptor instead.
+func (*LongGreetRequest) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{5}
 }
 
-var xxx_messageInfo_GreetWithDeadlineRequest proto.InternalMessageInfo
-
-func (m *GreetWithDeadlineRequest) GetGreeting() *Greeting {
-	if m != nil {
-		return m.Greeting
+func (x *LongGreetRequest) GetGreeting() *Greeting {
+	if x != nil {
+		return x.Greeting
 	}
 	return nil
 }
 
-type GreetWithDeadlineResponse struct {
-	Result               string   `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type LongGreetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *GreetWithDeadlineResponse) Reset()         { *m = GreetWithDeadlineResponse{} }
-func (m *GreetWithDeadlineResponse) String() string { return proto.CompactTextString(m) }
-func (*GreetWithDeadlineResponse) ProtoMessage()    {}
-func (*GreetWithDeadlineResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe6f881da19a2871, []int{10}
+func (x *LongGreetResponse) Reset() {
+	*x = LongGreetResponse{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (m *GreetWithDeadlineResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GreetWithDeadlineResponse.Unmarshal(m, b)
-}
-func (m *GreetWithDeadlineResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GreetWithDeadlineResponse.Marshal(b, m, deterministic)
-}
-func (m *GreetWithDeadlineResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GreetWithDeadlineResponse.Merge(m, src)
-}
-func (m *GreetWithDeadlineResponse) XXX_Size() int {
-	return xxx_messageInfo_GreetWithDeadlineResponse.Size(m)
-}
-func (m *GreetWithDeadlineResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GreetWithDeadlineResponse.DiscardUnknown(m)
+func (x *LongGreetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var xxx_messageInfo_GreetWithDeadlineResponse proto.InternalMessageInfo
+func (*LongGreetResponse) ProtoMessage() {}
 
-func (m *GreetWithDeadlineResponse) GetResult() string {
-	if m != nil {
-		return m.Result
+func (x *LongGreetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func init() {
-	proto.RegisterType((*Greeting)(nil), "greet.Greeting")
-	proto.RegisterType((*GreetRequest)(nil), "greet.GreetRequest")
-	proto.RegisterType((*GreetResponse)(nil), "greet.GreetResponse")
-	proto.RegisterType((*GreetManyTimesRequest)(nil), "greet.GreetManyTimesRequest")
-	proto.RegisterType((*GreetManyTimesResponse)(nil), "greet.GreetManyTimesResponse")
-	proto.RegisterType((*LongGreetRequest)(nil), "greet.LongGreetRequest")
-	proto.RegisterType((*LongGreetResponse)(nil), "greet.LongGreetResponse")
-	proto.RegisterType((*GreetEveryoneRequest)(nil), "greet.GreetEveryoneRequest")
-	proto.RegisterType((*GreetEveryoneResponse)(nil), "greet.GreetEveryoneResponse")
-	proto.RegisterType((*GreetWithDeadlineRequest)(nil), "greet.GreetWithDeadlineRequest")
-	proto.RegisterType((*GreetWithDeadlineResponse)(nil), "greet.GreetWithDeadlineResponse")
-}
-
-func init() { proto.RegisterFile("greet/greetpb/greet.proto", fileDescriptor_fe6f881da19a2871) }
-
-var fileDescriptor_fe6f881da19a2871 = []byte{
-	// 373 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0x4f, 0x4f, 0xc2, 0x40,
-	0x10, 0xc5, 0xad, 0x06, 0xa4, 0xe3, 0x5f, 0x46, 0x44, 0x28, 0x10, 0x49, 0x2f, 0x92, 0x90, 0x00,
-	0x01, 0x6f, 0x1e, 0x4c, 0x10, 0xe5, 0xa2, 0x46, 0xd1, 0x44, 0xe3, 0xc5, 0x14, 0x1d, 0x6b, 0x13,
-	0x68, 0xb1, 0x5d, 0x48, 0xf8, 0x04, 0x7e, 0x6d, 0xc3, 0xb2, 0x0b, 0xa5, 0x80, 0x4d, 0x7a, 0x69,
-	0xbb, 0xfb, 0xde, 0xfe, 0xde, 0x76, 0x67, 0xb2, 0x90, 0x35, 0x5d, 0x22, 0x56, 0xe5, 0xcf, 0x41,
-	0x77, 0xfa, 0xae, 0x0c, 0x5c, 0x87, 0x39, 0x18, 0xe3, 0x03, 0xfd, 0x06, 0x12, 0xed, 0xc9, 0x87,
-	0x65, 0x9b, 0x58, 0x00, 0xf8, 0xb2, 0x5c, 0x8f, 0xbd, 0xdb, 0x46, 0x9f, 0x32, 0x4a, 0x51, 0x29,
-	0xa9, 0x1d, 0x95, 0xcf, 0xdc, 0x1b, 0x7d, 0xc2, 0x1c, 0xa8, 0x3d, 0x43, 0xaa, 0x9b, 0x5c, 0x4d,
-	0x4c, 0x26, 0x26, 0xa2, 0x7e, 0x01, 0xbb, 0x9c, 0xd3, 0xa1, 0x9f, 0x21, 0x79, 0x0c, 0xcb, 0x90,
-	0x30, 0x05, 0x97, 0x93, 0x76, 0xea, 0x07, 0x95, 0x69, 0xbc, 0x8c, 0xeb, 0xcc, 0x0c, 0xfa, 0x19,
-	0xec, 0x89, 0xc5, 0xde, 0xc0, 0xb1, 0x3d, 0xc2, 0x34, 0xc4, 0x5d, 0xf2, 0x86, 0x3d, 0x26, 0x76,
-	0x21, 0x46, 0x7a, 0x0b, 0x8e, 0xb9, 0xf1, 0xce, 0xb0, 0xc7, 0xcf, 0x56, 0x9f, 0xbc, 0x48, 0x71,
-	0x35, 0x48, 0x07, 0x29, 0x21, 0xb9, 0x97, 0x70, 0x78, 0xeb, 0xd8, 0x66, 0xf4, 0x3f, 0x2c, 0x43,
-	0xd2, 0x07, 0x08, 0x49, 0xbb, 0x82, 0x14, 0x37, 0x5e, 0x8f, 0xc8, 0x1d, 0x3b, 0x36, 0x45, 0x4a,
-	0xac, 0x8a, 0xa3, 0x9a, 0x43, 0x42, 0x52, 0xdb, 0x90, 0xe1, 0x0b, 0x5e, 0x2c, 0xf6, 0xdd, 0x22,
-	0xe3, 0xb3, 0x67, 0x45, 0x4c, 0x6e, 0x40, 0x76, 0x05, 0xe8, 0xff, 0xf4, 0xfa, 0xef, 0x96, 0x68,
-	0xa0, 0x27, 0x72, 0x47, 0xd6, 0x07, 0xe1, 0x39, 0xc4, 0xf8, 0x18, 0x8f, 0xfc, 0x49, 0x62, 0x43,
-	0x5a, 0x6a, 0x71, 0x72, 0x0a, 0xd7, 0x37, 0xf0, 0x11, 0xf6, 0x17, 0x4b, 0x8b, 0x79, 0xbf, 0x33,
-	0xd8, 0x37, 0x5a, 0x61, 0x8d, 0x2a, 0x81, 0x35, 0x05, 0x9b, 0xa0, 0xce, 0x4a, 0x87, 0x27, 0xc2,
-	0x1f, 0xec, 0x06, 0x2d, 0xb3, 0x2c, 0x48, 0x46, 0x49, 0xc1, 0x07, 0xd1, 0xe0, 0xb2, 0x18, 0x98,
-	0xf3, 0xe7, 0x06, 0xea, 0xac, 0xe5, 0x57, 0x8b, 0x73, 0x5e, 0x4d, 0xc1, 0x57, 0x48, 0x2e, 0x1d,
-	0x32, 0x9e, 0xfa, 0x17, 0xae, 0xa8, 0xa3, 0x56, 0x5c, 0x6f, 0x90, 0xf4, 0xa6, 0xfa, 0xb6, 0x2d,
-	0xee, 0x8b, 0x6e, 0x9c, 0x5f, 0x15, 0x8d, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x01, 0x6f, 0x0a,
-	0x03, 0x47, 0x04, 0x00, 0x00,
-}
-
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// GreetServiceClient is the client API for GreetService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type GreetServiceClient interface {
-	// Unary API
-	Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error)
-	// Server Streaming
-	GreetManyTimes(ctx context.Context, in *GreetManyTimesRequest, opts ...grpc.CallOption) (GreetService_GreetManyTimesClient, error)
-	// Client Streaming
-	LongGreet(ctx context.Context, opts ...grpc.CallOption) (GreetService_LongGreetClient, error)
-	// Bi-directional Streaming
-	GreetEveryone(ctx context.Context, opts ...grpc.CallOption) (GreetService_GreetEveryoneClient, error)
-	// Unary With Dealine
-	GreetWithDeadline(ctx context.Context, in *GreetWithDeadlineRequest, opts ...grpc.CallOption) (*GreetWithDeadlineResponse, error)
-}
-
-type greetServiceClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewGreetServiceClient(cc *grpc.ClientConn) GreetServiceClient {
-	return &greetServiceClient{cc}
-}
-
-func (c *greetServiceClient) Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error) {
-	out := new(GreetResponse)
-	err := c.cc.Invoke(ctx, "/greet.GreetService/Greet", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+// Deprecated: Use LongGreetResponse.ProtoReflect.Descriptor instead.
+func (*LongGreetResponse) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{6}
 }
 
-func (c *greetServiceClient) GreetManyTimes(ctx context.Context, in *GreetManyTimesRequest, opts ...grpc.CallOption) (GreetService_GreetManyTimesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_GreetService_serviceDesc.Streams[0], "/greet.GreetService/GreetManyTimes", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &greetServiceGreetManyTimesClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+func (x *LongGreetResponse) GetResult() string {
+	if x != nil {
+		return x.Result
 	}
-	return x, nil
-}
-
-type GreetService_GreetManyTimesClient interface {
-	Recv() (*GreetManyTimesResponse, error)
-	grpc.ClientStream
+	return ""
 }
 
-type greetServiceGreetManyTimesClient struct {
-	grpc.ClientStream
+type GreetEveryoneRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Greeting      *Greeting              `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *greetServiceGreetManyTimesClient) Recv() (*GreetManyTimesResponse, error) {
-	m := new(GreetManyTimesResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
+func (x *GreetEveryoneRequest) Reset() {
+	*x = GreetEveryoneRequest{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (c *greetServiceClient) LongGreet(ctx context.Context, opts ...grpc.CallOption) (GreetService_LongGreetClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_GreetService_serviceDesc.Streams[1], "/greet.GreetService/LongGreet", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &greetServiceLongGreetClient{stream}
-	return x, nil
+func (x *GreetEveryoneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type GreetService_LongGreetClient interface {
-	Send(*LongGreetRequest) error
-	CloseAndRecv() (*LongGreetResponse, error)
-	grpc.ClientStream
-}
+func (*GreetEveryoneRequest) ProtoMessage() {}
 
-type greetServiceLongGreetClient struct {
-	grpc.ClientStream
+func (x *GreetEveryoneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *greetServiceLongGreetClient) Send(m *LongGreetRequest) error {
-	return x.ClientStream.SendMsg(m)
+// Deprecated: Use GreetEveryoneRequest.ProtoReflect.Descriptor instead.
+func (*GreetEveryoneRequest) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *greetServiceLongGreetClient) CloseAndRecv() (*LongGreetResponse, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	m := new(LongGreetResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *GreetEveryoneRequest) GetGreeting() *Greeting {
+	if x != nil {
+		return x.Greeting
 	}
-	return m, nil
+	return nil
 }
 
-func (c *greetServiceClient) GreetEveryone(ctx context.Context, opts ...grpc.CallOption) (GreetService_GreetEveryoneClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_GreetService_serviceDesc.Streams[2], "/greet.GreetService/GreetEveryone", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &greetServiceGreetEveryoneClient{stream}
-	return x, nil
+type GreetEveryoneResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type GreetService_GreetEveryoneClient interface {
-	Send(*GreetEveryoneRequest) error
-	Recv() (*GreetEveryoneResponse, error)
-	grpc.ClientStream
+func (x *GreetEveryoneResponse) Reset() {
+	*x = GreetEveryoneResponse{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type greetServiceGreetEveryoneClient struct {
-	grpc.ClientStream
+func (x *GreetEveryoneResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *greetServiceGreetEveryoneClient) Send(m *GreetEveryoneRequest) error {
-	return x.ClientStream.SendMsg(m)
-}
+func (*GreetEveryoneResponse) ProtoMessage() {}
 
-func (x *greetServiceGreetEveryoneClient) Recv() (*GreetEveryoneResponse, error) {
-	m := new(GreetEveryoneResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *GreetEveryoneResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return m, nil
+	return mi.MessageOf(x)
 }
 
-func (c *greetServiceClient) GreetWithDeadline(ctx context.Context, in *GreetWithDeadlineRequest, opts ...grpc.CallOption) (*GreetWithDeadlineResponse, error) {
-	out := new(GreetWithDeadlineResponse)
-	err := c.cc.Invoke(ctx, "/greet.GreetService/GreetWithDeadline", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+// Deprecated: Use GreetEveryoneResponse.ProtoReflect.Descriptor instead.
+func (*GreetEveryoneResponse) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{8}
 }
 
-// GreetServiceServer is the server API for GreetService service.
-type GreetServiceServer interface {
-	// Unary API
-	Greet(context.Context, *GreetRequest) (*GreetResponse, error)
-	// Server Streaming
-	GreetManyTimes(*GreetManyTimesRequest, GreetService_GreetManyTimesServer) error
-	// Client Streaming
-	LongGreet(GreetService_LongGreetServer) error
-	// Bi-directional Streaming
-	GreetEveryone(GreetService_GreetEveryoneServer) error
-	// Unary With Dealine
-	GreetWithDeadline(context.Context, *GreetWithDeadlineRequest) (*GreetWithDeadlineResponse, error)
+func (x *GreetEveryoneResponse) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
 }
 
-// UnimplementedGreetServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedGreetServiceServer struct {
+type GreetWithDeadlineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Greeting      *Greeting              `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (*UnimplementedGreetServiceServer) Greet(ctx context.Context, req *GreetRequest) (*GreetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Greet not implemented")
-}
-func (*UnimplementedGreetServiceServer) GreetManyTimes(req *GreetManyTimesRequest, srv GreetService_GreetManyTimesServer) error {
-	return status.Errorf(codes.Unimplemented, "method GreetManyTimes not implemented")
-}
-func (*UnimplementedGreetServiceServer) LongGreet(srv GreetService_LongGreetServer) error {
-	return status.Errorf(codes.Unimplemented, "method LongGreet not implemented")
-}
-func (*UnimplementedGreetServiceServer) GreetEveryone(srv GreetService_GreetEveryoneServer) error {
-	return status.Errorf(codes.Unimplemented, "method GreetEveryone not implemented")
-}
-func (*UnimplementedGreetServiceServer) GreetWithDeadline(ctx context.Context, req *GreetWithDeadlineRequest) (*GreetWithDeadlineResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GreetWithDeadline not implemented")
+func (x *GreetWithDeadlineRequest) Reset() {
+	*x = GreetWithDeadlineRequest{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func RegisterGreetServiceServer(s *grpc.Server, srv GreetServiceServer) {
-	s.RegisterService(&_GreetService_serviceDesc, srv)
+func (x *GreetWithDeadlineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func _GreetService_Greet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GreetRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(GreetServiceServer).Greet(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/greet.GreetService/Greet",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GreetServiceServer).Greet(ctx, req.(*GreetRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
+func (*GreetWithDeadlineRequest) ProtoMessage() {}
 
-func _GreetService_GreetManyTimes_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(GreetManyTimesRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func (x *GreetWithDeadlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return srv.(GreetServiceServer).GreetManyTimes(m, &greetServiceGreetManyTimesServer{stream})
+	return mi.MessageOf(x)
 }
 
-type GreetService_GreetManyTimesServer interface {
-	Send(*GreetManyTimesResponse) error
-	grpc.ServerStream
-}
-
-type greetServiceGreetManyTimesServer struct {
-	grpc.ServerStream
+// Deprecated: Use GreetWithDeadlineRequest.ProtoReflect.Descriptor instead.
+func (*GreetWithDeadlineRequest) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *greetServiceGreetManyTimesServer) Send(m *GreetManyTimesResponse) error {
-	return x.ServerStream.SendMsg(m)
+func (x *GreetWithDeadlineRequest) GetGreeting() *Greeting {
+	if x != nil {
+		return x.Greeting
+	}
+	return nil
 }
 
-func _GreetService_LongGreet_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(GreetServiceServer).LongGreet(&greetServiceLongGreetServer{stream})
+type GreetWithDeadlineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type GreetService_LongGreetServer interface {
-	SendAndClose(*LongGreetResponse) error
-	Recv() (*LongGreetRequest, error)
-	grpc.ServerStream
+func (x *GreetWithDeadlineResponse) Reset() {
+	*x = GreetWithDeadlineResponse{}
+	mi := &file_greet_greetpb_greet_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type greetServiceLongGreetServer struct {
-	grpc.ServerStream
+func (x *GreetWithDeadlineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *greetServiceLongGreetServer) SendAndClose(m *LongGreetResponse) error {
-	return x.ServerStream.SendMsg(m)
-}
+func (*GreetWithDeadlineResponse) ProtoMessage() {}
 
-func (x *greetServiceLongGreetServer) Recv() (*LongGreetRequest, error) {
-	m := new(LongGreetRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *GreetWithDeadlineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greet_greetpb_greet_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return m, nil
-}
-
-func _GreetService_GreetEveryone_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(GreetServiceServer).GreetEveryone(&greetServiceGreetEveryoneServer{stream})
-}
-
-type GreetService_GreetEveryoneServer interface {
-	Send(*GreetEveryoneResponse) error
-	Recv() (*GreetEveryoneRequest, error)
-	grpc.ServerStream
-}
-
-type greetServiceGreetEveryoneServer struct {
-	grpc.ServerStream
+	return mi.MessageOf(x)
 }
 
-func (x *greetServiceGreetEveryoneServer) Send(m *GreetEveryoneResponse) error {
-	return x.ServerStream.SendMsg(m)
+// Deprecated: Use GreetWithDeadlineResponse.ProtoReflect.Descriptor instead.
+func (*GreetWithDeadlineResponse) Descriptor() ([]byte, []int) {
+	return file_greet_greetpb_greet_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *greetServiceGreetEveryoneServer) Recv() (*GreetEveryoneRequest, error) {
-	m := new(GreetEveryoneRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *GreetWithDeadlineResponse) GetResult() string {
+	if x != nil {
+		return x.Result
 	}
-	return m, nil
+	return ""
 }
 
-func _GreetService_GreetWithDeadline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GreetWithDeadlineRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(GreetServiceServer).GreetWithDeadline(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/greet.GreetService/GreetWithDeadline",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GreetServiceServer).GreetWithDeadline(ctx, req.(*GreetWithDeadlineRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
+var File_greet_greetpb_greet_proto protoreflect.FileDescriptor
+
+const file_greet_greetpb_greet_proto_rawDesc = "" +
+	"\n" +
+	"\x19greet/greetpb/greet.proto\x12\x05greet\"F\n" +
+	"\bGreeting\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x01 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x02 \x01(\tR\blastName\";\n" +
+	"\fGreetRequest\x12+\n" +
+	"\bgreeting\x18\x01 \x01(\v2\x0f.greet.GreetingR\bgreeting\"'\n" +
+	"\rGreetResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\"D\n" +
+	"\x15GreetManyTimesRequest\x12+\n" +
+	"\bgreeting\x18\x01 \x01(\v2\x0f.greet.GreetingR\bgreeting\"0\n" +
+	"\x16GreetManyTimesResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\"?\n" +
+	"\x10LongGreetRequest\x12+\n" +
+	"\bgreeting\x18\x01 \x01(\v2\x0f.greet.GreetingR\bgreeting\"+\n" +
+	"\x11LongGreetResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\"C\n" +
+	"\x14GreetEveryoneRequest\x12+\n" +
+	"\bgreeting\x18\x01 \x01(\v2\x0f.greet.GreetingR\bgreeting\"/\n" +
+	"\x15GreetEveryoneResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\"G\n" +
+	"\x18GreetWithDeadlineRequest\x12+\n" +
+	"\bgreeting\x18\x01 \x01(\v2\x0f.greet.GreetingR\bgreeting\"3\n" +
+	"\x19GreetWithDeadlineResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result2\x96\x03\n" +
+	"\fGreetService\x127\n" +
+	"\x05Greet\x12\x13.greet.GreetRequest\x1a\x14.greet.GreetResponse\"\x03\x90\x02\x01\x12T\n" +
+	"\x0eGreetManyTimes\x12\x1c.greet.GreetManyTimesRequest\x1a\x1d.greet.GreetManyTimesResponse\"\x03\x90\x02\x010\x01\x12E\n" +
+	"\tLongGreet\x12\x17.greet.LongGreetRequest\x1a\x18.greet.LongGreetResponse\"\x03\x90\x02\x01(\x01\x12S\n" +
+	"\rGreetEveryone\x12\x1b.greet.GreetEveryoneRequest\x1a\x1c.greet.GreetEveryoneResponse\"\x03\x90\x02\x01(\x010\x01\x12[\n" +
+	"\x11GreetWithDeadline\x12\x1f.greet.GreetWithDeadlineRequest\x1a .greet.GreetWithDeadlineResponse\"\x03\x90\x02\x01B8Z6github.com/angel/golang_api_microservice/greet/greetpbb\x06proto3"
+
+var (
+	file_greet_greetpb_greet_proto_rawDescOnce sync.Once
+	file_greet_greetpb_greet_proto_rawDescData []byte
+)
 
-var _GreetService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "greet.GreetService",
-	HandlerType: (*GreetServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Greet",
-			Handler:    _GreetService_Greet_Handler,
-		},
-		{
-			MethodName: "GreetWithDeadline",
-			Handler:    _GreetService_GreetWithDeadline_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "GreetManyTimes",
-			Handler:       _GreetService_GreetManyTimes_Handler,
-			ServerStreams: true,
-		},
-		{
-			StreamName:    "LongGreet",
-			Handler:       _GreetService_LongGreet_Handler,
-			ClientStreams: true,
-		},
-		{
-			StreamName:    "GreetEveryone",
-			Handler:       _GreetService_GreetEveryone_Handler,
-			ServerStreams: true,
-			ClientStreams: true,
+func file_greet_greetpb_greet_proto_rawDescGZIP() []byte {
+	file_greet_greetpb_greet_proto_rawDescOnce.Do(func() {
+		file_greet_greetpb_greet_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_greet_greetpb_greet_proto_rawDesc), len(file_greet_greetpb_greet_proto_rawDesc)))
+	})
+	return file_greet_greetpb_greet_proto_rawDescData
+}
+
+var file_greet_greetpb_greet_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_greet_greetpb_greet_proto_goTypes = []any{
+	(*Greeting)(nil),                  // 0: greet.Greeting
+	(*GreetRequest)(nil),              // 1: greet.GreetRequest
+	(*GreetResponse)(nil),             // 2: greet.GreetResponse
+	(*GreetManyTimesRequest)(nil),     // 3: greet.GreetManyTimesRequest
+	(*GreetManyTimesResponse)(nil),    // 4: greet.GreetManyTimesResponse
+	(*LongGreetRequest)(nil),          // 5: greet.LongGreetRequest
+	(*LongGreetResponse)(nil),         // 6: greet.LongGreetResponse
+	(*GreetEveryoneRequest)(nil),      // 7: greet.GreetEveryoneRequest
+	(*GreetEveryoneResponse)(nil),     // 8: greet.GreetEveryoneResponse
+	(*GreetWithDeadlineRequest)(nil),  // 9: greet.GreetWithDeadlineRequest
+	(*GreetWithDeadlineResponse)(nil), // 10: greet.GreetWithDeadlineResponse
+}
+var file_greet_greetpb_greet_proto_depIdxs = []int32{
+	0,  // 0: greet.GreetRequest.greeting:type_name -> greet.Greeting
+	0,  // 1: greet.GreetManyTimesRequest.greeting:type_name -> greet.Greeting
+	0,  // 2: greet.LongGreetRequest.greeting:type_name -> greet.Greeting
+	0,  // 3: greet.GreetEveryoneRequest.greeting:type_name -> greet.Greeting
+	0,  // 4: greet.GreetWithDeadlineRequest.greeting:type_name -> greet.Greeting
+	1,  // 5: greet.GreetService.Greet:input_type -> greet.GreetRequest
+	3,  // 6: greet.GreetService.GreetManyTimes:input_type -> greet.GreetManyTimesRequest
+	5,  // 7: greet.GreetService.LongGreet:input_type -> greet.LongGreetRequest
+	7,  // 8: greet.GreetService.GreetEveryone:input_type -> greet.GreetEveryoneRequest
+	9,  // 9: greet.GreetService.GreetWithDeadline:input_type -> greet.GreetWithDeadlineRequest
+	2,  // 10: greet.GreetService.Greet:output_type -> greet.GreetResponse
+	4,  // 11: greet.GreetService.GreetManyTimes:output_type -> greet.GreetManyTimesResponse
+	6,  // 12: greet.GreetService.LongGreet:output_type -> greet.LongGreetResponse
+	8,  // 13: greet.GreetService.GreetEveryone:output_type -> greet.GreetEveryoneResponse
+	10, // 14: greet.GreetService.GreetWithDeadline:output_type -> greet.GreetWithDeadlineResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_greet_greetpb_greet_proto_init() }
+func file_greet_greetpb_greet_proto_init() {
+	if File_greet_greetpb_greet_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_greet_greetpb_greet_proto_rawDesc), len(file_greet_greetpb_greet_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
 		},
-	},
-	Metadata: "greet/greetpb/greet.proto",
+		GoTypes:           file_greet_greetpb_greet_proto_goTypes,
+		DependencyIndexes: file_greet_greetpb_greet_proto_depIdxs,
+		MessageInfos:      file_greet_greetpb_greet_proto_msgTypes,
+	}.Build()
+	File_greet_greetpb_greet_proto = out.File
+	file_greet_greetpb_greet_proto_goTypes = nil
+	file_greet_greetpb_greet_proto_depIdxs = nil
 }
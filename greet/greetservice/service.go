@@ -0,0 +1,167 @@
+// Package greetservice implements the GreetService defined in greet.proto
+package greetservice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/internal/clock"
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"github.com/angel/golang_api_microservice/internal/shutdown"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements greetpb.GreetServiceServer
+type Server struct {
+	greetpb.UnimplementedGreetServiceServer
+
+	// clock paces GreetManyTimes and GreetWithDeadline
+	clock clock.Clock
+}
+
+// Option configures NewServer
+type Option func(*Server)
+
+// WithClock replaces the system clock, e.g. with a clocktest.Fake so tests
+// don't wait for the slow methods
+func WithClock(c clock.Clock) Option {
+	return func(s *Server) {
+		s.clock = c
+	}
+}
+
+// NewServer returns a GreetService implementation ready to be registered on a grpc.Server
+func NewServer(opts ...Option) *Server {
+	s := &Server{clock: clock.Real}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Greet implements the `GreetServiceServer` interface in greet.pb.go (greet protobuff)
+// this defines our server, takes context, a pointer to GreetRequest and returns
+// a pointer to GreetResponse and an error
+func (*Server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	logging.FromContext(ctx).Info("Greet function was invoked", logging.Proto("request", req))
+	// we can get GetGreeting because request (req) is a greetRequest and it contains a greeting
+	firstName := req.GetGreeting().GetFirstName()
+	result := "Hello " + firstName
+
+	// GreetResponse is a struct that returns key `Result` of type string
+	res := &greetpb.GreetResponse{
+		Result: result,
+	}
+
+	return res, nil
+}
+
+func (s *Server) GreetManyTimes(req *greetpb.GreetManyTimesRequest, stream greetpb.GreetService_GreetManyTimesServer) error {
+	logging.FromContext(stream.Context()).Info("GreetManyTimes function was invoked", logging.Proto("request", req))
+	firstName := req.GetGreeting().GetFirstName()
+	for i := 0; i < 10; i++ {
+		result := "Hello " + firstName + " number " + strconv.Itoa(i)
+		res := &greetpb.GreetManyTimesResponse{
+			Result: result,
+		}
+		if err := stream.Send(res); err != nil {
+			logging.FromContext(stream.Context()).Warn("Error while sending data to client", "error", err)
+			return err
+		}
+
+		// wait a second between greetings, but stop early when the client goes away
+		// or the server is shutting down, so the stream ends cleanly instead of being cut off
+		select {
+		case <-s.clock.After(1000 * time.Millisecond):
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-shutdown.Draining(stream.Context()):
+			return status.Errorf(codes.Unavailable, "server is shutting down, sent %v of 10 greetings", i+1)
+		}
+	}
+	return nil
+}
+
+func (s *Server) LongGreet(stream greetpb.GreetService_LongGreetServer) error {
+	logging.FromContext(stream.Context()).Info("LongGreet function was invoked with a streaming request")
+	result := ""
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			// we have finished reading the client stream
+			return stream.SendAndClose(&greetpb.LongGreetResponse{
+				Result: result,
+			})
+		}
+		if err != nil {
+			// a client going away only ends its own stream, the error is already a gRPC status
+			logging.FromContext(stream.Context()).Warn("Error while reading client stream", "error", err)
+			return err
+		}
+
+		firstName := req.GetGreeting().GetFirstName()
+		result += "Hello " + firstName + "! "
+	}
+
+}
+
+// GreetEveryone is a bi-directional API that sends a greeting everytime it gets a message
+func (s *Server) GreetEveryone(stream greetpb.GreetService_GreetEveryoneServer) error {
+	logging.FromContext(stream.Context()).Info("GreetEveryone function was invoked with a streaming request")
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			logging.FromContext(stream.Context()).Warn("Error while reading client stream", "error", err)
+			return err
+		}
+
+		firstName := req.GetGreeting().GetFirstName()
+		result := fmt.Sprintf("Hello %v! ", firstName)
+		// send back response from server
+		sendErr := stream.Send(&greetpb.GreetEveryoneResponse{
+			Result: result,
+		})
+		if sendErr != nil {
+			logging.FromContext(stream.Context()).Warn("Error while sending data to client", "error", sendErr)
+			return sendErr
+		}
+	}
+}
+
+func (s *Server) GreetWithDeadline(ctx context.Context, req *greetpb.GreetWithDeadlineRequest) (*greetpb.GreetWithDeadlineResponse, error) {
+	logging.FromContext(ctx).Info("GreetWithDeadline function was invoked", logging.Proto("request", req))
+	// having server wait three seconds, and checking within context if the client has cancelled the request
+	// this allows us to test the timeout functionality
+	for i := 0; i < 3; i++ {
+		select {
+		case <-s.clock.After(1 * time.Second):
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				// the client has cancelled the request
+				logging.FromContext(ctx).Info("the client has cancelled the request")
+				return nil, status.Error(codes.Canceled, "the client has cancelled the request")
+			}
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	firstName := req.GetGreeting().GetFirstName()
+	lastName := req.GetGreeting().GetLastName()
+	result := fmt.Sprintf("Hello %v, %v", firstName, lastName)
+
+	res := &greetpb.GreetWithDeadlineResponse{
+		Result: result,
+	}
+
+	return res, nil
+}
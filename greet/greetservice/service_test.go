@@ -0,0 +1,254 @@
+package greetservice_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/greet/greetservice"
+	"github.com/angel/golang_api_microservice/internal/clock/clocktest"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+)
+
+// drive advances clk by a second every time the server waits on it, until stop is called
+func drive(clk *clocktest.Fake) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if clk.BlockUntil(1, 10*time.Millisecond) {
+				clk.Advance(time.Second)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func TestGreet(t *testing.T) {
+	c := servertest.Greet(t)
+	tests := []struct {
+		name     string
+		greeting *greetpb.Greeting
+		want     string
+	}{
+		{"first name", &greetpb.Greeting{FirstName: "Angel"}, "Hello Angel"},
+		{"last name is left out", &greetpb.Greeting{FirstName: "Angel", LastName: "Dionisio"}, "Hello Angel"},
+		{"empty greeting", &greetpb.Greeting{}, "Hello "},
+		{"no greeting", nil, "Hello "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Greet(context.Background(), tt.greeting)
+			if err != nil {
+				t.Fatalf("Greet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Greet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGreetManyTimes(t *testing.T) {
+	tests := []struct {
+		name string
+		// advance is whether the fake clock moves, without it the server stops after a greeting
+		advance bool
+		// cancelAfter cancels the call after that many greetings, 0 never does
+		cancelAfter int
+		wantCount   int
+		wantErr     error
+	}{
+		{name: "all ten greetings", advance: true, wantCount: 10},
+		{name: "client cancels", cancelAfter: 1, wantCount: 1, wantErr: sdk.ErrCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(time.Now())
+			c := servertest.Greet(t, greetservice.WithClock(clk))
+			if tt.advance {
+				stop := drive(clk)
+				defer stop()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			got := []string{}
+			err := c.GreetManyTimes(ctx, &greetpb.Greeting{FirstName: "Angel"}, func(result string) error {
+				got = append(got, result)
+				if len(got) == tt.cancelAfter {
+					cancel()
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GreetManyTimes() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != tt.wantCount {
+				t.Fatalf("got %v greetings, want %v: %q", len(got), tt.wantCount, got)
+			}
+			if got[0] != "Hello Angel number 0" {
+				t.Errorf("first greeting = %q, want %q", got[0], "Hello Angel number 0")
+			}
+		})
+	}
+}
+
+func TestLongGreet(t *testing.T) {
+	c := servertest.Greet(t)
+	tests := []struct {
+		name      string
+		greetings []*greetpb.Greeting
+		want      string
+	}{
+		{"empty stream", nil, ""},
+		{"one", []*greetpb.Greeting{{FirstName: "Angel"}}, "Hello Angel! "},
+		{"several", []*greetpb.Greeting{{FirstName: "Angel"}, {FirstName: "John"}, {FirstName: "Mark"}}, "Hello Angel! Hello John! Hello Mark! "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.LongGreet(context.Background(), tt.greetings)
+			if err != nil {
+				t.Fatalf("LongGreet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("LongGreet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLongGreetCanceled(t *testing.T) {
+	c := servertest.Greet(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.LongGreet(ctx, []*greetpb.Greeting{{FirstName: "Angel"}})
+	if !errors.Is(err, sdk.ErrCanceled) {
+		t.Errorf("LongGreet() error = %v, want %v", err, sdk.ErrCanceled)
+	}
+}
+
+func TestGreetEveryone(t *testing.T) {
+	c := servertest.Greet(t)
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{"empty stream", nil, []string{}},
+		{"one", []string{"Angel"}, []string{"Hello Angel! "}},
+		{"several", []string{"Angel", "John", "Mark"}, []string{"Hello Angel! ", "Hello John! ", "Hello Mark! "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := c.GreetEveryone(context.Background())
+			if err != nil {
+				t.Fatalf("GreetEveryone() error = %v", err)
+			}
+			// answers come one per request, so they are read before the next is sent
+			got := []string{}
+			for _, name := range tt.names {
+				if err := stream.Send(&greetpb.Greeting{FirstName: name}); err != nil {
+					t.Fatalf("Send() error = %v", err)
+				}
+				res, err := stream.Recv()
+				if err != nil {
+					t.Fatalf("Recv() error = %v", err)
+				}
+				got = append(got, res)
+			}
+			if err := stream.CloseSend(); err != nil {
+				t.Fatalf("CloseSend() error = %v", err)
+			}
+			if _, err := stream.Recv(); err != io.EOF {
+				t.Fatalf("Recv() after CloseSend error = %v, want io.EOF", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GreetEveryone() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGreetEveryoneCanceled(t *testing.T) {
+	c := servertest.Greet(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.GreetEveryone(ctx)
+	if err != nil {
+		t.Fatalf("GreetEveryone() error = %v", err)
+	}
+	if err := stream.Send(&greetpb.Greeting{FirstName: "Angel"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	cancel()
+	if _, err := stream.Recv(); !errors.Is(err, sdk.ErrCanceled) {
+		t.Errorf("Recv() after cancel error = %v, want %v", err, sdk.ErrCanceled)
+	}
+}
+
+func TestGreetWithDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline time.Duration
+		// advance is whether the fake clock moves, without it the server never answers
+		advance bool
+		// cancel cancels the call once the server waits
+		cancel  bool
+		want    string
+		wantErr error
+	}{
+		{name: "answers after three seconds", deadline: 10 * time.Second, advance: true, want: "Hello Angel, Dionisio"},
+		{name: "deadline exceeded", deadline: 50 * time.Millisecond, wantErr: sdk.ErrDeadlineExceeded},
+		{name: "client cancels", deadline: 10 * time.Second, cancel: true, wantErr: sdk.ErrCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(time.Now())
+			c := servertest.Greet(t, greetservice.WithClock(clk))
+			if tt.advance {
+				stop := drive(clk)
+				defer stop()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+			if tt.cancel {
+				go func() {
+					if clk.BlockUntil(1, 5*time.Second) {
+						cancel()
+					}
+				}()
+			}
+
+			start := time.Now()
+			got, err := c.GreetWithDeadline(ctx, &greetpb.Greeting{FirstName: "Angel", LastName: "Dionisio"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GreetWithDeadline() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GreetWithDeadline() = %q, want %q", got, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("GreetWithDeadline() took %v of real time, the fake clock should pace it", elapsed)
+			}
+		})
+	}
+}
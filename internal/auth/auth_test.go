@@ -0,0 +1,181 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/auth/authtest"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newAuthenticator accepts the issuer's tokens and the API key "ci-key" for the subject ci
+func newAuthenticator(t *testing.T, issuer *authtest.Issuer) *auth.Authenticator {
+	t.Helper()
+	dir := t.TempDir()
+	secretFile, err := issuer.WriteFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keysFile := filepath.Join(dir, "api-keys.json")
+	if err := os.WriteFile(keysFile, []byte(`{"ci-key": {"subject": "ci", "roles": ["admin"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := auth.NewAuthenticator(auth.Config{HMACSecretFile: secretFile, APIKeysFile: keysFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func incoming(kv ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+}
+
+func TestAuthenticate(t *testing.T) {
+	issuer := authtest.NewIssuer()
+	a := newAuthenticator(t, issuer)
+	hour := time.Now().Add(time.Hour).Unix()
+
+	hs384, err := jwt.NewWithClaims(jwt.SigningMethodHS384, jwt.MapClaims{"sub": "angel", "exp": hour}).SignedString(issuer.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "angel", "exp": hour}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey := (&authtest.Issuer{Secret: []byte("another secret")}).Token("angel")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		want     *auth.Principal
+		wantCode codes.Code
+	}{
+		{"valid token", incoming("authorization", "Bearer "+issuer.Token("angel", "admin")), &auth.Principal{Subject: "angel", Roles: []string{"admin"}}, codes.OK},
+		{"expired token", incoming("authorization", "Bearer "+issuer.Sign(jwt.MapClaims{"sub": "angel", "exp": time.Now().Add(-time.Minute).Unix()})), nil, codes.Unauthenticated},
+		{"no exp", incoming("authorization", "Bearer "+issuer.Sign(jwt.MapClaims{"sub": "angel"})), nil, codes.Unauthenticated},
+		{"no sub", incoming("authorization", "Bearer "+issuer.Sign(jwt.MapClaims{"exp": hour})), nil, codes.Unauthenticated},
+		{"wrong algorithm", incoming("authorization", "Bearer "+hs384), nil, codes.Unauthenticated},
+		{"alg none", incoming("authorization", "Bearer "+none), nil, codes.Unauthenticated},
+		{"wrong key", incoming("authorization", "Bearer "+otherKey), nil, codes.Unauthenticated},
+		{"not a bearer token", incoming("authorization", "Basic YW5nZWw6c2VjcmV0"), nil, codes.Unauthenticated},
+		{"known API key", incoming("x-api-key", "ci-key"), &auth.Principal{Subject: "ci", Roles: []string{"admin"}}, codes.OK},
+		{"unknown API key", incoming("x-api-key", "guessed-key"), nil, codes.Unauthenticated},
+		{"no credentials", incoming(), nil, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Authenticate(tt.ctx)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantCode)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && (got.Subject != tt.want.Subject || !slices.Equal(got.Roles, tt.want.Roles)) {
+				t.Errorf("Authenticate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterceptorPolicy(t *testing.T) {
+	issuer := authtest.NewIssuer()
+	a := newAuthenticator(t, issuer)
+	policy := auth.Merge(auth.DefaultPolicy, auth.Policy{
+		"/blog.BlogService/ApproveContent": {Roles: []string{"moderator", "admin"}},
+		"/greet.GreetService/*":            {Public: true},
+	})
+	interceptor := auth.UnaryServerInterceptor(a, policy)
+
+	tests := []struct {
+		name        string
+		method      string
+		ctx         context.Context
+		wantCode    codes.Code
+		wantSubject string
+	}{
+		{"role allowed", "/blog.BlogService/ApproveContent", incoming("authorization", "Bearer "+issuer.Token("mod", "moderator")), codes.OK, "mod"},
+		{"role missing", "/blog.BlogService/ApproveContent", incoming("authorization", "Bearer "+issuer.Token("angel", "writer")), codes.PermissionDenied, ""},
+		{"any caller", "/blog.BlogService/CreateBlog", incoming("authorization", "Bearer "+issuer.Token("angel")), codes.OK, "angel"},
+		{"no credentials", "/blog.BlogService/CreateBlog", incoming(), codes.Unauthenticated, ""},
+		{"public service", "/greet.GreetService/Greet", incoming(), codes.OK, ""},
+		{"health is public", "/grpc.health.v1.Health/Check", incoming(), codes.OK, ""},
+		{"invalid credentials on a public method", "/greet.GreetService/Greet", incoming("x-api-key", "guessed-key"), codes.Unauthenticated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				p, ok := auth.FromContext(ctx)
+				if ok != (tt.wantSubject != "") || ok && p.Subject != tt.wantSubject {
+					t.Errorf("principal = %+v, want subject %q", p, tt.wantSubject)
+				}
+				return nil, nil
+			}
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("error = %v, want %v", err, tt.wantCode)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCode == codes.OK)
+			}
+		})
+	}
+}
+
+func TestRequireOwnerOrRole(t *testing.T) {
+	// anonymous is the context of a caller without credentials on a public method
+	var anonymous context.Context
+	interceptor := auth.UnaryServerInterceptor(newAuthenticator(t, authtest.NewIssuer()), auth.Policy{"/greet.GreetService/*": {Public: true}})
+	_, err := interceptor(incoming(), nil, &grpc.UnaryServerInfo{FullMethod: "/greet.GreetService/Greet"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		anonymous = ctx
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{"owner", auth.NewContext(context.Background(), &auth.Principal{Subject: "angel"}), codes.OK},
+		{"admin", auth.NewContext(context.Background(), &auth.Principal{Subject: "root", Roles: []string{"admin"}}), codes.OK},
+		{"someone else", auth.NewContext(context.Background(), &auth.Principal{Subject: "mallory", Roles: []string{"moderator"}}), codes.PermissionDenied},
+		{"anonymous caller", anonymous, codes.Unauthenticated},
+		{"auth off", context.Background(), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := auth.RequireOwnerOrRole(tt.ctx, "angel", "admin"); status.Code(err) != tt.wantCode {
+				t.Errorf("RequireOwnerOrRole() error = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	creds := auth.ClientCredentials{Token: "token", APIKey: "key"}
+	if !creds.RequireTransportSecurity() {
+		t.Error("RequireTransportSecurity() = false, want true unless Insecure is set")
+	}
+	creds.Insecure = true
+	if creds.RequireTransportSecurity() {
+		t.Error("RequireTransportSecurity() with Insecure = true, want false")
+	}
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil || md["authorization"] != "Bearer token" || md["x-api-key"] != "key" {
+		t.Errorf("GetRequestMetadata() = %v, %v", md, err)
+	}
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadata keys the credentials are read from
+const (
+	authorizationHeader = "authorization"
+	apiKeyHeader        = "x-api-key"
+)
+
+// Config says which credentials are accepted, auth is off when nothing is set
+type Config struct {
+	// HMACSecretFile holds the shared secret for HS256 tokens
+	HMACSecretFile string
+	// JWKSFile is a local JSON Web Key Set with the RSA keys for RS256 tokens
+	JWKSFile string
+	// APIKeysFile is a JSON object mapping each API key to its principal,
+	// e.g. {"secret-key": {"subject": "ci", "roles": ["admin"]}}
+	APIKeysFile string
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string
+	Audience string
+}
+
+// RegisterFlags adds the auth.* flags to fs
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.HMACSecretFile, "auth.hmac-secret-file", "", "file with the HS256 secret, enables JWT auth")
+	fs.StringVar(&c.JWKSFile, "auth.jwks-file", "", "JWKS file with RS256 keys, enables JWT auth")
+	fs.StringVar(&c.APIKeysFile, "auth.api-keys-file", "", "JSON file mapping API keys to principals, enables API key auth")
+	fs.StringVar(&c.Issuer, "auth.issuer", "", "required JWT issuer")
+	fs.StringVar(&c.Audience, "auth.audience", "", "required JWT audience")
+}
+
+// Enabled reports whether callers have to authenticate
+func (c Config) Enabled() bool {
+	return c.HMACSecretFile != "" || c.JWKSFile != "" || c.APIKeysFile != ""
+}
+
+// Authenticator turns the credentials in the incoming metadata into a Principal
+type Authenticator struct {
+	hmacSecret []byte
+	rsaKeys    map[string]*rsa.PublicKey
+	apiKeys    map[string]*Principal
+	parser     *jwt.Parser
+}
+
+// NewAuthenticator loads the secrets, keys and API keys named in the config
+func NewAuthenticator(c Config) (*Authenticator, error) {
+	a := &Authenticator{}
+
+	if c.HMACSecretFile != "" {
+		secret, err := os.ReadFile(c.HMACSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read HMAC secret: %v", err)
+		}
+		a.hmacSecret = []byte(strings.TrimSpace(string(secret)))
+		if len(a.hmacSecret) == 0 {
+			return nil, fmt.Errorf("HMAC secret file %v is empty", c.HMACSecretFile)
+		}
+	}
+	if c.JWKSFile != "" {
+		keys, err := loadJWKS(c.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.rsaKeys = keys
+	}
+	if c.APIKeysFile != "" {
+		data, err := os.ReadFile(c.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read API keys: %v", err)
+		}
+		if err := json.Unmarshal(data, &a.apiKeys); err != nil {
+			return nil, fmt.Errorf("cannot parse API keys file %v: %v", c.APIKeysFile, err)
+		}
+	}
+
+	// tokens without an exp claim would never expire
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{"HS256", "RS256"}), jwt.WithExpirationRequired()}
+	if c.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(c.Issuer))
+	}
+	if c.Audience != "" {
+		opts = append(opts, jwt.WithAudience(c.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+
+	return a, nil
+}
+
+// Authenticate returns the caller in ctx, or nil when no credentials were sent.
+// Credentials that were sent but are invalid give an Unauthenticated error
+func (a *Authenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if keys := md.Get(apiKeyHeader); len(keys) > 0 {
+		p, ok := a.apiKeys[keys[0]]
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		return p, nil
+	}
+
+	if values := md.Get(authorizationHeader); len(values) > 0 {
+		token := strings.TrimPrefix(values[0], "Bearer ")
+		if token == values[0] {
+			return nil, status.Error(codes.Unauthenticated, "authorization must be a Bearer token")
+		}
+		return a.parseToken(token)
+	}
+
+	return nil, nil
+}
+
+// claims are the JWT claims we read, roles is a custom claim
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+func (a *Authenticator) parseToken(token string) (*Principal, error) {
+	c := &claims{}
+	if _, err := a.parser.ParseWithClaims(token, c, a.key); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	if c.Subject == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid token: missing sub claim")
+	}
+	return &Principal{Subject: c.Subject, Roles: c.Roles}, nil
+}
+
+// key picks the verification key for a token based on its alg and kid headers
+func (a *Authenticator) key(t *jwt.Token) (interface{}, error) {
+	switch t.Method.Alg() {
+	case "HS256":
+		if a.hmacSecret == nil {
+			return nil, fmt.Errorf("HS256 tokens are not accepted")
+		}
+		return a.hmacSecret, nil
+	case "RS256":
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := a.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		// a set with a single key doesn't need the kid header
+		if kid == "" && len(a.rsaKeys) == 1 {
+			for _, key := range a.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", t.Method.Alg())
+}
+
+// jwks is the JSON Web Key Set format, only RSA keys are used
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func loadJWKS(file string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read JWKS: %v", err)
+	}
+	set := jwks{}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("cannot parse JWKS file %v: %v", file, err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: invalid n: %v", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: invalid e: %v", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no RSA keys found in %v", file)
+	}
+	return keys, nil
+}
@@ -0,0 +1,57 @@
+// Package authtest signs throwaway HS256 tokens so auth can be tried out and tested
+// offline, nothing it creates should be used in production
+package authtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer signs tokens with a random HS256 secret
+type Issuer struct {
+	Secret []byte
+}
+
+// NewIssuer returns an issuer with a new secret
+func NewIssuer() *Issuer {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	// the secret is hex so it survives the trimming of the secret file
+	return &Issuer{Secret: []byte(hex.EncodeToString(secret))}
+}
+
+// Token returns a token for subject with roles, valid for an hour
+func (i *Issuer) Token(subject string, roles ...string) string {
+	return i.Sign(jwt.MapClaims{
+		"sub":   subject,
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// Sign returns a token with the claims as they are, e.g. to leave out exp
+func (i *Issuer) Sign(claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.Secret)
+	if err != nil {
+		// signing only fails for keys of the wrong type
+		panic(err)
+	}
+	return token
+}
+
+// WriteFile writes the secret to <dir>/hmac-secret, for auth.Config.HMACSecretFile,
+// and returns the path
+func (i *Issuer) WriteFile(dir string) (string, error) {
+	path := filepath.Join(dir, "hmac-secret")
+	if err := os.WriteFile(path, i.Secret, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor authenticates the caller, checks the policy and puts the principal in the context
+func UnaryServerInterceptor(a *Authenticator, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.check(ctx, info.FullMethod, policy)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs
+func StreamServerInterceptor(a *Authenticator, policy Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.check(ss.Context(), info.FullMethod, policy)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) check(ctx context.Context, fullMethod string, policy Policy) (context.Context, error) {
+	p, err := a.Authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Rule(fullMethod).authorize(fullMethod, p); err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, checkedKey{}, true)
+	if p != nil {
+		ctx = NewContext(ctx, p)
+	}
+	return ctx, nil
+}
+
+// serverStream swaps the context of a stream for one carrying the principal
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
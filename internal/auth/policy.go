@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rule says who may call a method
+type Rule struct {
+	// Public methods can be called without credentials
+	Public bool
+	// Roles, when not empty, limits the method to callers with one of these roles.
+	// Otherwise any authenticated caller is allowed
+	Roles []string
+}
+
+// Policy maps full method names ("/greet.GreetService/Greet") or whole services
+// ("/greet.GreetService/*") to their rule. Methods that are not listed
+// need an authenticated caller
+type Policy map[string]Rule
+
+// DefaultPolicy keeps the health and reflection services open,
+// probes and tools like grpcurl don't carry credentials
+var DefaultPolicy = Policy{
+	"/grpc.health.v1.Health/*":                    {Public: true},
+	"/grpc.reflection.v1.ServerReflection/*":      {Public: true},
+	"/grpc.reflection.v1alpha.ServerReflection/*": {Public: true},
+}
+
+// Merge returns a new policy with the rules of all the given policies,
+// later policies win when a method is listed twice
+func Merge(policies ...Policy) Policy {
+	merged := Policy{}
+	for _, p := range policies {
+		for method, rule := range p {
+			merged[method] = rule
+		}
+	}
+	return merged
+}
+
+// Rule returns the rule for a full method name
+func (p Policy) Rule(fullMethod string) Rule {
+	if r, ok := p[fullMethod]; ok {
+		return r
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		if r, ok := p[fullMethod[:i+1]+"*"]; ok {
+			return r
+		}
+	}
+	return Rule{}
+}
+
+// authorize checks the principal, which is nil for anonymous callers, against the rule
+func (r Rule) authorize(fullMethod string, p *Principal) error {
+	if r.Public {
+		return nil
+	}
+	if p == nil {
+		return status.Errorf(codes.Unauthenticated, "%v needs credentials", fullMethod)
+	}
+	if len(r.Roles) > 0 && !p.HasRole(r.Roles...) {
+		return status.Errorf(codes.PermissionDenied, "%v needs one of the roles %v", fullMethod, strings.Join(r.Roles, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package auth authenticates callers from gRPC metadata, with a JWT in the
+// authorization header or an API key in x-api-key, and authorizes each RPC
+// against a per-method policy table
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Principal is the authenticated caller of an RPC
+type Principal struct {
+	// Subject identifies the caller, it is the JWT sub claim or the name given to an API key
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+}
+
+// HasRole reports whether the principal has any of the roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, have := range p.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// checkedKey marks the calls that went through the auth interceptors
+type checkedKey struct{}
+
+// NewContext returns a context carrying the principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal the auth interceptors put in the context
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// RequireOwnerOrRole is for handlers whose rule depends on the data being touched,
+// e.g. deleting a blog is allowed for its author or an admin. It returns a
+// PermissionDenied error unless the caller is ownerID or has one of the roles,
+// and an Unauthenticated error for anonymous callers of public methods.
+// With auth disabled there is nothing to check, like for the policy table
+func RequireOwnerOrRole(ctx context.Context, ownerID string, roles ...string) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		if ctx.Value(checkedKey{}) == nil {
+			return nil
+		}
+		return status.Error(codes.Unauthenticated, "this call needs credentials")
+	}
+	if p.Subject == ownerID || p.HasRole(roles...) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%v is not allowed to modify a resource owned by %v", p.Subject, ownerID)
+}
@@ -0,0 +1,106 @@
+package bootstrap
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/config"
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"github.com/angel/golang_api_microservice/internal/ratelimit"
+	"github.com/angel/golang_api_microservice/internal/recording"
+	"github.com/angel/golang_api_microservice/internal/tlsutil"
+	"github.com/angel/golang_api_microservice/internal/tracing"
+)
+
+// DefaultDrainTimeout is how long in-flight RPCs get to finish on shutdown
+// before the server is stopped forcefully
+const DefaultDrainTimeout = 10 * time.Second
+
+// Config holds what every server needs to start
+type Config struct {
+	// Name of the service, used in logs and as the env var prefix
+	Name string
+	// Addr is the address the gRPC server listens on, e.g. "0.0.0.0:50051"
+	Addr string
+	// MetricsAddr is the address of the HTTP server for /metrics, empty turns it off
+	MetricsAddr string
+	// DrainTimeout is how long GracefulStop waits before forcing a Stop
+	DrainTimeout time.Duration
+	// TLS turns on TLS, and optionally mutual TLS, when a certificate is set
+	TLS tlsutil.ServerConfig
+	// Auth turns on authentication when a secret, key set or API key file is set
+	Auth auth.Config
+	// RateLimit limits calls and stream messages per client when a rate is set
+	RateLimit ratelimit.Config
+	// Tracing selects where spans are exported
+	Tracing tracing.Config
+	// Log sets the log level and format
+	Log logging.Config
+	// Record captures a sample of the calls to a file when one is set
+	Record recording.Config
+	// DumpDescriptors, when set, makes Run write the FileDescriptorSet of the
+	// registered services to this file ("-" for stdout) and return instead of serving
+	DumpDescriptors string
+}
+
+// LoadConfig returns the config for the named service from the command line flags.
+// Each setting can also come from a config file or the environment, see package config,
+// e.g. --addr, addr in the file or GREET_ADDR for the greet service.
+// Services with extra settings register their flags on flag.CommandLine before calling LoadConfig.
+// With --print-config the resolved config is printed and the program exits
+func LoadConfig(name, defaultAddr, defaultMetricsAddr string) (Config, error) {
+	cfg := Config{Name: name}
+	flag.StringVar(&cfg.Addr, "addr", defaultAddr, "address the gRPC server listens on")
+	flag.StringVar(&cfg.MetricsAddr, "metrics.addr", defaultMetricsAddr, "address of the HTTP server for /metrics, empty turns it off")
+	flag.DurationVar(&cfg.DrainTimeout, "drain-timeout", DefaultDrainTimeout, "how long to wait for in-flight RPCs on shutdown")
+	flag.StringVar(&cfg.DumpDescriptors, "dump-descriptors", "", "write the FileDescriptorSet of the services to this file (- for stdout) and exit")
+	cfg.TLS.RegisterFlags(flag.CommandLine)
+	cfg.Auth.RegisterFlags(flag.CommandLine)
+	cfg.RateLimit.RegisterFlags(flag.CommandLine)
+	cfg.Tracing.RegisterFlags(flag.CommandLine)
+	cfg.Log.RegisterFlags(flag.CommandLine)
+	cfg.Record.RegisterFlags(flag.CommandLine)
+
+	if err := config.Load(flag.CommandLine, name, os.Args[1:]); err != nil {
+		if err == config.ErrPrintConfig {
+			os.Exit(0)
+		}
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the config is usable
+func (c Config) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return fmt.Errorf("invalid addr %q: %v", c.Addr, err)
+	}
+	if c.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+			return fmt.Errorf("invalid metrics.addr %q: %v", c.MetricsAddr, err)
+		}
+	}
+	if c.DrainTimeout <= 0 {
+		return fmt.Errorf("drain-timeout must be positive, got %v", c.DrainTimeout)
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+	if err := c.Log.Validate(); err != nil {
+		return err
+	}
+	if c.Record.Enabled() {
+		if err := c.Record.Validate(); err != nil {
+			return err
+		}
+	}
+	return c.TLS.Validate()
+}
@@ -0,0 +1,73 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DescriptorSet returns the FileDescriptorSet of every service registered on the server,
+// with all the files they import, in the format grpcurl -protoset expects
+func (s *Server) DescriptorSet() (*descriptorpb.FileDescriptorSet, error) {
+	names := make([]string, 0)
+	for name := range s.GRPC.GetServiceInfo() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		// dependencies go first so the set can be loaded in order
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+
+	for _, name := range names {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("cannot find descriptor for %v: %v", name, err)
+		}
+		add(d.ParentFile())
+	}
+	return set, nil
+}
+
+// DumpDescriptors writes the binary FileDescriptorSet to path, "-" writes to stdout
+func (s *Server) DumpDescriptors(path string) error {
+	set, err := s.DescriptorSet()
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,95 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheck is a dependency check that decides the serving status of a service
+type healthCheck struct {
+	service  string
+	interval time.Duration
+	check    func(ctx context.Context) error
+}
+
+// healthState tracks which services are failing their checks,
+// the overall "" status is NOT_SERVING while any of them is
+type healthState struct {
+	mu      sync.Mutex
+	failing map[string]bool
+}
+
+// AddHealthCheck runs check every interval while the server is running and reports
+// service as NOT_SERVING while it fails, e.g. the blog service pinging MongoDB.
+// Services without a check are SERVING for as long as the server runs
+func (s *Server) AddHealthCheck(service string, interval time.Duration, check func(ctx context.Context) error) {
+	s.healthChecks = append(s.healthChecks, healthCheck{
+		service:  service,
+		interval: interval,
+		check:    check,
+	})
+}
+
+// startHealth marks every registered service as SERVING and starts the health checks,
+// they stop when ctx is done
+func (s *Server) startHealth(ctx context.Context) {
+	for name := range s.GRPC.GetServiceInfo() {
+		s.Health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	state := &healthState{failing: map[string]bool{}}
+	for _, hc := range s.healthChecks {
+		go s.runHealthCheck(ctx, hc, state)
+	}
+}
+
+func (s *Server) runHealthCheck(ctx context.Context, hc healthCheck, state *healthState) {
+	t := time.NewTicker(hc.interval)
+	defer t.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, hc.interval)
+		err := hc.check(checkCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		s.setHealth(hc.service, err, state)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Server) setHealth(service string, err error, state *healthState) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	wasFailing := state.failing[service]
+	if err != nil {
+		if !wasFailing {
+			slog.Warn("health check failed", "health_service", service, "error", err)
+		}
+		state.failing[service] = true
+		s.Health.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	} else {
+		if wasFailing {
+			slog.Info("health check recovered", "health_service", service)
+		}
+		delete(state.failing, service)
+		s.Health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	if len(state.failing) > 0 {
+		overall = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	s.Health.SetServingStatus("", overall)
+}
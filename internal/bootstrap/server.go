@@ -0,0 +1,320 @@
+// Package bootstrap holds the boilerplate shared by the greet, calculator and blog servers:
+// listening, interceptor chaining, health, reflection, signal handling and graceful shutdown.
+// Each main only has to register its service and call Run
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"github.com/angel/golang_api_microservice/internal/metrics"
+	"github.com/angel/golang_api_microservice/internal/ratelimit"
+	"github.com/angel/golang_api_microservice/internal/recording"
+	"github.com/angel/golang_api_microservice/internal/recovery"
+	"github.com/angel/golang_api_microservice/internal/shutdown"
+	"github.com/angel/golang_api_microservice/internal/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server wraps a grpc.Server with the health service, reflection and shutdown handling
+type Server struct {
+	Config Config
+	// GRPC is the server services get registered on
+	GRPC *grpc.Server
+	// Health is the grpc.health.v1.Health service registered on GRPC
+	Health *health.Server
+	// Metrics records the RPC metrics served on /metrics,
+	// services can register their own collectors on Metrics.Registry
+	Metrics *metrics.Metrics
+
+	onShutdown   []func(ctx context.Context)
+	healthChecks []healthCheck
+	draining     *shutdown.Signal
+}
+
+// streamBatchSize is how many stream messages go in one tracing span
+const streamBatchSize = 100
+
+// Option configures a Server
+type Option func(*options)
+
+type options struct {
+	unary         []grpc.UnaryServerInterceptor
+	stream        []grpc.StreamServerInterceptor
+	serverOptions []grpc.ServerOption
+	authPolicy    auth.Policy
+}
+
+// WithUnaryInterceptors adds unary interceptors, they run in the order given
+func WithUnaryInterceptors(i ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.unary = append(o.unary, i...)
+	}
+}
+
+// WithStreamInterceptors adds stream interceptors, they run in the order given
+func WithStreamInterceptors(i ...grpc.StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.stream = append(o.stream, i...)
+	}
+}
+
+// WithServerOptions passes extra options to grpc.NewServer
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *options) {
+		o.serverOptions = append(o.serverOptions, opts...)
+	}
+}
+
+// WithAuthPolicy adds per-method authorization rules, they are only
+// enforced when auth is enabled in the config
+func WithAuthPolicy(p auth.Policy) Option {
+	return func(o *options) {
+		o.authPolicy = auth.Merge(o.authPolicy, p)
+	}
+}
+
+// New creates a Server with health and reflection already registered,
+// serving TLS when the config has a certificate
+func New(cfg Config, opts ...Option) (*Server, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if cfg.Log.Level != "" {
+		if err := logging.Setup(cfg.Log, cfg.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	// request IDs, access logs and metrics come first so they see every call,
+	// including the ones rejected below
+	m := metrics.New()
+	draining := shutdown.NewSignal()
+	unary := []grpc.UnaryServerInterceptor{
+		logging.UnaryServerInterceptor(),
+		m.UnaryServerInterceptor(),
+		draining.UnaryServerInterceptor(),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		logging.StreamServerInterceptor(),
+		m.StreamServerInterceptor(),
+		draining.StreamServerInterceptor(),
+	}
+	// recording comes right after them, so it has the request ID and also captures
+	// the calls rejected by rate limiting or auth
+	var recorder *recording.Recorder
+	if cfg.Record.Enabled() {
+		r, err := recording.New(cfg.Record)
+		if err != nil {
+			return nil, err
+		}
+		recorder = r
+		unary = append(unary, recorder.UnaryServerInterceptor())
+		stream = append(stream, recorder.StreamServerInterceptor())
+	}
+	o.unary = append(unary, o.unary...)
+	o.stream = append(stream, o.stream...)
+
+	// the RPC spans come from a stats handler, which runs before any interceptor
+	stopTracing, err := tracing.Setup(context.Background(), cfg.Name, cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+	o.stream = append(o.stream, tracing.StreamBatchInterceptor(streamBatchSize))
+	o.serverOptions = append(o.serverOptions, tracing.ServerOption())
+
+	// auth and rate limiting run after the interceptors passed in, so those also see rejected calls.
+	// Rate limiting comes after auth so it can count calls per verified principal,
+	// unverified credentials would let a client pick a new bucket for every call.
+	// Calls auth rejects are limited per peer before auth instead, or a flood of bad
+	// credentials would never reach a bucket
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled() {
+		limiter = ratelimit.New(cfg.RateLimit)
+	}
+	if cfg.Auth.Enabled() {
+		a, err := auth.NewAuthenticator(cfg.Auth)
+		if err != nil {
+			return nil, err
+		}
+		if limiter != nil {
+			o.unary = append(o.unary, limiter.RejectedUnaryServerInterceptor())
+			o.stream = append(o.stream, limiter.RejectedStreamServerInterceptor())
+		}
+		policy := auth.Merge(auth.DefaultPolicy, o.authPolicy)
+		o.unary = append(o.unary, auth.UnaryServerInterceptor(a, policy))
+		o.stream = append(o.stream, auth.StreamServerInterceptor(a, policy))
+	}
+	if limiter != nil {
+		o.unary = append(o.unary, limiter.UnaryServerInterceptor())
+		o.stream = append(o.stream, limiter.StreamServerInterceptor())
+	}
+	// panics are recovered right around the handler, so every interceptor above
+	// sees them as Internal errors and still records the call
+	o.unary = append(o.unary, recovery.UnaryServerInterceptor())
+	o.stream = append(o.stream, recovery.StreamServerInterceptor())
+
+	serverOptions := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(o.unary...),
+		grpc.ChainStreamInterceptor(o.stream...),
+	}, o.serverOptions...)
+
+	if cfg.TLS.Enabled() {
+		creds, err := cfg.TLS.ServerOption()
+		if err != nil {
+			return nil, err
+		}
+		serverOptions = append(serverOptions, creds)
+	}
+
+	s := &Server{
+		Config:   cfg,
+		GRPC:     grpc.NewServer(serverOptions...),
+		Health:   health.NewServer(),
+		Metrics:  m,
+		draining: draining,
+	}
+	healthpb.RegisterHealthServer(s.GRPC, s.Health)
+	reflection.Register(s.GRPC)
+
+	s.OnShutdown(func(ctx context.Context) {
+		if err := stopTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	})
+	if recorder != nil {
+		s.OnShutdown(func(context.Context) {
+			if err := recorder.Close(); err != nil {
+				slog.Error("failed to close the recording", "error", err)
+			}
+		})
+	}
+
+	return s, nil
+}
+
+// OnShutdown registers a function that is called after the gRPC server has stopped,
+// e.g. to close a database connection. Functions run in the order they were added
+func (s *Server) OnShutdown(fn func(ctx context.Context)) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Run listens on the configured address and serves until SIGINT or SIGTERM is received,
+// then shuts the server down gracefully. With DumpDescriptors set it only writes
+// the descriptors and returns
+func (s *Server) Run() error {
+	if s.Config.DumpDescriptors != "" {
+		return s.DumpDescriptors(s.Config.DumpDescriptors)
+	}
+
+	lis, err := net.Listen("tcp", s.Config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", s.Config.Addr, err)
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(ch)
+
+	return s.Serve(lis, ch)
+}
+
+// Serve serves on lis until a value is received on stop, then shuts down gracefully
+func (s *Server) Serve(lis net.Listener, stop <-chan os.Signal) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.startHealth(ctx)
+
+	if s.Config.MetricsAddr != "" {
+		metricsServer, err := s.serveMetrics()
+		if err != nil {
+			return err
+		}
+		s.OnShutdown(func(ctx context.Context) {
+			metricsServer.Shutdown(ctx)
+		})
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "addr", lis.Addr().String())
+		errCh <- s.GRPC.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case sig := <-stop:
+		slog.Info("stopping server", "signal", sig.String())
+	}
+
+	cancel()
+	s.Shutdown()
+	return nil
+}
+
+// serveMetrics starts the HTTP server for /metrics
+func (s *Server) serveMetrics() (*http.Server, error) {
+	lis, err := net.Listen("tcp", s.Config.MetricsAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %v: %v", s.Config.MetricsAddr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.Metrics.Handler())
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		slog.Info("metrics listening", "addr", lis.Addr().String())
+		if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server failed", "error", err)
+		}
+	}()
+	return srv, nil
+}
+
+// Shutdown marks the server as not serving, stops accepting new connections,
+// tells long running handlers to wrap up (see shutdown.Draining), waits up to
+// DrainTimeout for in-flight RPCs to finish and then runs the shutdown hooks
+func (s *Server) Shutdown() {
+	s.Health.Shutdown()
+	s.draining.Trigger()
+
+	done := make(chan struct{})
+	go func() {
+		s.GRPC.GracefulStop()
+		close(done)
+	}()
+
+	timeout := s.Config.DrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("drain timeout reached, forcing stop", "timeout", timeout.String())
+		s.GRPC.Stop()
+		<-done
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, fn := range s.onShutdown {
+		fn(ctx)
+	}
+}
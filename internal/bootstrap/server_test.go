@@ -0,0 +1,130 @@
+package bootstrap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/auth/authtest"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/ratelimit"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// panicking is a calculator whose Sum and PrimeNumberDecomposition panic
+type panicking struct {
+	calculatorpb.UnimplementedCalculatorServiceServer
+}
+
+func (panicking) Sum(context.Context, *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	panic("sum exploded")
+}
+
+func (panicking) PrimeNumberDecomposition(*calculatorpb.PrimeNumberDecompositionRequest, grpc.ServerStreamingServer[calculatorpb.PrimeNumberDecompositionResponse]) error {
+	panic("decomposition exploded")
+}
+
+// metricValue returns the value of the gauge or counter name with labels, 0 when it was never set
+func metricValue(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+					continue metrics
+				}
+			}
+			if m.GetGauge() != nil {
+				return m.GetGauge().GetValue()
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestRecoverPanics(t *testing.T) {
+	s := servertest.Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, panicking{})
+	})
+	c := calculatorpb.NewCalculatorServiceClient(s.Dial(t, []string{calcclient.ServiceName}))
+	ctx := context.Background()
+
+	_, err := c.Sum(ctx, &calculatorpb.SumRequest{FirstNumber: 1, SecondNumber: 2})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Sum() error = %v, want Internal", err)
+	}
+	stream, err := c.PrimeNumberDecomposition(ctx, &calculatorpb.PrimeNumberDecompositionRequest{Number: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+		t.Errorf("PrimeNumberDecomposition() error = %v, want Internal", err)
+	}
+
+	// the server is still up and the metrics recorded both calls as finished
+	if _, err := c.Sum(ctx, &calculatorpb.SumRequest{}); status.Code(err) != codes.Internal {
+		t.Errorf("second Sum() error = %v, want Internal", err)
+	}
+	for method, calls := range map[string]float64{"Sum": 2, "PrimeNumberDecomposition": 1} {
+		labels := map[string]string{"grpc_method": method}
+		if got := metricValue(t, s.Metrics.Registry, "grpc_server_in_flight", labels); got != 0 {
+			t.Errorf("%v in flight = %v, want 0", method, got)
+		}
+		labels["grpc_code"] = codes.Internal.String()
+		if got := metricValue(t, s.Metrics.Registry, "grpc_server_handled_total", labels); got != calls {
+			t.Errorf("%v handled with Internal = %v, want %v", method, got, calls)
+		}
+	}
+}
+
+func TestRateLimitBadCredentials(t *testing.T) {
+	issuer := authtest.NewIssuer()
+	secretFile, err := issuer.WriteFile(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := bootstrap.Config{
+		Auth: auth.Config{HMACSecretFile: secretFile},
+		// two calls per hour
+		RateLimit: ratelimit.Config{Calls: ratelimit.Limit{Rate: 2.0 / 3600, Burst: 2}},
+	}
+	s := servertest.Start(t, cfg, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+	})
+	c := calculatorpb.NewCalculatorServiceClient(s.Dial(t, []string{calcclient.ServiceName}))
+
+	tests := []struct {
+		name  string
+		token string
+		want  codes.Code
+	}{
+		{"valid credentials", issuer.Token("angel"), codes.OK},
+		{"bad credentials", "not-a-token", codes.Unauthenticated},
+		{"bad credentials again", "not-a-token", codes.Unauthenticated},
+		// the peer used up its rejected calls, auth no longer sees them
+		{"a flood of bad credentials", "not-a-token", codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tt.token)
+		if _, err := c.Sum(ctx, &calculatorpb.SumRequest{}); status.Code(err) != tt.want {
+			t.Errorf("%v: Sum() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package clock is the time source of code that waits, so tests can drive it with
+// the fake clock of package clocktest instead of sleeping
+package clock
+
+import "time"
+
+// Clock tells the time and waits
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel once d has passed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	yamlFile = `
+addr: 0.0.0.0:6000
+drain_timeout: 5s
+mongo:
+  uri: mongodb://file:27017
+cors:
+  allowed-origins:
+    - https://a.example.com
+    - https://b.example.com
+`
+	tomlFile = `
+addr = "0.0.0.0:7000"
+drain_timeout = "7s"
+
+[mongo]
+uri = "mongodb://toml:27017"
+`
+)
+
+// newFlagSet registers flags like the servers do, with their defaults
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	fs.String("addr", "0.0.0.0:50051", "")
+	fs.Duration("drain-timeout", 30*time.Second, "")
+	fs.String("mongo.uri", "mongodb://localhost:27017", "")
+	fs.String("cors.allowed-origins", "", "")
+	fs.Bool("tls.enabled", false, "")
+	return fs
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		// files are written to a temporary directory, {dir} in args and env values is that directory
+		files map[string]string
+		env   map[string]string
+		args  []string
+		want  map[string]string
+		// wantErr is part of the expected error, empty when Load must succeed
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"addr": "0.0.0.0:50051", "drain-timeout": "30s", "mongo.uri": "mongodb://localhost:27017", "tls.enabled": "false"},
+		},
+		{
+			name:  "YAML file",
+			files: map[string]string{"blog.yaml": yamlFile},
+			args:  []string{"--config", "{dir}/blog.yaml"},
+			want: map[string]string{
+				"addr":                 "0.0.0.0:6000",
+				"drain-timeout":        "5s",
+				"mongo.uri":            "mongodb://file:27017",
+				"cors.allowed-origins": "https://a.example.com,https://b.example.com",
+				"tls.enabled":          "false",
+			},
+		},
+		{
+			name:  "TOML file",
+			files: map[string]string{"blog.toml": tomlFile},
+			args:  []string{"--config", "{dir}/blog.toml"},
+			want:  map[string]string{"addr": "0.0.0.0:7000", "drain-timeout": "7s", "mongo.uri": "mongodb://toml:27017"},
+		},
+		{
+			name:  "env over file",
+			files: map[string]string{"blog.yaml": yamlFile},
+			env:   map[string]string{"TEST_CONFIG": "{dir}/blog.yaml", "TEST_ADDR": "0.0.0.0:8000", "TEST_TLS_ENABLED": "true"},
+			want:  map[string]string{"addr": "0.0.0.0:8000", "drain-timeout": "5s", "mongo.uri": "mongodb://file:27017", "tls.enabled": "true"},
+		},
+		{
+			name:  "flags over env and file",
+			files: map[string]string{"blog.yaml": yamlFile},
+			env:   map[string]string{"TEST_ADDR": "0.0.0.0:8000", "TEST_DRAIN_TIMEOUT": "8s"},
+			args:  []string{"--config", "{dir}/blog.yaml", "--addr", "0.0.0.0:9000", "--mongo.uri", "mongodb://flag:27017"},
+			want:  map[string]string{"addr": "0.0.0.0:9000", "drain-timeout": "8s", "mongo.uri": "mongodb://flag:27017"},
+		},
+		{
+			name:  "--config wins over <PREFIX>_CONFIG",
+			files: map[string]string{"blog.yaml": yamlFile, "blog.toml": tomlFile},
+			env:   map[string]string{"TEST_CONFIG": "{dir}/blog.toml"},
+			args:  []string{"--config", "{dir}/blog.yaml"},
+			want:  map[string]string{"addr": "0.0.0.0:6000"},
+		},
+		{
+			name:    "unknown key",
+			files:   map[string]string{"blog.yaml": "mongo:\n  url: mongodb://typo:27017\n"},
+			args:    []string{"--config", "{dir}/blog.yaml"},
+			wantErr: `unknown setting "mongo.url"`,
+		},
+		{
+			name:    "invalid value in the file",
+			files:   map[string]string{"blog.toml": "drain_timeout = \"soon\"\n"},
+			args:    []string{"--config", "{dir}/blog.toml"},
+			wantErr: `invalid value "soon" for drain-timeout`,
+		},
+		{
+			name:    "invalid value in the environment",
+			env:     map[string]string{"TEST_TLS_ENABLED": "maybe"},
+			wantErr: `invalid value "maybe" for TEST_TLS_ENABLED`,
+		},
+		{
+			name:    "invalid YAML",
+			files:   map[string]string{"blog.yaml": "addr: [\n"},
+			args:    []string{"--config", "{dir}/blog.yaml"},
+			wantErr: "cannot parse config file",
+		},
+		{
+			name:    "unsupported extension",
+			files:   map[string]string{"blog.json": `{"addr": "0.0.0.0:6000"}`},
+			args:    []string{"--config", "{dir}/blog.json"},
+			wantErr: "unsupported extension",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"TEST_CONFIG": "{dir}/missing.yaml"},
+			wantErr: "cannot read config file",
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"--adr", "0.0.0.0:9000"},
+			wantErr: "flag provided but not defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			inDir := strings.NewReplacer("{dir}", dir)
+			args := make([]string, len(tt.args))
+			for i, arg := range tt.args {
+				args[i] = inDir.Replace(arg)
+			}
+			getenv := func(key string) string {
+				return inDir.Replace(tt.env[key])
+			}
+
+			fs := newFlagSet()
+			err := load(fs, "test", args, getenv, new(bytes.Buffer))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+			for name, want := range tt.want {
+				if got := fs.Lookup(name).Value.String(); got != want {
+					t.Errorf("%v = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadPrintConfig(t *testing.T) {
+	fs := newFlagSet()
+	var out bytes.Buffer
+	getenv := func(key string) string {
+		if key == "TEST_MONGO_URI" {
+			return "mongodb://angel:s3cret@db:27017"
+		}
+		return ""
+	}
+	err := load(fs, "test", []string{"--print-config", "--addr", "0.0.0.0:9000"}, getenv, &out)
+	if !errors.Is(err, ErrPrintConfig) {
+		t.Fatalf("load() error = %v, want ErrPrintConfig", err)
+	}
+	want := "addr: 0.0.0.0:9000\n" +
+		"cors.allowed-origins: \n" +
+		"drain-timeout: 30s\n" +
+		"mongo.uri: mongodb://angel:[REDACTED]@db:27017\n" +
+		"tls.enabled: false\n"
+	if out.String() != want {
+		t.Errorf("printed config = %q, want %q", out.String(), want)
+	}
+}
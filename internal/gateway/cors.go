@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exposedHeaders are the response headers browsers let scripts read, gRPC-Web
+// clients need grpc-status and grpc-message for trailers-only responses
+var exposedHeaders = []string{"grpc-status", "grpc-message", "x-request-id", "retry-after"}
+
+// CORSConfig selects which browser origins may call the gateway
+type CORSConfig struct {
+	// AllowedOrigins is a comma separated list of origins, * allows any.
+	// CORS is off when it is empty, only same origin pages can call the gateway
+	AllowedOrigins string
+	// AllowedHeaders is the comma separated list of request headers browsers may send
+	AllowedHeaders string
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// RegisterFlags adds the cors.allowed-origins, cors.allowed-headers and cors.max-age flags to fs
+func (c *CORSConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.AllowedOrigins, "cors.allowed-origins", "", "comma separated origins allowed to call the gateway from a browser, * for any, empty turns CORS off")
+	fs.StringVar(&c.AllowedHeaders, "cors.allowed-headers", "authorization,content-type,x-api-key,x-request-id,x-grpc-web,x-user-agent,grpc-timeout", "comma separated request headers browsers may send")
+	fs.DurationVar(&c.MaxAge, "cors.max-age", 10*time.Minute, "how long browsers may cache a preflight response")
+}
+
+// Validate checks the config is usable
+func (c CORSConfig) Validate() error {
+	for _, o := range splitList(c.AllowedOrigins) {
+		if o != "*" && !strings.HasPrefix(o, "http://") && !strings.HasPrefix(o, "https://") {
+			return fmt.Errorf("invalid cors.allowed-origins entry %q, must be * or start with http:// or https://", o)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cors.max-age must not be negative")
+	}
+	return nil
+}
+
+// Handler adds the CORS headers for allowed origins to the responses of next
+// and answers preflight requests itself
+func (c CORSConfig) Handler(next http.Handler) http.Handler {
+	if len(splitList(c.AllowedOrigins)) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !c.Allows(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(splitList(c.AllowedHeaders), ", "))
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allows reports whether pages on origin may call the gateway
+func (c CORSConfig) Allows(origin string) bool {
+	for _, o := range splitList(c.AllowedOrigins) {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin accepts WebSockets from non-browser clients, same origin pages and
+// the allowed origins, use it with WithCheckOrigin
+func (c CORSConfig) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return c.Allows(origin)
+}
+
+// splitList splits a comma separated flag value, dropping empty entries
+func splitList(s string) []string {
+	list := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
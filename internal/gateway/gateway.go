@@ -0,0 +1,330 @@
+// Package gateway serves the gRPC services as HTTP/JSON and gRPC-Web for clients that cannot speak gRPC.
+// Requests are transcoded with the descriptors registered by the generated protobuf
+// packages, so the protos need no HTTP annotations and no gateway code is generated.
+// Server streams are written as newline delimited JSON, or as server-sent events
+// when the client accepts text/event-stream
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxBodySize limits the size of a request body, streamed bodies included
+const maxBodySize = 4 << 20
+
+// forwardedHeaders are the HTTP request headers passed on to the services as metadata
+var forwardedHeaders = []string{"authorization", "x-api-key", logging.RequestIDKey}
+
+// returnedMetadata are the response metadata keys passed back to HTTP clients as headers
+var returnedMetadata = []string{logging.RequestIDKey, "retry-after"}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Gateway is an http.Handler that calls the gRPC services, from HTTP/JSON routes
+// or from gRPC-Web clients
+type Gateway struct {
+	mux         *http.ServeMux
+	routes      []*route
+	conns       map[string]grpc.ClientConnInterface
+	checkOrigin func(r *http.Request) bool
+}
+
+// Option configures a Gateway
+type Option func(*Gateway)
+
+// WithCheckOrigin decides which browser origins may open WebSockets, by default only
+// pages served from the gateway's own host can
+func WithCheckOrigin(fn func(r *http.Request) bool) Option {
+	return func(g *Gateway) {
+		g.checkOrigin = fn
+	}
+}
+
+// route is a Route resolved against the descriptors and the connection to its service
+type route struct {
+	Route
+	method     protoreflect.MethodDescriptor
+	pathParams []pathParam
+	conn       grpc.ClientConnInterface
+}
+
+// New builds a gateway for routes. conns holds the connection to each service by its
+// full name, e.g. greet.GreetService, routes of services without a connection are left out.
+// gRPC-Web calls are accepted for every method of the services in conns
+func New(conns map[string]grpc.ClientConnInterface, routes []Route, opts ...Option) (*Gateway, error) {
+	g := &Gateway{mux: http.NewServeMux(), conns: conns}
+	for _, opt := range opts {
+		opt(g)
+	}
+	for _, r := range routes {
+		rt, err := resolve(r)
+		if err != nil {
+			return nil, err
+		}
+		conn, ok := conns[string(rt.method.Parent().FullName())]
+		if !ok {
+			continue
+		}
+		rt.conn = conn
+		g.routes = append(g.routes, rt)
+		g.mux.HandleFunc(r.Method+" "+r.Path, func(w http.ResponseWriter, req *http.Request) {
+			g.serve(rt, w, req)
+		})
+	}
+	g.mux.HandleFunc("GET /openapi.json", g.serveOpenAPI)
+	return g, nil
+}
+
+// resolve finds the method of r and checks its path parameters are fields of the request
+func resolve(r Route) (*route, error) {
+	parts := strings.Split(strings.TrimPrefix(r.RPC, "/"), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid RPC %q for %v %v, must be /package.Service/Method", r.RPC, r.Method, r.Path)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find service %v: %v", parts[0], err)
+	}
+	service, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a service", parts[0])
+	}
+	method := service.Methods().ByName(protoreflect.Name(parts[1]))
+	if method == nil {
+		return nil, fmt.Errorf("service %v has no method %v", parts[0], parts[1])
+	}
+	if r.WebSocket && r.Method != http.MethodGet {
+		return nil, fmt.Errorf("WebSocket route %v %v must use GET", r.Method, r.Path)
+	}
+	if !r.WebSocket && method.IsStreamingClient() && method.IsStreamingServer() {
+		return nil, fmt.Errorf("%v is a bi-directional stream and can only be served over a WebSocket", r.RPC)
+	}
+
+	rt := &route{Route: r, method: method}
+	for _, m := range pathParamPattern.FindAllStringSubmatch(r.Path, -1) {
+		p := pathParam{name: m[1], field: m[1]}
+		if field, ok := r.PathFields[p.name]; ok {
+			p.field = field
+		}
+		if findFieldPath(method.Input(), p.field) == nil {
+			return nil, fmt.Errorf("path parameter %v of %v is not a field of %v", p.field, r.Path, method.Input().FullName())
+		}
+		rt.pathParams = append(rt.pathParams, p)
+	}
+	return rt, nil
+}
+
+// pathParam is a path parameter and the request field it fills
+type pathParam struct {
+	name  string
+	field string
+}
+
+// ServeHTTP implements http.Handler
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	if isGRPCWeb(r) {
+		g.serveGRPCWeb(rec, r)
+	} else {
+		g.mux.ServeHTTP(rec, r)
+	}
+	slog.Info("http request finished",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", rec.status,
+		"duration_ms", time.Since(start).Milliseconds())
+}
+
+// serve transcodes one HTTP request into a call of rt
+func (g *Gateway) serve(rt *route, w http.ResponseWriter, r *http.Request) {
+	ctx := outgoingContext(r)
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	switch {
+	case rt.WebSocket:
+		g.serveWebSocket(ctx, rt, w, r)
+	case rt.method.IsStreamingClient():
+		serveClientStream(ctx, rt, w, r)
+	case rt.method.IsStreamingServer():
+		serveServerStream(ctx, rt, w, r)
+	default:
+		serveUnary(ctx, rt, w, r)
+	}
+}
+
+// outgoingContext returns the context of the call made for r, carrying the trace
+// context and the forwardedHeaders as metadata
+func outgoingContext(r *http.Request) context.Context {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	md := metadata.MD{}
+	for _, h := range forwardedHeaders {
+		if v := r.Header.Values(h); len(v) > 0 {
+			md.Set(h, v...)
+		}
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func serveUnary(ctx context.Context, rt *route, w http.ResponseWriter, r *http.Request) {
+	req := dynamicpb.NewMessage(rt.method.Input())
+	if err := decodeRequest(r, rt, req); err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	res := dynamicpb.NewMessage(rt.method.Output())
+	var header, trailer metadata.MD
+	err := rt.conn.Invoke(ctx, rt.RPC, req, res, grpc.Header(&header), grpc.Trailer(&trailer))
+	writeMetadata(w, header, trailer)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, res)
+}
+
+// serveClientStream sends every JSON value of the body as a request,
+// one per line in practice, and writes the single response
+func serveClientStream(ctx context.Context, rt *route, w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := rt.conn.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, rt.RPC)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dec := newJSONStream(r.Body)
+	for {
+		req := dynamicpb.NewMessage(rt.method.Input())
+		err := dec.next(req)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// cancelling the context aborts the call, the service sees a partial stream as cancelled
+			cancel()
+			writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		if err := stream.SendMsg(req); err != nil {
+			// the real error comes back from RecvMsg
+			break
+		}
+	}
+	stream.CloseSend()
+
+	res := dynamicpb.NewMessage(rt.method.Output())
+	err = stream.RecvMsg(res)
+	header, _ := stream.Header()
+	writeMetadata(w, header, stream.Trailer())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeMessage(w, res)
+}
+
+// serveServerStream writes every response as soon as it arrives. Errors before the first
+// response get a regular HTTP error status, later ones are written as the last event
+func serveServerStream(ctx context.Context, rt *route, w http.ResponseWriter, r *http.Request) {
+	req := dynamicpb.NewMessage(rt.method.Input())
+	if err := decodeRequest(r, rt, req); err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	stream, err := rt.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, rt.RPC)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := stream.SendMsg(req); err != nil && err != io.EOF {
+		writeError(w, err)
+		return
+	}
+	stream.CloseSend()
+
+	res := dynamicpb.NewMessage(rt.method.Output())
+	err = stream.RecvMsg(res)
+	header, _ := stream.Header()
+	if err != nil && err != io.EOF {
+		writeMetadata(w, header, stream.Trailer())
+		writeError(w, err)
+		return
+	}
+
+	writeMetadata(w, header)
+	events := newEventWriter(w, r)
+	for err == nil {
+		if werr := events.message(res); werr != nil {
+			// the HTTP client went away, the request context cancels the call
+			return
+		}
+		res = dynamicpb.NewMessage(rt.method.Output())
+		err = stream.RecvMsg(res)
+	}
+	if err != io.EOF {
+		events.error(err)
+	}
+}
+
+// writeMetadata copies the returnedMetadata keys into the response headers
+func writeMetadata(w http.ResponseWriter, mds ...metadata.MD) {
+	for _, md := range mds {
+		for _, k := range returnedMetadata {
+			for _, v := range md.Get(k) {
+				w.Header().Add(k, v)
+			}
+		}
+	}
+}
+
+// statusRecorder remembers the status code for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Hijack lets WebSockets take over the connection
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("the connection cannot be hijacked")
+	}
+	s.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Flush lets streamed responses through the recorder
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
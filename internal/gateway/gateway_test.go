@@ -0,0 +1,262 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/blog/blogclient"
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/blog/blogservice"
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/greet/greetclient"
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/greet/greetservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/gateway"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"google.golang.org/grpc"
+)
+
+// startGateway serves the default routes over HTTP, in front of greet, calculator and blog
+// services running over bufconn with cfg. The blog service stores blogs in collection
+func startGateway(t *testing.T, cfg bootstrap.Config, collection *mongo.Collection, cors gateway.CORSConfig) *httptest.Server {
+	t.Helper()
+	s := servertest.Start(t, cfg, func(s *bootstrap.Server) {
+		greetpb.RegisterGreetServiceServer(s.GRPC, greetservice.NewServer())
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+		blogpb.RegisterBlogServiceServer(s.GRPC, blogservice.NewServer(collection, nil))
+	})
+	conns := map[string]grpc.ClientConnInterface{}
+	for _, service := range []string{greetclient.ServiceName, calcclient.ServiceName, blogclient.ServiceName} {
+		conns[service] = s.Dial(t, []string{service})
+	}
+
+	gw, err := gateway.New(conns, gateway.DefaultRoutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(cors.Handler(gw))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// do sends a request to the gateway and returns the response with its body read
+func do(t *testing.T, req *http.Request) (*http.Response, string) {
+	t.Helper()
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(body)
+}
+
+func newRequest(t *testing.T, method, url, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// compactStream compacts the JSON of every line of a stream body, keeping the
+// data: prefix of server-sent events
+func compactStream(t *testing.T, s string) string {
+	t.Helper()
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			lines[i] = "data: " + compactJSON(t, data)
+		} else if line != "" {
+			lines[i] = compactJSON(t, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compactJSON makes JSON bodies comparable, protojson varies its spacing on purpose
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestTranscode(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("routes", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		blog := func(content string) bson.D {
+			return bson.D{
+				{Key: "_id", Value: id},
+				{Key: "author_id", Value: "angel"},
+				{Key: "title", Value: "Hello"},
+				{Key: "content", Value: content},
+				{Key: "status", Value: blogpb.BlogStatus_PUBLISHED},
+			}
+		}
+		// ReadBlog finds the blog, then UpdateBlog updates it
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "mydb.blog", mtest.FirstBatch, blog("my first post")),
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: blog("an edited post")}),
+		)
+		srv := startGateway(mt.T, bootstrap.Config{}, mt.Coll, gateway.CORSConfig{})
+
+		tests := []struct {
+			name       string
+			method     string
+			path       string
+			body       string
+			wantStatus int
+			wantBody   string
+		}{
+			{"JSON body", "POST", "/v1/greet", `{"greeting": {"first_name": "Angel"}}`, http.StatusOK, `{"result":"Hello Angel"}`},
+			{"query string", "GET", "/v1/calculator/sum?first_number=3&second_number=4", "", http.StatusOK, `{"sum_result":7}`},
+			{"path parameter", "GET", "/v1/calculator/square-root/16", "", http.StatusOK, `{"number_root":4}`},
+			{"client stream", "POST", "/v1/calculator/average", "{\"number\": 1}\n{\"number\": 2}\n", http.StatusOK, `{"average":1.5}`},
+			{
+				"read a blog", "GET", "/v1/blogs/" + id.Hex(), "", http.StatusOK,
+				`{"blog":{"id":"` + id.Hex() + `","author_id":"angel","title":"Hello","content":"my first post","status":"PUBLISHED","moderation_reasons":[]}}`,
+			},
+			{
+				"update a blog, the ID comes from the path", "PUT", "/v1/blogs/" + id.Hex(), `{"blog": {"title": "Hello", "content": "an edited post"}}`, http.StatusOK,
+				`{"blog":{"id":"` + id.Hex() + `","author_id":"angel","title":"Hello","content":"an edited post","status":"PUBLISHED","moderation_reasons":[]}}`,
+			},
+			{"gRPC error", "GET", "/v1/calculator/square-root/-4", "", http.StatusBadRequest, `{"code":3,"message":"received a negative number: -4","details":[]}`},
+			{"invalid path parameter", "GET", "/v1/calculator/square-root/four", "", http.StatusBadRequest, ""},
+			{"unknown query parameter", "GET", "/v1/calculator/sum?third_number=1", "", http.StatusBadRequest, ""},
+			{"invalid body", "POST", "/v1/greet", `{"greeting": 1}`, http.StatusBadRequest, ""},
+			{"invalid blog ID", "GET", "/v1/blogs/not-an-id", "", http.StatusBadRequest, ""},
+			{"no route", "GET", "/v1/unknown", "", http.StatusNotFound, ""},
+		}
+		for _, tt := range tests {
+			res, body := do(mt.T, newRequest(mt.T, tt.method, srv.URL+tt.path, tt.body))
+			if res.StatusCode != tt.wantStatus {
+				mt.Errorf("%v: %v %v status = %v, want %v, body %s", tt.name, tt.method, tt.path, res.StatusCode, tt.wantStatus, body)
+				continue
+			}
+			if tt.wantBody != "" && compactJSON(mt.T, body) != compactJSON(mt.T, tt.wantBody) {
+				mt.Errorf("%v: %v %v body = %s, want %s", tt.name, tt.method, tt.path, body, tt.wantBody)
+			}
+		}
+
+		// the update went to the blog of the path
+		e := mt.GetStartedEvent()
+		for e != nil && e.CommandName != "findAndModify" {
+			e = mt.GetStartedEvent()
+		}
+		if e == nil {
+			mt.Fatal("no findAndModify command was sent")
+		}
+		if got := e.Command.Lookup("query", "_id").ObjectID(); got != id {
+			mt.Errorf("updated blog %v, want %v", got.Hex(), id.Hex())
+		}
+	})
+}
+
+func TestServerStream(t *testing.T) {
+	srv := startGateway(t, bootstrap.Config{}, nil, gateway.CORSConfig{})
+
+	tests := []struct {
+		name            string
+		path            string
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "newline delimited JSON",
+			path:            "/v1/calculator/prime-factors/12",
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"result\":{\"prime_factor\":\"2\"}}\n{\"result\":{\"prime_factor\":\"2\"}}\n{\"result\":{\"prime_factor\":\"3\"}}\n",
+		},
+		{
+			name:            "server-sent events",
+			path:            "/v1/calculator/prime-factors/12",
+			accept:          "text/event-stream",
+			wantContentType: "text/event-stream",
+			wantBody:        "data: {\"prime_factor\":\"2\"}\n\ndata: {\"prime_factor\":\"2\"}\n\ndata: {\"prime_factor\":\"3\"}\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, "GET", srv.URL+tt.path, "")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			res, body := do(t, req)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("status = %v, want 200, body %s", res.StatusCode, body)
+			}
+			if got := res.Header.Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if compactStream(t, body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	srv := startGateway(t, bootstrap.Config{}, nil, gateway.CORSConfig{AllowedOrigins: "https://app.example.com", AllowedHeaders: "authorization,content-type"})
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  bool
+		wantStatus int
+		wantOrigin string
+	}{
+		{"preflight", "OPTIONS", "https://app.example.com", true, http.StatusNoContent, "https://app.example.com"},
+		{"allowed origin", "GET", "https://app.example.com", false, http.StatusOK, "https://app.example.com"},
+		{"other origin", "GET", "https://evil.example.com", false, http.StatusOK, ""},
+		{"preflight from another origin", "OPTIONS", "https://evil.example.com", true, http.StatusMethodNotAllowed, ""},
+		{"same origin", "GET", "", false, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, tt.method, srv.URL+"/v1/calculator/sum?first_number=1&second_number=2", "")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", "GET")
+			}
+			res, body := do(t, req)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %v, want %v, body %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if got := res.Header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.preflight && tt.wantOrigin != "" {
+				if got := res.Header.Get("Access-Control-Allow-Headers"); got != "authorization, content-type" {
+					t.Errorf("Access-Control-Allow-Headers = %q, want the allowed headers", got)
+				}
+			}
+			if tt.wantOrigin != "" && !strings.Contains(res.Header.Get("Access-Control-Expose-Headers"), "grpc-status") {
+				t.Errorf("Access-Control-Expose-Headers = %q, want grpc-status exposed", res.Header.Get("Access-Control-Expose-Headers"))
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC-Web, as described in https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md.
+// Calls are proxied to the services message by message without decoding them, so every
+// method works, including server streams. Browsers cannot send client streams, but the
+// proxy passes them on anyway for non-browser clients
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// trailerFlag marks the frame carrying the trailers at the end of a response
+	trailerFlag = 0x80
+	// compressedFlag marks a compressed message, which the proxy does not support
+	compressedFlag = 0x01
+)
+
+// isGRPCWeb reports whether r is a gRPC-Web call, in binary or text mode
+func isGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+// serveGRPCWeb proxies a gRPC-Web call to its service. The HTTP status is always 200,
+// the outcome of the call is in the grpc-status trailer like in plain gRPC
+func (g *Gateway) serveGRPCWeb(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, grpcWebTextContentType)
+	subtype := strings.TrimPrefix(strings.TrimPrefix(contentType, grpcWebTextContentType), grpcWebContentType)
+	if subtype != "" && subtype != "+proto" {
+		http.Error(w, fmt.Sprintf("unsupported gRPC-Web content type %q, only proto messages are supported", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	responseType := grpcWebContentType + "+proto"
+	if text {
+		responseType = grpcWebTextContentType + "+proto"
+	}
+	w.Header().Set("Content-Type", responseType)
+	out := &grpcWebWriter{w: w, text: text}
+
+	service := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+	conn, ok := g.conns[service]
+	if !ok {
+		out.trailers(status.Newf(codes.Unimplemented, "unknown service %v", service), nil)
+		return
+	}
+
+	ctx, cancel, err := grpcWebContext(r)
+	if err != nil {
+		out.trailers(status.Convert(err), nil)
+		return
+	}
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, r.URL.Path, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		out.trailers(status.Convert(err), nil)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if text {
+		body = newTextDecoder(body)
+	}
+	for {
+		msg, err := readFrame(body)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cancel()
+			out.trailers(status.New(codes.InvalidArgument, err.Error()), nil)
+			return
+		}
+		if err := stream.SendMsg(&msg); err != nil {
+			// the real error comes back from RecvMsg
+			break
+		}
+	}
+	stream.CloseSend()
+
+	header, err := stream.Header()
+	if err == nil {
+		writeGRPCWebMetadata(w.Header(), header)
+	}
+	w.WriteHeader(http.StatusOK)
+	for {
+		var msg []byte
+		if err = stream.RecvMsg(&msg); err != nil {
+			break
+		}
+		if werr := out.frame(0, msg); werr != nil {
+			// the browser went away, cancelling the context ends the call
+			return
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	out.trailers(status.Convert(err), stream.Trailer())
+}
+
+// grpcWebContext returns the context of the proxied call, with the deadline
+// from the grpc-timeout header
+func grpcWebContext(r *http.Request) (context.Context, context.CancelFunc, error) {
+	ctx := outgoingContext(r)
+
+	if t := r.Header.Get("grpc-timeout"); t != "" {
+		timeout, err := parseTimeout(t)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid grpc-timeout %q: %v", t, err)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, nil
+}
+
+// parseTimeout parses a grpc-timeout value, an integer followed by one of H, M, S, m, u or n
+func parseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("bad length")
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[s[len(s)-1]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", s[len(s)-1])
+	}
+	var n int64
+	for _, c := range s[:len(s)-1] {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("not a number")
+		}
+		n = n*10 + int64(c-'0')
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// readFrame reads one length-prefixed message of the request body
+func readFrame(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated message")
+		}
+		return nil, err
+	}
+	if prefix[0]&compressedFlag != 0 {
+		return nil, fmt.Errorf("compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if length > maxBodySize {
+		return nil, fmt.Errorf("message of %v bytes is too large", length)
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("truncated message")
+	}
+	return msg, nil
+}
+
+// textDecoder decodes a gRPC-Web text mode body. Clients may base64 encode every
+// message on its own and send the padded chunks one after the other, which
+// base64.NewDecoder rejects, so the body is decoded 4 characters at a time
+type textDecoder struct {
+	r   *bufio.Reader
+	out []byte
+}
+
+func newTextDecoder(r io.Reader) *textDecoder {
+	return &textDecoder{r: bufio.NewReader(r)}
+}
+
+func (d *textDecoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		var quantum [4]byte
+		n := 0
+		for n < len(quantum) {
+			c, err := d.r.ReadByte()
+			if err == io.EOF && n > 0 {
+				return 0, fmt.Errorf("truncated base64 body")
+			}
+			if err != nil {
+				return 0, err
+			}
+			if c == '\r' || c == '\n' {
+				continue
+			}
+			quantum[n] = c
+			n++
+		}
+		var decoded [3]byte
+		m, err := base64.StdEncoding.Decode(decoded[:], quantum[:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid base64 body: %v", err)
+		}
+		d.out = decoded[:m]
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// grpcWebWriter writes the frames of a response, base64 encoding each one in text mode
+type grpcWebWriter struct {
+	w    http.ResponseWriter
+	text bool
+}
+
+func (g *grpcWebWriter) frame(flag byte, data []byte) error {
+	buf := make([]byte, 5+len(data))
+	buf[0] = flag
+	binary.BigEndian.PutUint32(buf[1:], uint32(len(data)))
+	copy(buf[5:], data)
+	if g.text {
+		buf = []byte(base64.StdEncoding.EncodeToString(buf))
+	}
+	if _, err := g.w.Write(buf); err != nil {
+		return err
+	}
+	if f, ok := g.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// trailers ends the response with the status and trailer metadata of the call
+func (g *grpcWebWriter) trailers(st *status.Status, trailer metadata.MD) {
+	h := http.Header{}
+	h.Set("grpc-status", fmt.Sprint(int32(st.Code())))
+	if st.Message() != "" {
+		h.Set("grpc-message", encodeGRPCMessage(st.Message()))
+	}
+	writeGRPCWebMetadata(h, trailer)
+
+	var b strings.Builder
+	for k, values := range h {
+		for _, v := range values {
+			fmt.Fprintf(&b, "%s: %s\r\n", strings.ToLower(k), v)
+		}
+	}
+	g.frame(trailerFlag, []byte(b.String()))
+}
+
+// writeGRPCWebMetadata copies md into h, binary values are base64 encoded as on the wire
+func writeGRPCWebMetadata(h http.Header, md metadata.MD) {
+	for k, values := range md {
+		if k == "content-type" {
+			continue
+		}
+		for _, v := range values {
+			if strings.HasSuffix(k, "-bin") {
+				v = base64.RawStdEncoding.EncodeToString([]byte(v))
+			}
+			h.Add(k, v)
+		}
+	}
+}
+
+// encodeGRPCMessage percent-encodes a status message like gRPC does for grpc-message,
+// everything outside printable ASCII and the percent sign itself
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// rawCodec passes the messages through without decoding them
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec cannot marshal %T", v)
+	}
+	return *msg, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec cannot unmarshal into %T", v)
+	}
+	*msg = append((*msg)[:0], data...)
+	return nil
+}
+
+// Name is proto so the services see the usual application/grpc+proto content type
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "1H", want: time.Hour},
+		{in: "2M", want: 2 * time.Minute},
+		{in: "30S", want: 30 * time.Second},
+		{in: "100m", want: 100 * time.Millisecond},
+		{in: "5u", want: 5 * time.Microsecond},
+		{in: "7n", want: 7 * time.Nanosecond},
+		{in: "99999999S", want: 99999999 * time.Second},
+		{in: "0m", want: 0},
+		{in: "S", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "123456789S", wantErr: true},
+		{in: "10x", wantErr: true},
+		{in: "-1S", wantErr: true},
+		{in: "1.5S", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeout(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTimeout(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseTimeout(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextDecoder(t *testing.T) {
+	enc := base64.StdEncoding.EncodeToString
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "one chunk", in: enc([]byte("hello world")), want: "hello world"},
+		{name: "padded chunks", in: enc([]byte("a")) + enc([]byte("bc")) + enc([]byte("def")), want: "abcdef"},
+		{name: "line breaks", in: enc([]byte("hello"))[:4] + "\r\n" + enc([]byte("hello"))[4:] + "\n", want: "hello"},
+		{name: "empty", in: "", want: ""},
+		{name: "truncated", in: "aGVsbG8", wantErr: true},
+		{name: "invalid", in: "a*==", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(newTextDecoder(strings.NewReader(tt.in)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("decoded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package gateway_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/gateway"
+	"github.com/angel/golang_api_microservice/internal/ratelimit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"google.golang.org/protobuf/proto"
+)
+
+// frame is a length-prefixed gRPC-Web message, or the trailers when trailer is set
+type frame struct {
+	trailer bool
+	data    []byte
+}
+
+// encodeFrame frames m like a gRPC-Web client
+func encodeFrame(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5+len(data))
+	binary.BigEndian.PutUint32(buf[1:], uint32(len(data)))
+	copy(buf[5:], data)
+	return buf
+}
+
+// decodeFrames splits a response body into its frames
+func decodeFrames(t *testing.T, body []byte) []frame {
+	t.Helper()
+	var frames []frame
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("truncated frame prefix %x", body)
+		}
+		length := int(binary.BigEndian.Uint32(body[1:5]))
+		if len(body) < 5+length {
+			t.Fatalf("frame of %v bytes, only %v left", length, len(body)-5)
+		}
+		frames = append(frames, frame{trailer: body[0]&0x80 != 0, data: body[5 : 5+length]})
+		body = body[5+length:]
+	}
+	return frames
+}
+
+// decodeText decodes a text mode response, where every frame is base64 encoded on its own
+func decodeText(t *testing.T, body string) []byte {
+	t.Helper()
+	var out []byte
+	for len(body) >= 4 {
+		chunk := strings.Index(body, "=")
+		end := len(body)
+		if chunk >= 0 {
+			end = chunk
+			for end < len(body) && body[end] == '=' {
+				end++
+			}
+		}
+		b, err := base64.StdEncoding.DecodeString(body[:end])
+		if err != nil {
+			t.Fatalf("invalid base64 %q: %v", body[:end], err)
+		}
+		out = append(out, b...)
+		body = body[end:]
+	}
+	if body != "" {
+		t.Fatalf("trailing base64 %q", body)
+	}
+	return out
+}
+
+// parseTrailers reads the "key: value" lines of a trailer frame
+func parseTrailers(data []byte) map[string]string {
+	trailers := map[string]string{}
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if k, v, ok := strings.Cut(line, ": "); ok {
+			trailers[k] = v
+		}
+	}
+	return trailers
+}
+
+// grpcWebCall posts body to method and returns the frames of the response
+func grpcWebCall(t *testing.T, url, method, contentType string, body []byte, header http.Header) (*http.Response, []frame) {
+	t.Helper()
+	if strings.HasPrefix(contentType, "application/grpc-web-text") {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+	req := newRequest(t, "POST", url+method, string(body))
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, resBody := do(t, req)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("%v status = %v, want 200, body %q", method, res.StatusCode, resBody)
+	}
+	raw := []byte(resBody)
+	if strings.HasPrefix(contentType, "application/grpc-web-text") {
+		raw = decodeText(t, resBody)
+	}
+	return res, decodeFrames(t, raw)
+}
+
+// messages unmarshals the message frames into new messages of newMsg and
+// returns them with the trailers
+func messages[M proto.Message](t *testing.T, frames []frame, newMsg func() M) ([]M, map[string]string) {
+	t.Helper()
+	var msgs []M
+	for i, f := range frames {
+		if f.trailer {
+			if i != len(frames)-1 {
+				t.Fatalf("trailers in frame %v of %v", i, len(frames))
+			}
+			return msgs, parseTrailers(f.data)
+		}
+		m := newMsg()
+		if err := proto.Unmarshal(f.data, m); err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, m)
+	}
+	t.Fatal("the response has no trailers")
+	return nil, nil
+}
+
+func TestGRPCWeb(t *testing.T) {
+	srv := startGateway(t, bootstrap.Config{}, nil, gateway.CORSConfig{})
+	sum := encodeFrame(t, &calculatorpb.SumRequest{FirstNumber: 3, SecondNumber: 4})
+
+	for _, contentType := range []string{"application/grpc-web", "application/grpc-web+proto", "application/grpc-web-text", "application/grpc-web-text+proto"} {
+		t.Run(contentType, func(t *testing.T) {
+			res, frames := grpcWebCall(t, srv.URL, "/calculator.CalculatorService/Sum", contentType, sum, nil)
+			want := strings.TrimSuffix(contentType, "+proto") + "+proto"
+			if got := res.Header.Get("Content-Type"); got != want {
+				t.Errorf("Content-Type = %q, want %q", got, want)
+			}
+			if res.Header.Get("x-request-id") == "" {
+				t.Error("the response headers have no x-request-id")
+			}
+			msgs, trailers := messages(t, frames, func() *calculatorpb.SumResponse { return &calculatorpb.SumResponse{} })
+			if len(msgs) != 1 || msgs[0].GetSumResult() != 7 {
+				t.Errorf("messages = %v, want one sum of 7", msgs)
+			}
+			if trailers["grpc-status"] != "0" {
+				t.Errorf("trailers = %v, want grpc-status 0", trailers)
+			}
+		})
+	}
+}
+
+func TestGRPCWebStreams(t *testing.T) {
+	srv := startGateway(t, bootstrap.Config{}, nil, gateway.CORSConfig{})
+
+	t.Run("server stream", func(t *testing.T) {
+		_, frames := grpcWebCall(t, srv.URL, "/calculator.CalculatorService/PrimeNumberDecomposition", "application/grpc-web+proto",
+			encodeFrame(t, &calculatorpb.PrimeNumberDecompositionRequest{Number: 12}), nil)
+		msgs, trailers := messages(t, frames, func() *calculatorpb.PrimeNumberDecompositionResponse {
+			return &calculatorpb.PrimeNumberDecompositionResponse{}
+		})
+		var got []int64
+		for _, m := range msgs {
+			got = append(got, m.GetPrimeFactor())
+		}
+		if len(got) != 3 || got[0] != 2 || got[1] != 2 || got[2] != 3 {
+			t.Errorf("prime factors = %v, want [2 2 3]", got)
+		}
+		if trailers["grpc-status"] != "0" {
+			t.Errorf("trailers = %v, want grpc-status 0", trailers)
+		}
+	})
+
+	t.Run("text mode client stream in padded chunks", func(t *testing.T) {
+		// every message is encoded on its own, the body is several padded base64 chunks
+		var body bytes.Buffer
+		for _, n := range []int32{1, 2} {
+			body.WriteString(base64.StdEncoding.EncodeToString(encodeFrame(t, &calculatorpb.ComputeAverageRequest{Number: n})))
+		}
+		if strings.Count(body.String(), "=") < 2 {
+			t.Fatalf("body %q is not padded chunks", body.String())
+		}
+		req := newRequest(t, "POST", srv.URL+"/calculator.CalculatorService/ComputeAverage", body.String())
+		req.Header.Set("Content-Type", "application/grpc-web-text")
+		_, resBody := do(t, req)
+		msgs, trailers := messages(t, decodeFrames(t, decodeText(t, resBody)), func() *calculatorpb.ComputeAverageResponse {
+			return &calculatorpb.ComputeAverageResponse{}
+		})
+		if len(msgs) != 1 || msgs[0].GetAverage() != 1.5 {
+			t.Errorf("messages = %v, want one average of 1.5", msgs)
+		}
+		if trailers["grpc-status"] != "0" {
+			t.Errorf("trailers = %v, want grpc-status 0", trailers)
+		}
+	})
+}
+
+func TestGRPCWebListBlogs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("published blogs", func(mt *mtest.T) {
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		doc := func(id primitive.ObjectID) bson.D {
+			return bson.D{{Key: "_id", Value: id}, {Key: "title", Value: "Hello"}, {Key: "status", Value: blogpb.BlogStatus_PUBLISHED}}
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "mydb.blog", mtest.FirstBatch, doc(first), doc(second)))
+		srv := startGateway(mt.T, bootstrap.Config{}, mt.Coll, gateway.CORSConfig{})
+
+		_, frames := grpcWebCall(mt.T, srv.URL, "/blog.BlogService/ListBlogs", "application/grpc-web-text", encodeFrame(mt.T, &blogpb.ListBlogsRequest{}), nil)
+		msgs, trailers := messages(mt.T, frames, func() *blogpb.ListBlogsResponse { return &blogpb.ListBlogsResponse{} })
+		if len(msgs) != 2 || msgs[0].GetBlog().GetId() != first.Hex() || msgs[1].GetBlog().GetId() != second.Hex() {
+			mt.Errorf("messages = %v, want blogs %v and %v", msgs, first.Hex(), second.Hex())
+		}
+		if trailers["grpc-status"] != "0" {
+			mt.Errorf("trailers = %v, want grpc-status 0", trailers)
+		}
+	})
+}
+
+func TestGRPCWebTrailers(t *testing.T) {
+	cfg := bootstrap.Config{RateLimit: ratelimit.Config{Methods: ratelimit.MethodLimits{
+		"/calculator.CalculatorService/Sum": {Rate: 0.001, Burst: 1},
+	}}}
+	srv := startGateway(t, cfg, nil, gateway.CORSConfig{})
+	sum := encodeFrame(t, &calculatorpb.SumRequest{FirstNumber: 1, SecondNumber: 2})
+
+	tests := []struct {
+		name        string
+		method      string
+		body        []byte
+		header      http.Header
+		wantStatus  string
+		wantMessage string
+		wantTrailer string
+	}{
+		{name: "within the rate limit", method: "/calculator.CalculatorService/Sum", body: sum, wantStatus: "0"},
+		{name: "over the rate limit", method: "/calculator.CalculatorService/Sum", body: sum, wantStatus: "8", wantTrailer: "retry-after"},
+		{
+			name:        "error with a message",
+			method:      "/calculator.CalculatorService/SquareRoot",
+			body:        encodeFrame(t, &calculatorpb.SquareRootRequest{Number: -4}),
+			wantStatus:  "3",
+			wantMessage: "received a negative number: -4",
+		},
+		{
+			name:        "invalid grpc-timeout",
+			method:      "/calculator.CalculatorService/SquareRoot",
+			body:        encodeFrame(t, &calculatorpb.SquareRootRequest{Number: 4}),
+			header:      http.Header{"Grpc-Timeout": {"1x"}},
+			wantStatus:  "3",
+			wantMessage: `invalid grpc-timeout "1x": unknown unit 'x'`,
+		},
+		{name: "unknown service", method: "/unknown.Service/Call", body: sum, wantStatus: "12"},
+		{name: "unknown method", method: "/calculator.CalculatorService/Unknown", body: sum, wantStatus: "12"},
+		{name: "truncated message", method: "/calculator.CalculatorService/SquareRoot", body: sum[:4], wantStatus: "3", wantMessage: "truncated message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, frames := grpcWebCall(t, srv.URL, tt.method, "application/grpc-web+proto", tt.body, tt.header)
+			if len(frames) == 0 || !frames[len(frames)-1].trailer {
+				t.Fatalf("frames = %v, want trailers last", frames)
+			}
+			trailers := parseTrailers(frames[len(frames)-1].data)
+			if trailers["grpc-status"] != tt.wantStatus {
+				t.Errorf("grpc-status = %q, want %q, trailers %v", trailers["grpc-status"], tt.wantStatus, trailers)
+			}
+			if tt.wantMessage != "" && trailers["grpc-message"] != tt.wantMessage {
+				t.Errorf("grpc-message = %q, want %q", trailers["grpc-message"], tt.wantMessage)
+			}
+			if tt.wantTrailer != "" && trailers[tt.wantTrailer] == "" {
+				t.Errorf("trailers = %v, want %v", trailers, tt.wantTrailer)
+			}
+		})
+	}
+}
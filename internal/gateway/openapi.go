@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// statusSchema is the name of the google.rpc.Status schema every error response uses
+const statusSchema = "google.rpc.Status"
+
+// queryParamDepth limits how deep nested message fields are flattened into query parameters
+const queryParamDepth = 3
+
+// OpenAPI returns the OpenAPI 3 spec of the routes served by the gateway,
+// built from the same descriptors used to transcode the requests
+func (g *Gateway) OpenAPI() ([]byte, error) {
+	schemas := map[string]interface{}{
+		statusSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"code":    map[string]interface{}{"type": "integer", "format": "int32", "description": "gRPC status code"},
+				"message": map[string]interface{}{"type": "string"},
+				"details": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+			},
+		},
+	}
+	paths := map[string]map[string]interface{}{}
+
+	for _, rt := range g.routes {
+		if rt.WebSocket {
+			// OpenAPI 3 cannot describe WebSocket messages, they are documented in the README
+			continue
+		}
+		in, out := rt.method.Input(), rt.method.Output()
+		addSchema(schemas, in)
+		addSchema(schemas, out)
+
+		op := map[string]interface{}{
+			"operationId": string(rt.method.Parent().Name()) + "_" + string(rt.method.Name()),
+			"summary":     rt.Summary,
+			"tags":        []string{string(rt.method.Parent().FullName())},
+			"responses": map[string]interface{}{
+				"200":     map[string]interface{}{"description": "OK", "content": responseContent(rt)},
+				"default": map[string]interface{}{"description": "error", "content": jsonContent(statusSchema)},
+			},
+		}
+
+		params := []interface{}{}
+		var pathFields []string
+		for _, p := range rt.pathParams {
+			params = append(params, map[string]interface{}{
+				"name":     p.name,
+				"in":       "path",
+				"required": true,
+				"schema":   fieldSchema(findFieldPath(in, p.field)),
+			})
+			pathFields = append(pathFields, p.field)
+		}
+		if rt.Method == http.MethodGet || rt.Method == http.MethodDelete {
+			params = append(params, queryParams(in, "", pathFields, 0)...)
+		} else if rt.method.IsStreamingClient() {
+			op["requestBody"] = map[string]interface{}{
+				"description": "one request per line",
+				"content":     map[string]interface{}{"application/x-ndjson": map[string]interface{}{"schema": ref(in)}},
+			}
+		} else {
+			op["requestBody"] = map[string]interface{}{"content": jsonContent(string(in.FullName()))}
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if paths[rt.Path] == nil {
+			paths[rt.Path] = map[string]interface{}{}
+		}
+		paths[rt.Path][strings.ToLower(rt.Method)] = op
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "golang_api_microservice HTTP gateway",
+			"version": "v1",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}, "", "  ")
+}
+
+func (g *Gateway) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := g.OpenAPI()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// responseContent describes a unary response, or the NDJSON and SSE forms of a server stream
+func responseContent(rt *route) map[string]interface{} {
+	out := rt.method.Output()
+	if !rt.method.IsStreamingServer() {
+		return jsonContent(string(out.FullName()))
+	}
+	return map[string]interface{}{
+		"application/x-ndjson": map[string]interface{}{
+			"schema": map[string]interface{}{
+				"type":        "object",
+				"description": "one line per response, the stream ends with an error line if the call fails",
+				"properties": map[string]interface{}{
+					"result": ref(out),
+					"error":  map[string]interface{}{"$ref": "#/components/schemas/" + statusSchema},
+				},
+			},
+		},
+		"text/event-stream": map[string]interface{}{
+			"schema": map[string]interface{}{
+				"type":        "string",
+				"description": "one data event per " + string(out.FullName()) + ", an error event with a " + statusSchema + " if the call fails",
+			},
+		},
+	}
+}
+
+func jsonContent(schema string) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": map[string]interface{}{"$ref": "#/components/schemas/" + schema},
+		},
+	}
+}
+
+func ref(md protoreflect.MessageDescriptor) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + string(md.FullName())}
+}
+
+// addSchema adds md and every message it uses to schemas
+func addSchema(schemas map[string]interface{}, md protoreflect.MessageDescriptor) {
+	name := string(md.FullName())
+	if _, ok := schemas[name]; ok {
+		return
+	}
+	properties := map[string]interface{}{}
+	schemas[name] = map[string]interface{}{"type": "object", "properties": properties}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		properties[string(fd.Name())] = fieldSchema(fd)
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		if fd.Message() != nil {
+			addSchema(schemas, fd.Message())
+		}
+	}
+}
+
+// fieldSchema is the schema of a field as protojson encodes it
+func fieldSchema(fd protoreflect.FieldDescriptor) map[string]interface{} {
+	if fd.IsMap() {
+		return map[string]interface{}{"type": "object", "additionalProperties": valueSchema(fd.MapValue())}
+	}
+	if fd.IsList() {
+		return map[string]interface{}{"type": "array", "items": valueSchema(fd)}
+	}
+	return valueSchema(fd)
+}
+
+// valueSchema is the schema of a single value of fd
+func valueSchema(fd protoreflect.FieldDescriptor) map[string]interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// protojson writes 64 bit integers as strings, JavaScript numbers cannot hold them
+		return map[string]interface{}{"type": "string", "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]interface{}{"type": "string", "format": "uint64"}
+	case protoreflect.FloatKind:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return ref(fd.Message())
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// queryParams lists the fields of md that can be set from the query string,
+// with nested message fields flattened into dotted names
+func queryParams(md protoreflect.MessageDescriptor, prefix string, skip []string, depth int) []interface{} {
+	params := []interface{}{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := prefix + string(fd.Name())
+		if contains(skip, name) || fd.IsMap() {
+			continue
+		}
+		if fd.Kind() == protoreflect.MessageKind {
+			if !fd.IsList() && depth < queryParamDepth {
+				params = append(params, queryParams(fd.Message(), name+".", skip, depth+1)...)
+			}
+			continue
+		}
+		params = append(params, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": fieldSchema(fd),
+		})
+	}
+	return params
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
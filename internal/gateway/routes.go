@@ -0,0 +1,48 @@
+package gateway
+
+// Route maps an HTTP method and path to a gRPC method.
+//
+// Path uses the http.ServeMux syntax, every {name} segment fills the request
+// field with that name, or the field PathFields maps it to. GET routes read the rest of the request from the query
+// string, e.g. ?first_number=1&second_number=2, other methods read it from the
+// JSON body. Client streaming methods take one JSON request per line.
+// WebSocket routes upgrade the connection and exchange one JSON message per
+// request and response, which is how bi-directional streams are served
+type Route struct {
+	Method string
+	Path   string
+	// RPC is the full gRPC method name, e.g. /greet.GreetService/Greet
+	RPC string
+	// PathFields maps path parameters to nested request fields, e.g. blog_id to blog.id
+	PathFields map[string]string
+	// Summary describes the route in the OpenAPI spec
+	Summary string
+	// WebSocket serves the route over a WebSocket, Method must be GET
+	WebSocket bool
+}
+
+// DefaultRoutes are the HTTP routes of the greet, calculator and blog services.
+// Bi-directional streams (GreetEveryone, FindMaximum) cannot be mapped to a single
+// HTTP request and response, so they are served over WebSockets
+var DefaultRoutes = []Route{
+	{Method: "POST", Path: "/v1/greet", RPC: "/greet.GreetService/Greet", Summary: "Greet someone"},
+	{Method: "POST", Path: "/v1/greet/many-times", RPC: "/greet.GreetService/GreetManyTimes", Summary: "Stream ten greetings, one per second"},
+	{Method: "POST", Path: "/v1/greet/long", RPC: "/greet.GreetService/LongGreet", Summary: "Greet every person sent, one GreetRequest per line"},
+	{Method: "POST", Path: "/v1/greet/with-deadline", RPC: "/greet.GreetService/GreetWithDeadline", Summary: "Greet someone after three seconds"},
+	{Method: "GET", Path: "/v1/greet/everyone", RPC: "/greet.GreetService/GreetEveryone", Summary: "Greet every person sent as soon as they arrive", WebSocket: true},
+
+	{Method: "GET", Path: "/v1/calculator/sum", RPC: "/calculator.CalculatorService/Sum", Summary: "Add two numbers"},
+	{Method: "GET", Path: "/v1/calculator/prime-factors/{number}", RPC: "/calculator.CalculatorService/PrimeNumberDecomposition", Summary: "Stream the prime factors of a number"},
+	{Method: "POST", Path: "/v1/calculator/average", RPC: "/calculator.CalculatorService/ComputeAverage", Summary: "Average the numbers sent, one request per line"},
+	{Method: "GET", Path: "/v1/calculator/maximum", RPC: "/calculator.CalculatorService/FindMaximum", Summary: "Running maximum of the numbers sent", WebSocket: true},
+	{Method: "GET", Path: "/v1/calculator/square-root/{number}", RPC: "/calculator.CalculatorService/SquareRoot", Summary: "Square root of a positive number"},
+
+	{Method: "GET", Path: "/v1/blogs", RPC: "/blog.BlogService/ListBlogs", Summary: "Stream the published blogs"},
+	{Method: "POST", Path: "/v1/blogs", RPC: "/blog.BlogService/CreateBlog", Summary: "Write a blog, it waits for review if moderation flags it"},
+	{Method: "GET", Path: "/v1/blogs/{blog_id}", RPC: "/blog.BlogService/ReadBlog", Summary: "Read a blog"},
+	{Method: "PUT", Path: "/v1/blogs/{blog_id}", RPC: "/blog.BlogService/UpdateBlog", PathFields: map[string]string{"blog_id": "blog.id"}, Summary: "Replace the title and content of a blog, as its author or an admin"},
+	{Method: "DELETE", Path: "/v1/blogs/{blog_id}", RPC: "/blog.BlogService/DeleteBlog", Summary: "Delete a blog, as its author or an admin"},
+	{Method: "GET", Path: "/v1/blogs/moderation-queue", RPC: "/blog.BlogService/ListModerationQueue", Summary: "Stream the blogs waiting for review"},
+	{Method: "POST", Path: "/v1/blogs/{blog_id}/approve", RPC: "/blog.BlogService/ApproveContent", Summary: "Publish a blog waiting for review"},
+	{Method: "POST", Path: "/v1/blogs/{blog_id}/reject", RPC: "/blog.BlogService/RejectContent", Summary: "Reject a blog waiting for review"},
+}
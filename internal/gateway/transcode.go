@@ -0,0 +1,302 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// marshalOptions keeps the proto field names, as in the .proto files and grpcurl,
+// and writes zero values so clients always see every field
+var marshalOptions = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// decodeRequest fills req from the query string of GET and DELETE requests or from the
+// JSON body of the others, then from the path parameters, which win over both
+func decodeRequest(r *http.Request, rt *route, req proto.Message) error {
+	if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+		for key, values := range r.URL.Query() {
+			if err := setField(req.ProtoReflect(), key, values); err != nil {
+				return err
+			}
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("cannot read body: %v", err)
+		}
+		if len(strings.TrimSpace(string(body))) > 0 {
+			if err := protojson.Unmarshal(body, req); err != nil {
+				return fmt.Errorf("invalid body: %v", err)
+			}
+		}
+	}
+
+	for _, p := range rt.pathParams {
+		if err := setField(req.ProtoReflect(), p.field, []string{r.PathValue(p.name)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonStream reads the requests of a client stream, one JSON value after the other
+type jsonStream struct {
+	dec *json.Decoder
+}
+
+func newJSONStream(r io.Reader) *jsonStream {
+	return &jsonStream{dec: json.NewDecoder(r)}
+}
+
+// next decodes the next request into m, it returns io.EOF at the end of the body
+func (s *jsonStream) next(m proto.Message) error {
+	var raw json.RawMessage
+	if err := s.dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("invalid body: %v", err)
+	}
+	if err := protojson.Unmarshal(raw, m); err != nil {
+		return fmt.Errorf("invalid body: %v", err)
+	}
+	return nil
+}
+
+// findField looks a field up by its proto name or its JSON name
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// findFieldPath looks a field up by its path, e.g. greeting.first_name
+func findFieldPath(md protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := findField(md, name)
+		if fd == nil || i == len(names)-1 {
+			return fd
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil
+		}
+		md = fd.Message()
+	}
+	return nil
+}
+
+// setField sets the field at path, e.g. greeting.first_name, from its string values.
+// Repeated fields take every value, the others the last one
+func setField(m protoreflect.Message, path string, values []string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := findField(m.Descriptor(), name)
+		if fd == nil {
+			return fmt.Errorf("unknown field %q", path)
+		}
+		if i < len(names)-1 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %q has no sub fields", strings.Join(names[:i+1], "."))
+			}
+			m = m.Mutable(fd).Message()
+			continue
+		}
+
+		if fd.IsMap() {
+			return fmt.Errorf("map field %q cannot be set from a parameter", path)
+		}
+		if fd.IsList() {
+			list := m.Mutable(fd).List()
+			for _, s := range values {
+				v, err := parseValue(fd, s)
+				if err != nil {
+					return fmt.Errorf("invalid value for %q: %v", path, err)
+				}
+				list.Append(v)
+			}
+			return nil
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		v, err := parseValue(fd, values[len(values)-1])
+		if err != nil {
+			return fmt.Errorf("invalid value for %q: %v", path, err)
+		}
+		m.Set(fd, v)
+	}
+	return nil
+}
+
+// parseValue parses a query or path parameter for a scalar or enum field
+func parseValue(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.EnumKind:
+		if v := fd.Enum().Values().ByName(protoreflect.Name(s)); v != nil {
+			return protoreflect.ValueOfEnum(v.Number()), nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown %v value %q", fd.Enum().Name(), s)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("%v fields cannot be set from a parameter", fd.Kind())
+}
+
+// writeMessage writes m as the JSON body of a successful response
+func writeMessage(w http.ResponseWriter, m proto.Message) {
+	data, err := marshalOptions.Marshal(m)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "cannot encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeError writes err as a google.rpc.Status JSON body with the matching HTTP status
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatus(st.Code()))
+	w.Write(statusJSON(st))
+}
+
+// statusJSON encodes st, dropping details whose types are not linked into this binary
+func statusJSON(st *status.Status) []byte {
+	data, err := marshalOptions.Marshal(st.Proto())
+	if err != nil {
+		p := st.Proto()
+		p.Details = nil
+		data, _ = marshalOptions.Marshal(p)
+	}
+	return data
+}
+
+// HTTPStatus maps a gRPC status code to the closest HTTP status, as documented in
+// https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// nginx's "client closed request", there is no standard status for it
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// eventWriter writes the responses of a server stream, as server-sent events when the
+// client accepts text/event-stream and as newline delimited JSON otherwise. Each NDJSON
+// line is {"result": ...} or, for an error ending the stream, {"error": ...}
+type eventWriter struct {
+	w   http.ResponseWriter
+	sse bool
+}
+
+func newEventWriter(w http.ResponseWriter, r *http.Request) *eventWriter {
+	e := &eventWriter{w: w, sse: strings.Contains(r.Header.Get("Accept"), "text/event-stream")}
+	if e.sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+	e.flush()
+	return e
+}
+
+func (e *eventWriter) message(m proto.Message) error {
+	data, err := marshalOptions.Marshal(m)
+	if err != nil {
+		return e.error(status.Errorf(codes.Internal, "cannot encode response: %v", err))
+	}
+	if e.sse {
+		return e.write("data: %s\n\n", data)
+	}
+	return e.write("{\"result\":%s}\n", data)
+}
+
+func (e *eventWriter) error(err error) error {
+	data := statusJSON(status.Convert(err))
+	if e.sse {
+		return e.write("event: error\ndata: %s\n\n", data)
+	}
+	return e.write("{\"error\":%s}\n", data)
+}
+
+func (e *eventWriter) write(format string, data []byte) error {
+	if _, err := fmt.Fprintf(e.w, format, data); err != nil {
+		return err
+	}
+	e.flush()
+	return nil
+}
+
+func (e *eventWriter) flush() {
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// wsPingInterval is how often idle WebSockets are pinged
+	wsPingInterval = 30 * time.Second
+	// wsPongWait is how long a WebSocket may go without any message or pong before it is dropped
+	wsPongWait = 2 * wsPingInterval
+	// wsWriteWait is how long a write to a WebSocket may block
+	wsWriteWait = 10 * time.Second
+)
+
+// wsClientMessage is a text message sent by the client, either a request or,
+// once it has sent all of them, {"close_send": true}. The responses keep coming
+// until the server ends the stream
+type wsClientMessage struct {
+	Request   json.RawMessage `json:"request"`
+	CloseSend bool            `json:"close_send"`
+}
+
+// serveWebSocket bridges a WebSocket to a stream. Each response is sent as
+// {"result": ...}, a failed call ends with {"error": ...}, then the gateway closes
+// the socket with the code closeCode picks for the outcome of the call.
+//
+// Nothing is buffered: a slow client stops the gateway from reading responses, which
+// holds the service back through gRPC flow control, and a slow service stops the gateway
+// from reading the socket. Closing the socket cancels the call
+func (g *Gateway) serveWebSocket(ctx context.Context, rt *route, w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: g.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the HTTP error
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxBodySize)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := rt.conn.NewStream(ctx, &grpc.StreamDesc{
+		ClientStreams: rt.method.IsStreamingClient(),
+		ServerStreams: rt.method.IsStreamingServer(),
+	}, rt.RPC)
+	if err != nil {
+		closeWebSocket(conn, err)
+		return
+	}
+
+	// the reader reports why it aborted the call, so the client gets that error
+	// instead of the cancellation it causes
+	abort := make(chan error, 1)
+	go func() {
+		if err := readWebSocket(conn, rt, stream); err != nil {
+			abort <- err
+			cancel()
+		}
+	}()
+	go pingWebSocket(ctx, conn)
+
+	for {
+		res := dynamicpb.NewMessage(rt.method.Output())
+		if err = stream.RecvMsg(res); err != nil {
+			break
+		}
+		data, merr := marshalOptions.Marshal(res)
+		if merr != nil {
+			err = status.Errorf(codes.Internal, "cannot encode response: %v", merr)
+			break
+		}
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if werr := conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("{\"result\":%s}", data))); werr != nil {
+			// the client is gone, the deferred cancel ends the call
+			return
+		}
+	}
+
+	if err == io.EOF {
+		err = nil
+	} else {
+		select {
+		case reason := <-abort:
+			if _, ok := status.FromError(reason); ok {
+				err = reason
+			}
+		default:
+		}
+	}
+	closeWebSocket(conn, err)
+}
+
+// readWebSocket sends the requests read from conn until the client half-closes.
+// It returns an error, a status for bad requests, when the call must be aborted
+func readWebSocket(conn *websocket.Conn, rt *route, stream grpc.ClientStream) error {
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	closed := false
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			if closed && websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				// the client acknowledged our close, the call is over
+				return nil
+			}
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		if kind != websocket.TextMessage {
+			return status.Error(codes.InvalidArgument, "only text messages are supported")
+		}
+		if closed {
+			return status.Error(codes.InvalidArgument, "message sent after close_send")
+		}
+		var msg wsClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid message: %v", err)
+		}
+		if msg.CloseSend {
+			closed = true
+			stream.CloseSend()
+			continue
+		}
+
+		req := dynamicpb.NewMessage(rt.method.Input())
+		if err := protojson.Unmarshal(msg.Request, req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+		}
+		if err := stream.SendMsg(req); err != nil {
+			// the stream is over, its error comes back from RecvMsg
+			closed = true
+		}
+	}
+}
+
+// pingWebSocket keeps idle connections alive until ctx is done
+func pingWebSocket(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// closeWebSocket sends the error of the call, if any, and closes the socket
+func closeWebSocket(conn *websocket.Conn, err error) {
+	st := status.Convert(err)
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err != nil {
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("{\"error\":%s}", statusJSON(st))))
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode(st.Code()), ""), time.Now().Add(wsWriteWait))
+}
+
+// closeCode is the WebSocket close code for the outcome of a call: 1000 when it succeeded,
+// 1007 for invalid requests, 1008 for the other errors caused by the client, like
+// missing credentials, and 1011 when the server failed
+func closeCode(code codes.Code) int {
+	switch {
+	case code == codes.OK:
+		return websocket.CloseNormalClosure
+	case code == codes.InvalidArgument:
+		return websocket.CloseInvalidFramePayloadData
+	case HTTPStatus(code) < http.StatusInternalServerError:
+		return websocket.ClosePolicyViolation
+	}
+	return websocket.CloseInternalServerErr
+}
@@ -0,0 +1,232 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/gateway"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maximumProbe is a FindMaximum that echoes every number until the client half-closes,
+// then holds the call open until it is canceled. A negative number fails the call
+// with the code -number
+type maximumProbe struct {
+	calculatorpb.UnimplementedCalculatorServiceServer
+	// canceled is closed once the call is canceled
+	canceled chan struct{}
+}
+
+func (p *maximumProbe) FindMaximum(stream grpc.BidiStreamingServer[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if req.GetNumber() < 0 {
+			return status.Error(codes.Code(-req.GetNumber()), "the probe failed")
+		}
+		if err := stream.Send(&calculatorpb.FindMaximumResponse{Maximum: req.GetNumber()}); err != nil {
+			break
+		}
+	}
+	<-stream.Context().Done()
+	close(p.canceled)
+	return status.FromContextError(stream.Context().Err()).Err()
+}
+
+// startProbe serves the default routes in front of a maximumProbe
+func startProbe(t *testing.T) (*httptest.Server, *maximumProbe) {
+	t.Helper()
+	probe := &maximumProbe{canceled: make(chan struct{})}
+	s := servertest.Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, probe)
+	})
+	conns := map[string]grpc.ClientConnInterface{calcclient.ServiceName: s.Dial(t, []string{calcclient.ServiceName})}
+	gw, err := gateway.New(conns, gateway.DefaultRoutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(gw)
+	t.Cleanup(srv.Close)
+	return srv, probe
+}
+
+func dialWebSocket(t *testing.T, url, path string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// writeText sends every message as a text message
+func writeText(t *testing.T, conn *websocket.Conn, messages ...string) {
+	t.Helper()
+	for _, m := range messages {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readUntilClose reads the messages of the gateway, compacted, until it closes the socket
+func readUntilClose(t *testing.T, conn *websocket.Conn) ([]string, int) {
+	t.Helper()
+	var messages []string
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, data, err := conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return messages, closeErr.Code
+		}
+		if err != nil {
+			t.Fatalf("read error = %v, want a close", err)
+		}
+		messages = append(messages, compactJSON(t, string(data)))
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	srv := startGateway(t, bootstrap.Config{}, nil, gateway.CORSConfig{})
+
+	tests := []struct {
+		name string
+		path string
+		send []string
+		want []string
+	}{
+		{
+			name: "GreetEveryone",
+			path: "/v1/greet/everyone",
+			send: []string{`{"request": {"greeting": {"first_name": "Angel"}}}`, `{"request": {"greeting": {"first_name": "Ana"}}}`, `{"close_send": true}`},
+			want: []string{`{"result":{"result":"Hello Angel! "}}`, `{"result":{"result":"Hello Ana! "}}`},
+		},
+		{
+			name: "FindMaximum",
+			path: "/v1/calculator/maximum",
+			send: []string{`{"request": {"number": 1}}`, `{"request": {"number": 5}}`, `{"request": {"number": 3}}`, `{"request": {"number": 7}}`, `{"close_send": true}`},
+			want: []string{`{"result":{"maximum":1}}`, `{"result":{"maximum":5}}`, `{"result":{"maximum":7}}`},
+		},
+		{
+			name: "no requests",
+			path: "/v1/calculator/maximum",
+			send: []string{`{"close_send": true}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dialWebSocket(t, srv.URL, tt.path)
+			writeText(t, conn, tt.send...)
+			got, code := readUntilClose(t, conn)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+			if code != websocket.CloseNormalClosure {
+				t.Errorf("close code = %v, want %v", code, websocket.CloseNormalClosure)
+			}
+		})
+	}
+}
+
+func TestWebSocketClientCloses(t *testing.T) {
+	srv, probe := startProbe(t)
+	conn := dialWebSocket(t, srv.URL, "/v1/calculator/maximum")
+	writeText(t, conn, `{"request": {"number": 5}}`)
+	if _, data, err := conn.ReadMessage(); err != nil || compactJSON(t, string(data)) != `{"result":{"maximum":5}}` {
+		t.Fatalf("first message = %s, %v, want the maximum of 5", data, err)
+	}
+
+	// the client leaves in the middle of the call, without a close_send
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+	conn.Close()
+	select {
+	case <-probe.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call was not canceled when the client left")
+	}
+}
+
+func TestWebSocketErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		// send are text messages, binary is sent after them when set
+		send     []string
+		binary   []byte
+		wantCode codes.Code
+		// wantClose is the WebSocket close code
+		wantClose int
+	}{
+		{name: "binary message", binary: []byte{1, 2, 3}, wantCode: codes.InvalidArgument, wantClose: websocket.CloseInvalidFramePayloadData},
+		{name: "invalid JSON", send: []string{`{"request": `}, wantCode: codes.InvalidArgument, wantClose: websocket.CloseInvalidFramePayloadData},
+		{name: "invalid request", send: []string{`{"request": {"number": "five"}}`}, wantCode: codes.InvalidArgument, wantClose: websocket.CloseInvalidFramePayloadData},
+		{name: "unknown field", send: []string{`{"request": {"divisor": 2}}`}, wantCode: codes.InvalidArgument, wantClose: websocket.CloseInvalidFramePayloadData},
+		{
+			name:      "message after close_send",
+			send:      []string{`{"request": {"number": 1}}`, `{"close_send": true}`, `{"request": {"number": 2}}`},
+			wantCode:  codes.InvalidArgument,
+			wantClose: websocket.CloseInvalidFramePayloadData,
+		},
+		{name: "the service rejects the caller", send: []string{`{"request": {"number": -7}}`}, wantCode: codes.PermissionDenied, wantClose: websocket.ClosePolicyViolation},
+		{name: "the service fails", send: []string{`{"request": {"number": -13}}`}, wantCode: codes.Internal, wantClose: websocket.CloseInternalServerErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, probe := startProbe(t)
+			conn := dialWebSocket(t, srv.URL, "/v1/calculator/maximum")
+			writeText(t, conn, tt.send...)
+			if tt.binary != nil {
+				if err := conn.WriteMessage(websocket.BinaryMessage, tt.binary); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, code := readUntilClose(t, conn)
+			if len(got) == 0 {
+				t.Fatal("no error message before the close")
+			}
+			var last struct {
+				Error *struct{ Code codes.Code }
+			}
+			if err := json.Unmarshal([]byte(got[len(got)-1]), &last); err != nil || last.Error == nil || last.Error.Code != tt.wantCode {
+				t.Errorf("last message = %v, want an error with code %v", got[len(got)-1], tt.wantCode)
+			}
+			if code != tt.wantClose {
+				t.Errorf("close code = %v, want %v", code, tt.wantClose)
+			}
+			if tt.wantCode == codes.InvalidArgument {
+				// the gateway cancels the call it aborted
+				select {
+				case <-probe.canceled:
+				case <-time.After(5 * time.Second):
+					t.Error("the aborted call was not canceled")
+				}
+			}
+		})
+	}
+
+	t.Run("unknown route", func(t *testing.T) {
+		srv, _ := startProbe(t)
+		_, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/greet/everyone", nil)
+		if err == nil {
+			t.Fatal("the gateway upgraded a route without a service")
+		}
+		if res == nil || res.StatusCode != 404 {
+			t.Errorf("response = %v, want 404", res)
+		}
+	})
+}
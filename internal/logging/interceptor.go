@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the metadata key the request ID is read from and sent back in
+const RequestIDKey = "x-request-id"
+
+// UnaryServerInterceptor takes the caller's x-request-id or creates one, sends it back
+// in the response headers, puts a logger tagged with it in the context and
+// writes an access log line once the RPC is done
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, l := start(ctx, info.FullMethod)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, RequestID(ctx)))
+
+		begin := time.Now()
+		res, err := handler(ctx, req)
+		accessLog(ctx, l, begin, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, l := start(ss.Context(), info.FullMethod)
+		ss.SetHeader(metadata.Pairs(RequestIDKey, RequestID(ctx)))
+
+		begin := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		accessLog(ctx, l, begin, err)
+		return err
+	}
+}
+
+// requestIDAttr is the attribute name of the request ID in log lines
+const requestIDAttr = "request_id"
+
+func start(ctx context.Context, method string) (context.Context, *slog.Logger) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDKey); len(ids) > 0 && len(ids[0]) <= 128 {
+			id = ids[0]
+		}
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+
+	l := slog.Default().With(requestIDAttr, id, "method", method)
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return NewContext(ctx, l), l
+}
+
+type requestIDKey struct{}
+
+// RequestID returns the request ID of the RPC in ctx, or "" outside of an RPC
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func accessLog(ctx context.Context, l *slog.Logger, begin time.Time, err error) {
+	code := status.Code(err)
+	attrs := []any{
+		"code", code.String(),
+		"duration_ms", float64(time.Since(begin).Microseconds()) / 1000,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+	if err != nil {
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	case codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted:
+		level = slog.LevelWarn
+	}
+	l.Log(ctx, level, "rpc finished", attrs...)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// serverStream swaps the context of a stream for one carrying the logger
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,78 @@
+// Package logging sets up the structured, leveled logger used by the servers
+// and the interceptors that tag every RPC with a request ID and write an access log line for it
+package logging
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Config selects the log level and format
+type Config struct {
+	// Level is one of debug, info, warn or error
+	Level string
+	// Format is json or text
+	Format string
+}
+
+// RegisterFlags adds the log.level and log.format flags to fs
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Level, "log.level", "info", "log level: debug, info, warn or error")
+	fs.StringVar(&c.Format, "log.format", "json", "log format: json or text")
+}
+
+// Validate checks the config is usable
+func (c Config) Validate() error {
+	if _, err := parseLevel(c.Level); err != nil {
+		return err
+	}
+	if c.Format != "json" && c.Format != "text" {
+		return fmt.Errorf("invalid log.format %q, must be json or text", c.Format)
+	}
+	return nil
+}
+
+// Setup makes a logger for the config the default, for slog and for the log package,
+// every line carries the service name
+func Setup(c Config, service string) error {
+	level, err := parseLevel(c.Level)
+	if err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler = slog.NewJSONHandler(os.Stderr, opts)
+	if c.Format == "text" {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(h).With("service", service))
+	return nil
+}
+
+func parseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(s))); err != nil {
+		return level, fmt.Errorf("invalid log.level %q, must be debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+type loggerKey struct{}
+
+// NewContext returns a context carrying the logger
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger of the RPC, tagged with its request ID and method,
+// or the default logger outside of an RPC
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
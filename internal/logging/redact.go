@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"log/slog"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SensitiveFields are the proto field names whose values never make it into the logs,
+// in requests and in responses. The greet results are in there since they echo the names
+var SensitiveFields = map[protoreflect.Name]bool{
+	"first_name": true,
+	"last_name":  true,
+	"result":     true,
+	"author_id":  true,
+	"content":    true,
+}
+
+// redacted replaces the value of sensitive string fields
+const redacted = "[REDACTED]"
+
+// Proto returns a log attribute with the message as JSON, sensitive fields redacted
+func Proto(key string, m proto.Message) slog.Attr {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return slog.String(key, "null")
+	}
+	clone := proto.Clone(m)
+	Redact(clone.ProtoReflect())
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(clone)
+	if err != nil {
+		return slog.String(key, "<unprintable>")
+	}
+	return slog.Any(key, rawJSON(b))
+}
+
+// Redact clears the sensitive fields of m and of every message nested in it, in place
+func Redact(m protoreflect.Message) {
+	// fields are only changed after Range, a message must not be modified while ranging over it
+	var sensitive []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case SensitiveFields[fd.Name()]:
+			sensitive = append(sensitive, fd)
+		case fd.Message() != nil && fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				Redact(list.Get(i).Message())
+			}
+		case fd.Message() != nil && fd.IsMap():
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				if fd.MapValue().Message() != nil {
+					Redact(mv.Message())
+				}
+				return true
+			})
+		case fd.Message() != nil:
+			Redact(v.Message())
+		}
+		return true
+	})
+
+	for _, fd := range sensitive {
+		if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+			m.Set(fd, protoreflect.ValueOfString(redacted))
+		} else {
+			m.Clear(fd)
+		}
+	}
+}
+
+// rawJSON is logged as-is by the JSON handler instead of as a quoted string
+type rawJSON []byte
+
+func (r rawJSON) MarshalJSON() ([]byte, error) {
+	return r, nil
+}
+
+func (r rawJSON) String() string {
+	return string(r)
+}
@@ -0,0 +1,155 @@
+// Package metrics collects Prometheus metrics for the gRPC servers: per-method call counts,
+// status codes, latency, in-flight calls and stream messages, and serves them on /metrics
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics holds the gRPC server collectors and the registry they are registered on
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	started  *prometheus.CounterVec
+	handled  *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+	received *prometheus.CounterVec
+	sent     *prometheus.CounterVec
+}
+
+// New creates the gRPC server metrics on a new registry, along with the Go runtime and process metrics
+func New() *Metrics {
+	labels := []string{"grpc_service", "grpc_method", "grpc_type"}
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_started_total",
+			Help: "Total number of RPCs started on the server.",
+		}, labels),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, by status code.",
+		}, append(labels, "grpc_code")),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Time taken by the server to handle RPCs.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_server_in_flight",
+			Help: "Number of RPCs currently being handled.",
+		}, labels),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Total number of stream messages received from clients.",
+		}, labels),
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Total number of stream messages sent to clients.",
+		}, labels),
+	}
+
+	m.Registry.MustRegister(
+		m.started, m.handled, m.latency, m.inFlight, m.received, m.sent,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// UnaryServerInterceptor records the metrics of unary RPCs
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		labels := methodLabels(info.FullMethod, "unary")
+		done := m.start(labels)
+		res, err := handler(ctx, req)
+		done(err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor records the metrics of streaming RPCs, including messages sent and received
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		labels := methodLabels(info.FullMethod, streamType(info))
+		done := m.start(labels)
+		err := handler(srv, &countingStream{
+			ServerStream: ss,
+			received:     m.received.With(labels),
+			sent:         m.sent.With(labels),
+		})
+		done(err)
+		return err
+	}
+}
+
+// start counts an RPC as started and in flight, the returned func records its result
+func (m *Metrics) start(labels prometheus.Labels) func(err error) {
+	begin := time.Now()
+	m.started.With(labels).Inc()
+	m.inFlight.With(labels).Inc()
+
+	return func(err error) {
+		m.inFlight.With(labels).Dec()
+		m.latency.With(labels).Observe(time.Since(begin).Seconds())
+
+		handled := prometheus.Labels{"grpc_code": status.Code(err).String()}
+		for k, v := range labels {
+			handled[k] = v
+		}
+		m.handled.With(handled).Inc()
+	}
+}
+
+// countingStream counts the messages that go through a stream
+type countingStream struct {
+	grpc.ServerStream
+	received prometheus.Counter
+	sent     prometheus.Counter
+}
+
+func (s *countingStream) RecvMsg(msg interface{}) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.received.Inc()
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(msg interface{}) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+// methodLabels splits "/greet.GreetService/Greet" into its service and method labels
+func methodLabels(fullMethod, rpcType string) prometheus.Labels {
+	service, method := "unknown", "unknown"
+	parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)
+	if len(parts) == 2 {
+		service, method = parts[0], parts[1]
+	}
+	return prometheus.Labels{"grpc_service": service, "grpc_method": method, "grpc_type": rpcType}
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	case info.IsServerStream:
+		return "server_stream"
+	}
+	return "unary"
+}
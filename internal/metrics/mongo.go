@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// NewMongoMonitor returns a MongoDB command monitor that records the latency of every
+// operation as mongo_operation_duration_seconds, by command and result.
+// Pass it to options.Client().SetMonitor
+func NewMongoMonitor(reg prometheus.Registerer) *event.CommandMonitor {
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongo_operation_duration_seconds",
+		Help:    "Time taken by MongoDB operations.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"command", "result"})
+	reg.MustRegister(latency)
+
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			latency.WithLabelValues(e.CommandName, "success").Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			latency.WithLabelValues(e.CommandName, "failure").Observe(e.Duration.Seconds())
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limit is a token bucket, Rate tokens per second up to Burst. A zero Rate means no limit
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Config holds the call and stream message limits, each client gets its own buckets
+type Config struct {
+	// Calls limits how often a client may start an RPC, per method
+	Calls Limit
+	// Messages limits how fast a client may send messages on streams, per method
+	Messages Limit
+	// Methods overrides Calls for single methods
+	Methods MethodLimits
+}
+
+// RegisterFlags adds the ratelimit.* flags to fs
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&c.Calls.Rate, "ratelimit.rps", 0, "RPCs per second per client and method, 0 disables rate limiting")
+	fs.IntVar(&c.Calls.Burst, "ratelimit.burst", 20, "burst of RPCs allowed above ratelimit.rps")
+	fs.Float64Var(&c.Messages.Rate, "ratelimit.stream-rps", 0, "stream messages per second per client and method, 0 disables")
+	fs.IntVar(&c.Messages.Burst, "ratelimit.stream-burst", 50, "burst of stream messages allowed above ratelimit.stream-rps")
+	c.Methods = MethodLimits{}
+	fs.Var(c.Methods, "ratelimit.methods", "per method RPC limits, e.g. /calculator.CalculatorService/Sum=5:10 for 5 rps with a burst of 10")
+}
+
+// Enabled reports whether any limit is set
+func (c Config) Enabled() bool {
+	return c.Calls.Rate > 0 || c.Messages.Rate > 0 || len(c.Methods) > 0
+}
+
+// Validate checks the config is usable
+func (c Config) Validate() error {
+	for _, l := range append([]Limit{c.Calls, c.Messages}, c.Methods.limits()...) {
+		if l.Rate < 0 || l.Burst < 0 {
+			return fmt.Errorf("rate limits must not be negative")
+		}
+		if l.Rate > 0 && l.Burst == 0 {
+			return fmt.Errorf("a rate limit needs a burst of at least 1")
+		}
+	}
+	return nil
+}
+
+// callLimit returns the limit for starting the given method
+func (c Config) callLimit(fullMethod string) Limit {
+	if l, ok := c.Methods[fullMethod]; ok {
+		return l
+	}
+	return c.Calls
+}
+
+// MethodLimits maps full method names to their limit, it is a flag.Value
+// that parses "method=rate:burst" pairs separated by commas
+type MethodLimits map[string]Limit
+
+// String implements flag.Value
+func (m MethodLimits) String() string {
+	parts := make([]string, 0, len(m))
+	for method, l := range m {
+		parts = append(parts, fmt.Sprintf("%v=%v:%v", method, l.Rate, l.Burst))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value
+func (m MethodLimits) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, limit, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("%q is not method=rate:burst", pair)
+		}
+		rateStr, burstStr, ok := strings.Cut(limit, ":")
+		if !ok {
+			return fmt.Errorf("%q is not method=rate:burst", pair)
+		}
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rate in %q: %v", pair, err)
+		}
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil {
+			return fmt.Errorf("invalid burst in %q: %v", pair, err)
+		}
+		m[method] = Limit{Rate: rate, Burst: burst}
+	}
+	return nil
+}
+
+func (m MethodLimits) limits() []Limit {
+	limits := make([]Limit, 0, len(m))
+	for _, l := range m {
+		limits = append(limits, l)
+	}
+	return limits
+}
@@ -0,0 +1,235 @@
+// Package ratelimit limits how often each client may call a method and how fast it may
+// send messages on a stream, so one runaway client can't starve the others.
+// Clients are told apart by the principal auth verified, or by their peer address when
+// there is none. Credentials the client merely sends are not trusted, a new key per call
+// would get a new bucket every time. The calls auth rejects are limited per peer address
+// before auth runs, so floods of bad credentials are limited too
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterKey is the trailer that tells a limited client how many seconds to wait
+const RetryAfterKey = "retry-after"
+
+// maxMessageWait is how long a stream message is held back before the stream
+// is failed instead, small bursts over the limit are slowed down rather than rejected
+const maxMessageWait = time.Second
+
+// idleTimeout is how long the buckets of a client that stopped calling are kept
+const idleTimeout = 10 * time.Minute
+
+// Limiter keeps a token bucket per client and method
+type Limiter struct {
+	config Config
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// New returns a Limiter for the config
+func New(c Config) *Limiter {
+	return &Limiter{
+		config:    c,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// UnaryServerInterceptor rejects calls over the limit with ResourceExhausted and a retry-after trailer
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if wait, ok := l.allow(ctx, "call", info.FullMethod, l.config.callLimit(info.FullMethod)); !ok {
+			grpc.SetTrailer(ctx, retryAfter(wait))
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %v", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor limits opening streams like UnaryServerInterceptor does for calls,
+// and limits the messages each client sends on its streams
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if wait, ok := l.allow(ss.Context(), "call", info.FullMethod, l.config.callLimit(info.FullMethod)); !ok {
+			ss.SetTrailer(retryAfter(wait))
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %v", info.FullMethod)
+		}
+		if l.config.Messages.Rate <= 0 || !info.IsClientStream {
+			return handler(srv, ss)
+		}
+		return handler(srv, &limitedStream{ServerStream: ss, limiter: l, method: info.FullMethod})
+	}
+}
+
+// RejectedUnaryServerInterceptor goes before auth: every call auth rejects takes a token
+// from its peer's bucket, and once that bucket is empty the peer's calls are rejected
+// before their credentials are checked. Calls auth lets through take nothing, so
+// clients behind a shared peer like the gateway aren't limited by its address
+func (l *Limiter) RejectedUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limit := l.config.callLimit(info.FullMethod)
+		if wait, ok := l.allowRejected(ctx, info.FullMethod, limit); !ok {
+			grpc.SetTrailer(ctx, retryAfter(wait))
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %v", info.FullMethod)
+		}
+		res, err := handler(ctx, req)
+		l.countRejected(ctx, info.FullMethod, limit, err)
+		return res, err
+	}
+}
+
+// RejectedStreamServerInterceptor does for streams what RejectedUnaryServerInterceptor does for calls
+func (l *Limiter) RejectedStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		limit := l.config.callLimit(info.FullMethod)
+		if wait, ok := l.allowRejected(ss.Context(), info.FullMethod, limit); !ok {
+			ss.SetTrailer(retryAfter(wait))
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %v", info.FullMethod)
+		}
+		err := handler(srv, ss)
+		l.countRejected(ss.Context(), info.FullMethod, limit, err)
+		return err
+	}
+}
+
+// allowRejected tells whether the peer has a token left for a rejected call, without taking it
+func (l *Limiter) allowRejected(ctx context.Context, method string, limit Limit) (time.Duration, bool) {
+	if limit.Rate <= 0 {
+		return 0, true
+	}
+	b := l.bucket(ctx, "rejected", method, limit)
+	if b.Tokens() >= 1 {
+		return 0, true
+	}
+	r := b.Reserve()
+	defer r.Cancel()
+	return r.Delay(), false
+}
+
+// countRejected takes a token from the peer's bucket when auth rejected the call
+func (l *Limiter) countRejected(ctx context.Context, method string, limit Limit, err error) {
+	if limit.Rate <= 0 {
+		return
+	}
+	if c := status.Code(err); c == codes.Unauthenticated || c == codes.PermissionDenied {
+		l.bucket(ctx, "rejected", method, limit).Allow()
+	}
+}
+
+// limitedStream holds back received messages that are over the message limit
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *Limiter
+	method  string
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	b := s.limiter.bucket(s.Context(), "message", s.method, s.limiter.config.Messages)
+	r := b.Reserve()
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if delay > maxMessageWait {
+		r.Cancel()
+		s.SetTrailer(retryAfter(delay))
+		return status.Errorf(codes.ResourceExhausted, "stream message rate limit exceeded for %v", s.method)
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-s.Context().Done():
+		r.Cancel()
+		return status.FromContextError(s.Context().Err()).Err()
+	}
+}
+
+// allow takes a token from the client's bucket, when there is none it returns how long to wait
+func (l *Limiter) allow(ctx context.Context, kind, method string, limit Limit) (time.Duration, bool) {
+	if limit.Rate <= 0 {
+		return 0, true
+	}
+	r := l.bucket(ctx, kind, method, limit).Reserve()
+	if delay := r.Delay(); delay > 0 {
+		r.Cancel()
+		return delay, false
+	}
+	return 0, true
+}
+
+func (l *Limiter) bucket(ctx context.Context, kind, method string, limit Limit) *rate.Limiter {
+	key := kind + "|" + clientKey(ctx) + "|" + method
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleTimeout {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastUsed) > idleTimeout {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastUsed = now
+	return b.limiter
+}
+
+// clientKey identifies the caller by the principal put in ctx by auth, or by peer host
+// when there is none
+func clientKey(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return "principal:" + p.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "peer:" + host
+	}
+	return "unknown"
+}
+
+func retryAfter(wait time.Duration) metadata.MD {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return metadata.Pairs(RetryAfterKey, strconv.Itoa(seconds))
+}
@@ -0,0 +1,127 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// caller is the context of a call from host, sending apiKey and verified as subject when set
+func caller(host, apiKey, subject string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(host), Port: 40000}})
+	if apiKey != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", apiKey))
+	}
+	if subject != "" {
+		ctx = auth.NewContext(ctx, &auth.Principal{Subject: subject})
+	}
+	return ctx
+}
+
+func TestClientKey(t *testing.T) {
+	tests := []struct {
+		name string
+		// calls are made in order, each one is allowed or not
+		calls []context.Context
+		want  []codes.Code
+	}{
+		{
+			name:  "same peer",
+			calls: []context.Context{caller("10.0.0.1", "", ""), caller("10.0.0.1", "", "")},
+			want:  []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:  "other peers",
+			calls: []context.Context{caller("10.0.0.1", "", ""), caller("10.0.0.2", "", "")},
+			want:  []codes.Code{codes.OK, codes.OK},
+		},
+		{
+			name:  "unverified API keys don't get their own bucket",
+			calls: []context.Context{caller("10.0.0.1", "key-1", ""), caller("10.0.0.1", "key-2", ""), caller("10.0.0.1", "key-3", "")},
+			want:  []codes.Code{codes.OK, codes.ResourceExhausted, codes.ResourceExhausted},
+		},
+		{
+			name:  "principals behind one peer",
+			calls: []context.Context{caller("10.0.0.1", "", "angel"), caller("10.0.0.1", "", "ci"), caller("10.0.0.1", "", "angel")},
+			want:  []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:  "a principal on several peers",
+			calls: []context.Context{caller("10.0.0.1", "", "angel"), caller("10.0.0.2", "", "angel")},
+			want:  []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// one call per hour, without burst
+			l := ratelimit.New(ratelimit.Config{Calls: ratelimit.Limit{Rate: 1.0 / 3600, Burst: 1}})
+			interceptor := l.UnaryServerInterceptor()
+			info := &grpc.UnaryServerInfo{FullMethod: "/calculator.CalculatorService/Sum"}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+			for i, ctx := range tt.calls {
+				// SetTrailer needs a server stream in the context, which a direct call has not
+				ctx = grpc.NewContextWithServerTransportStream(ctx, fakeStream{})
+				_, err := interceptor(ctx, nil, info, handler)
+				if got := status.Code(err); got != tt.want[i] {
+					t.Errorf("call %v: code = %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// fakeStream stands in for the transport stream of a call
+type fakeStream struct{}
+
+func (fakeStream) Method() string                  { return "" }
+func (fakeStream) SetHeader(md metadata.MD) error  { return nil }
+func (fakeStream) SendHeader(md metadata.MD) error { return nil }
+func (fakeStream) SetTrailer(md metadata.MD) error { return nil }
+
+func TestRejectedCalls(t *testing.T) {
+	// one rejected call per hour, without burst
+	l := ratelimit.New(ratelimit.Config{Calls: ratelimit.Limit{Rate: 1.0 / 3600, Burst: 1}})
+	interceptor := l.RejectedUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/blog.BlogService/CreateBlog"}
+	calls := 0
+	handler := func(code codes.Code) grpc.UnaryHandler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return nil, status.Error(code, "")
+		}
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		// code is what auth answers when the call gets to it
+		code      codes.Code
+		want      codes.Code
+		wantCalls int
+	}{
+		{name: "calls auth lets through take no token", ctx: caller("10.0.0.1", "", ""), code: codes.OK, want: codes.OK, wantCalls: 1},
+		{name: "again", ctx: caller("10.0.0.1", "", ""), code: codes.OK, want: codes.OK, wantCalls: 2},
+		{name: "a bad API key", ctx: caller("10.0.0.1", "bad-1", ""), code: codes.Unauthenticated, want: codes.Unauthenticated, wantCalls: 3},
+		{name: "another bad API key is limited before auth", ctx: caller("10.0.0.1", "bad-2", ""), code: codes.Unauthenticated, want: codes.ResourceExhausted, wantCalls: 3},
+		{name: "so is a valid one from that peer", ctx: caller("10.0.0.1", "good", ""), code: codes.OK, want: codes.ResourceExhausted, wantCalls: 3},
+		{name: "other peers", ctx: caller("10.0.0.2", "bad-3", ""), code: codes.PermissionDenied, want: codes.PermissionDenied, wantCalls: 4},
+	}
+	for _, tt := range tests {
+		ctx := grpc.NewContextWithServerTransportStream(tt.ctx, fakeStream{})
+		_, err := interceptor(ctx, nil, info, handler(tt.code))
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%v: code = %v, want %v", tt.name, got, tt.want)
+		}
+		if calls != tt.wantCalls {
+			t.Errorf("%v: auth was called %v times, want %v", tt.name, calls, tt.wantCalls)
+		}
+	}
+}
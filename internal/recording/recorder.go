@@ -0,0 +1,245 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// CredentialKeys are the metadata keys whose values are replaced before they are recorded
+var CredentialKeys = map[string]bool{
+	"authorization": true,
+	"x-api-key":     true,
+	"cookie":        true,
+}
+
+// skippedServices are not recorded, they serve the clients and tools rather than the APIs
+var skippedServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// redacted replaces the values of credentials in the metadata
+const redacted = "[REDACTED]"
+
+// Recorder appends the calls it samples to a file, it is safe for concurrent use
+type Recorder struct {
+	config  Config
+	methods map[string]bool
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// New opens the recording file of c for appending
+func New(c Config) (*Recorder, error) {
+	f, err := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open recording file: %v", err)
+	}
+	r := &Recorder{config: c, f: f, enc: json.NewEncoder(f)}
+	if methods := c.methods(); len(methods) > 0 {
+		r.methods = map[string]bool{}
+		for _, m := range methods {
+			r.methods[m] = true
+		}
+	}
+	return r, nil
+}
+
+// Close closes the file, calls ending afterwards are not recorded
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.f
+	r.f = nil
+	if f == nil {
+		return nil
+	}
+	return f.Close()
+}
+
+// UnaryServerInterceptor records a sample of unary calls
+func (r *Recorder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !r.sample(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		c := r.start(ctx, info.FullMethod)
+		c.add(0, req, true)
+		res, err := handler(ctx, req)
+		if err == nil {
+			c.add(time.Since(c.Start), res, false)
+		}
+		r.finish(c, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor records a sample of streaming calls, with the time every message went through
+func (r *Recorder) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !r.sample(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		c := r.start(ss.Context(), info.FullMethod)
+		err := handler(srv, &recordingStream{ServerStream: ss, call: c})
+		r.finish(c, err)
+		return err
+	}
+}
+
+func (r *Recorder) sample(method string) bool {
+	if r.methods != nil && !r.methods[method] {
+		return false
+	}
+	for _, prefix := range skippedServices {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return r.config.SampleRate >= 1 || rand.Float64() < r.config.SampleRate
+}
+
+func (r *Recorder) start(ctx context.Context, method string) *call {
+	c := &call{
+		Call: Call{
+			Method:    method,
+			Start:     time.Now(),
+			RequestID: logging.RequestID(ctx),
+			Metadata:  recordedMetadata(ctx),
+			Redacted:  r.config.Redact,
+			Requests:  []Message{},
+			Responses: []Message{},
+		},
+		max: r.config.MaxMessages,
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		c.Timeout = time.Until(dl)
+	}
+	return c
+}
+
+func (r *Recorder) finish(c *call, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Duration = time.Since(c.Start)
+	st := status.Convert(err)
+	c.Code, c.Message = st.Code().String(), st.Message()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return
+	}
+	if err := r.enc.Encode(&c.Call); err != nil {
+		slog.Error("failed to record call", "method", c.Method, "error", err)
+	}
+}
+
+// recordedMetadata is the metadata of the call without what the transport adds,
+// credentials are replaced
+func recordedMetadata(ctx context.Context) map[string][]string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	out := map[string][]string{}
+	for k, v := range md {
+		switch {
+		case strings.HasPrefix(k, ":"), strings.HasPrefix(k, "grpc-"), k == "content-type", k == "te":
+		case CredentialKeys[k]:
+			out[k] = []string{redacted}
+		default:
+			out[k] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// call is a call being recorded, the messages of a stream may come from several goroutines
+type call struct {
+	Call
+	max int
+
+	mu sync.Mutex
+}
+
+// add records a request or response, messages past the max only mark the call as truncated
+func (c *call) add(offset time.Duration, msg interface{}, request bool) {
+	body := c.marshal(msg)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := &c.Responses
+	if request {
+		list = &c.Requests
+	}
+	if len(*list) >= c.max {
+		c.Truncated = true
+		return
+	}
+	*list = append(*list, Message{Offset: offset, Body: body})
+}
+
+// marshal returns msg as protojson, after clearing its sensitive fields when the
+// recording is redacted. msg itself is left as is
+func (c *call) marshal(msg interface{}) json.RawMessage {
+	var m proto.Message
+	switch v := msg.(type) {
+	case proto.Message:
+		m = v
+	case protoadapt.MessageV1:
+		m = protoadapt.MessageV2Of(v)
+	default:
+		b, _ := json.Marshal(fmt.Sprintf("<%T>", msg))
+		return b
+	}
+	if c.Redacted {
+		m = proto.Clone(m)
+		logging.Redact(m.ProtoReflect())
+	}
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("<unprintable: %v>", err))
+	}
+	return b
+}
+
+// recordingStream records the messages that go through a stream
+type recordingStream struct {
+	grpc.ServerStream
+	call *call
+}
+
+func (s *recordingStream) RecvMsg(msg interface{}) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.call.add(time.Since(s.call.Start), msg, true)
+	}
+	return err
+}
+
+func (s *recordingStream) SendMsg(msg interface{}) error {
+	offset := time.Since(s.call.Start)
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.call.add(offset, msg, false)
+	}
+	return err
+}
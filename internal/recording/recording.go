@@ -0,0 +1,122 @@
+// Package recording captures the traffic of a server to a file: the requests, responses,
+// metadata and final status of a sample of its calls, with the time every stream message
+// went through. The replay command re-drives a recording against a server to reproduce
+// what happened in production locally
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Config selects which calls are recorded and where to
+type Config struct {
+	// File is where calls are appended, one JSON object per line, empty turns recording off
+	File string
+	// SampleRate is the fraction of calls that are recorded
+	SampleRate float64
+	// Methods limits recording to these full methods, comma separated, empty records every method
+	Methods string
+	// Redact clears the sensitive fields of messages, see logging.SensitiveFields.
+	// Credentials in the metadata are never recorded
+	Redact bool
+	// MaxMessages is how many messages of a stream are recorded, in each direction
+	MaxMessages int
+}
+
+// RegisterFlags adds the record.* flags to fs
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.File, "record.file", "", "file calls are recorded to, one JSON object per line, empty turns recording off")
+	fs.Float64Var(&c.SampleRate, "record.sample-rate", 1, "fraction of calls that are recorded")
+	fs.StringVar(&c.Methods, "record.methods", "", "full methods to record, comma separated, e.g. /calculator.CalculatorService/Sum; empty records all")
+	fs.BoolVar(&c.Redact, "record.redact", true, "clear the sensitive fields of recorded messages, e.g. names and blog content")
+	fs.IntVar(&c.MaxMessages, "record.max-messages", 1000, "how many messages of a stream are recorded in each direction")
+}
+
+// Enabled reports whether calls are recorded
+func (c Config) Enabled() bool {
+	return c.File != ""
+}
+
+// Validate checks the config is usable
+func (c Config) Validate() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("record.sample-rate must be between 0 and 1, got %v", c.SampleRate)
+	}
+	if c.MaxMessages < 1 {
+		return fmt.Errorf("record.max-messages must be at least 1, got %v", c.MaxMessages)
+	}
+	for _, m := range c.methods() {
+		if !strings.HasPrefix(m, "/") || strings.Count(m, "/") != 2 {
+			return fmt.Errorf("invalid method %q in record.methods, must be like /calculator.CalculatorService/Sum", m)
+		}
+	}
+	return nil
+}
+
+func (c Config) methods() []string {
+	var out []string
+	for _, m := range strings.Split(c.Methods, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Call is one recorded call, a line of the recording
+type Call struct {
+	// Method is the full method, e.g. /calculator.CalculatorService/Sum
+	Method string `json:"method"`
+	// Start is when the server got the call
+	Start time.Time `json:"start"`
+	// Timeout is what was left of the caller's deadline when the call started, 0 for none
+	Timeout time.Duration `json:"timeout_ns,omitempty"`
+	// RequestID matches the call with its access log line
+	RequestID string              `json:"request_id,omitempty"`
+	Metadata  map[string][]string `json:"metadata,omitempty"`
+	// Redacted is set when the sensitive fields of the messages were cleared
+	Redacted  bool      `json:"redacted,omitempty"`
+	Requests  []Message `json:"requests"`
+	Responses []Message `json:"responses"`
+	// Truncated is set when a stream had more messages than were recorded
+	Truncated bool `json:"truncated,omitempty"`
+	// Code and Message are the final status of the call
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	// Duration is how long the server took to handle the call
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Message is a request or response as protojson, Offset is when it went through
+// from the start of the call
+type Message struct {
+	Offset time.Duration   `json:"offset_ns"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// maxLine is the longest line Read accepts
+const maxLine = 64 << 20
+
+// Read returns every call of a recording, in the order they ended
+func Read(r io.Reader) ([]Call, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxLine)
+	var calls []Call
+	for line := 1; sc.Scan(); line++ {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		var c Call
+		if err := json.Unmarshal(sc.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		calls = append(calls, c)
+	}
+	return calls, sc.Err()
+}
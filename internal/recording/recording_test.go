@@ -0,0 +1,210 @@
+package recording_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/greet/greetclient"
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/greet/greetservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/recording"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// record makes the same calls against a server recording with cfg and returns what it recorded
+func record(t *testing.T, cfg recording.Config) []recording.Call {
+	t.Helper()
+	cfg.File = filepath.Join(t.TempDir(), "calls.jsonl")
+	if cfg.MaxMessages == 0 {
+		cfg.MaxMessages = 1000
+	}
+	s := servertest.Start(t, bootstrap.Config{Record: cfg}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+		greetpb.RegisterGreetServiceServer(s.GRPC, greetservice.NewServer())
+	})
+	calc := calcclient.New(s.Dial(t, []string{calcclient.ServiceName}))
+	greet := greetclient.New(s.Dial(t, []string{greetclient.ServiceName}))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret", "x-tenant", "acme")
+	if _, err := calc.Sum(ctx, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := calc.PrimeFactors(ctx, 12, func(int64) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calc.SquareRoot(ctx, -4); err == nil {
+		t.Fatal("SquareRoot(-4) did not fail")
+	}
+	if _, err := greet.Greet(ctx, &greetpb.Greeting{FirstName: "Angel", LastName: "Dionisio"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// calls are written before their status goes out, so they are all in the file by now
+	f, err := os.Open(cfg.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	calls, err := recording.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return calls
+}
+
+func bodies(msgs []recording.Message) []string {
+	out := []string{}
+	for _, m := range msgs {
+		var buf bytes.Buffer
+		json.Compact(&buf, m.Body)
+		out = append(out, buf.String())
+	}
+	return out
+}
+
+func methods(calls []recording.Call) []string {
+	out := []string{}
+	for _, c := range calls {
+		out = append(out, c.Method)
+	}
+	return out
+}
+
+func TestRecord(t *testing.T) {
+	calls := record(t, recording.Config{SampleRate: 1, Redact: true})
+	want := []string{
+		"/calculator.CalculatorService/Sum",
+		"/calculator.CalculatorService/PrimeNumberDecomposition",
+		"/calculator.CalculatorService/SquareRoot",
+		"/greet.GreetService/Greet",
+	}
+	if got := methods(calls); !slices.Equal(got, want) {
+		t.Fatalf("recorded %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		call          recording.Call
+		wantRequests  []string
+		wantResponses []string
+		wantCode      string
+	}{
+		{calls[0], []string{`{"first_number":3,"second_number":4}`}, []string{`{"sum_result":7}`}, "OK"},
+		{calls[1], []string{`{"number":"12"}`}, []string{`{"prime_factor":"2"}`, `{"prime_factor":"2"}`, `{"prime_factor":"3"}`}, "OK"},
+		{calls[2], []string{`{"number":-4}`}, []string{}, "InvalidArgument"},
+		{calls[3], []string{`{"greeting":{"first_name":"[REDACTED]","last_name":"[REDACTED]"}}`}, []string{`{"result":"[REDACTED]"}`}, "OK"},
+	}
+	for _, tt := range tests {
+		c := tt.call
+		if got := bodies(c.Requests); !slices.Equal(got, tt.wantRequests) {
+			t.Errorf("%v requests = %v, want %v", c.Method, got, tt.wantRequests)
+		}
+		if got := bodies(c.Responses); !slices.Equal(got, tt.wantResponses) {
+			t.Errorf("%v responses = %v, want %v", c.Method, got, tt.wantResponses)
+		}
+		if c.Code != tt.wantCode {
+			t.Errorf("%v code = %v, want %v", c.Method, c.Code, tt.wantCode)
+		}
+		if c.RequestID == "" || c.Start.IsZero() || c.Duration <= 0 || !c.Redacted {
+			t.Errorf("%v has no request ID, start, duration or redacted flag: %+v", c.Method, c)
+		}
+		if !slices.Equal(c.Metadata["x-api-key"], []string{"[REDACTED]"}) || !slices.Equal(c.Metadata["x-tenant"], []string{"acme"}) {
+			t.Errorf("%v metadata = %v, want the API key redacted and x-tenant kept", c.Method, c.Metadata)
+		}
+		if _, ok := c.Metadata[":authority"]; ok {
+			t.Errorf("%v metadata has the :authority of the transport", c.Method)
+		}
+	}
+}
+
+func TestRecordRedactsResponses(t *testing.T) {
+	cfg := recording.Config{File: filepath.Join(t.TempDir(), "calls.jsonl"), SampleRate: 1, Redact: true, MaxMessages: 10}
+	r, err := recording.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blog := &blogpb.Blog{Id: "b1", AuthorId: "angel", Title: "Hello", Content: "about me"}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return &blogpb.CreateBlogResponse{Blog: blog}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/blog.BlogService/CreateBlog"}
+	if _, err := r.UnaryServerInterceptor()(context.Background(), &blogpb.CreateBlogRequest{Blog: blog}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(cfg.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	calls, err := recording.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("recorded %v calls, want 1", len(calls))
+	}
+	want := []string{`{"blog":{"id":"b1","author_id":"[REDACTED]","title":"Hello","content":"[REDACTED]"}}`}
+	if got := bodies(calls[0].Responses); !slices.Equal(got, want) {
+		t.Errorf("responses = %v, want %v", got, want)
+	}
+	if blog.Content != "about me" || blog.AuthorId != "angel" {
+		t.Errorf("recording changed the response that went out: %v", blog)
+	}
+}
+
+func TestRecordSelection(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         recording.Config
+		wantMethods []string
+		wantGreet   string
+	}{
+		{
+			name:        "nothing sampled",
+			cfg:         recording.Config{SampleRate: 0},
+			wantMethods: []string{},
+		},
+		{
+			name:        "some methods",
+			cfg:         recording.Config{SampleRate: 1, Methods: "/calculator.CalculatorService/Sum, /greet.GreetService/Greet"},
+			wantMethods: []string{"/calculator.CalculatorService/Sum", "/greet.GreetService/Greet"},
+			wantGreet:   `{"greeting":{"first_name":"Angel","last_name":"Dionisio"}}`,
+		},
+		{
+			name:        "stream truncated",
+			cfg:         recording.Config{SampleRate: 1, Methods: "/calculator.CalculatorService/PrimeNumberDecomposition", MaxMessages: 2},
+			wantMethods: []string{"/calculator.CalculatorService/PrimeNumberDecomposition"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := record(t, tt.cfg)
+			if got := methods(calls); !slices.Equal(got, tt.wantMethods) {
+				t.Fatalf("recorded %v, want %v", got, tt.wantMethods)
+			}
+			for _, c := range calls {
+				if c.Method == "/greet.GreetService/Greet" && bodies(c.Requests)[0] != tt.wantGreet {
+					t.Errorf("Greet request = %v, want %v", bodies(c.Requests), tt.wantGreet)
+				}
+				if tt.cfg.MaxMessages > 0 && (len(c.Responses) != tt.cfg.MaxMessages || !c.Truncated) {
+					t.Errorf("%v has %v responses, truncated %v, want %v and truncated", c.Method, len(c.Responses), c.Truncated, tt.cfg.MaxMessages)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package recovery turns panics in RPC handlers into Internal errors,
+// so one bad request can't take the whole server down
+package recovery
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor recovers from panics in unary handlers, logging the stack
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor recovers from panics in stream handlers, logging the stack
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs the panic with its stack, the caller only gets a generic error
+func recovered(ctx context.Context, r interface{}) error {
+	logging.FromContext(ctx).Error("panic in handler", "panic", r, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal server error")
+}
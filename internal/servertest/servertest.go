@@ -0,0 +1,132 @@
+// Package servertest runs the greet, calculator and blog servers in process over bufconn,
+// with the interceptors of package bootstrap, and hands out clients connected to them:
+//
+//	c := servertest.Calculator(t)
+//	sum, err := c.Sum(ctx, 3, 4)
+//
+// Servers and connections are closed when the test ends
+package servertest
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/blog/blogclient"
+	"github.com/angel/golang_api_microservice/blog/blogpb"
+	"github.com/angel/golang_api_microservice/blog/blogservice"
+	"github.com/angel/golang_api_microservice/blog/moderation"
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/greet/greetclient"
+	"github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/greet/greetservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/sdk"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is the buffer of the in-memory connections
+const bufSize = 1 << 20
+
+// Server is a bootstrap.Server listening on bufconn
+type Server struct {
+	*bootstrap.Server
+	lis *bufconn.Listener
+}
+
+// Start starts a server with the services register adds to it. cfg only needs what the
+// test cares about, e.g. Auth or RateLimit; the name defaults to "test" and the metrics
+// HTTP server is off
+func Start(t testing.TB, cfg bootstrap.Config, register func(s *bootstrap.Server), opts ...bootstrap.Option) *Server {
+	t.Helper()
+	if cfg.Name == "" {
+		cfg.Name = "test"
+	}
+	if cfg.DrainTimeout == 0 {
+		cfg.DrainTimeout = time.Second
+	}
+	cfg.MetricsAddr = ""
+
+	bs, err := bootstrap.New(cfg, opts...)
+	if err != nil {
+		t.Fatalf("could not create server: %v", err)
+	}
+	register(bs)
+
+	s := &Server{Server: bs, lis: bufconn.Listen(bufSize)}
+	stop := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- bs.Serve(s.lis, stop)
+	}()
+	t.Cleanup(func() {
+		stop <- os.Interrupt
+		if err := <-done; err != nil {
+			t.Errorf("server stopped with an error: %v", err)
+		}
+	})
+	return s
+}
+
+// Dial connects to the server for services with sdk.Dial, opts come after the ones
+// reaching bufconn
+func (s *Server) Dial(t testing.TB, services []string, opts ...sdk.Option) *grpc.ClientConn {
+	t.Helper()
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.lis.DialContext(ctx)
+	})
+	cc, err := sdk.Dial("passthrough:///bufconn", services, append([]sdk.Option{sdk.WithDialOptions(dialer)}, opts...)...)
+	if err != nil {
+		t.Fatalf("could not dial the server: %v", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+// DialContext opens a bufconn connection to the server, e.g. for a dialer that puts
+// several servers behind one target
+func (s *Server) DialContext(ctx context.Context) (net.Conn, error) {
+	return s.lis.DialContext(ctx)
+}
+
+// Greet starts a greet server, e.g. with greetservice.WithClock, and returns a client of it
+func Greet(t testing.TB, opts ...greetservice.Option) *greetclient.Client {
+	t.Helper()
+	s := Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		greetpb.RegisterGreetServiceServer(s.GRPC, greetservice.NewServer(opts...))
+	})
+	return greetclient.New(s.Dial(t, []string{greetclient.ServiceName}))
+}
+
+// Calculator starts a calculator server and returns a client of it
+func Calculator(t testing.TB) *calcclient.Client {
+	t.Helper()
+	s := Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+	})
+	return calcclient.New(s.Dial(t, []string{calcclient.ServiceName}))
+}
+
+// Blog starts a blog server storing blogs in collection and returns a client of it.
+// Without a MongoDB at hand, collection can come from a mock deployment of the driver's
+// mtest package
+func Blog(t testing.TB, collection *mongo.Collection, moderator moderation.Moderator) *blogclient.Client {
+	t.Helper()
+	s := StartBlog(t, bootstrap.Config{}, collection, moderator)
+	return blogclient.New(s.Dial(t, []string{blogclient.ServiceName}))
+}
+
+// StartBlog starts a blog server with cfg and the blog auth policy, e.g. to test it
+// with auth on. Clients are made with Dial
+func StartBlog(t testing.TB, cfg bootstrap.Config, collection *mongo.Collection, moderator moderation.Moderator) *Server {
+	t.Helper()
+	return Start(t, cfg, func(s *bootstrap.Server) {
+		blogpb.RegisterBlogServiceServer(s.GRPC, blogservice.NewServer(collection, moderator))
+	}, bootstrap.WithAuthPolicy(blogservice.AuthPolicy))
+}
@@ -0,0 +1,63 @@
+// Package shutdown lets long running handlers know the server is draining,
+// so a stream can stop early and return cleanly instead of being cut off
+// when the drain timeout runs out
+package shutdown
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// Signal is closed once when the server starts draining
+type Signal struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+// NewSignal returns an open Signal
+func NewSignal() *Signal {
+	return &Signal{ch: make(chan struct{})}
+}
+
+// Trigger closes the signal, it is safe to call more than once
+func (s *Signal) Trigger() {
+	s.once.Do(func() {
+		close(s.ch)
+	})
+}
+
+type signalKey struct{}
+
+// Draining returns a channel that is closed when the server handling the RPC in ctx
+// starts shutting down. Outside of a server it returns nil, which blocks forever in a select
+func Draining(ctx context.Context) <-chan struct{} {
+	if s, ok := ctx.Value(signalKey{}).(*Signal); ok {
+		return s.ch
+	}
+	return nil
+}
+
+// UnaryServerInterceptor makes the signal available to handlers through Draining
+func (s *Signal) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(context.WithValue(ctx, signalKey{}, s), req)
+	}
+}
+
+// StreamServerInterceptor makes the signal available to stream handlers through Draining
+func (s *Signal) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), signalKey{}, s)})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
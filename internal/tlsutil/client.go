@@ -0,0 +1,81 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ClientConfig is the TLS setup of a client, the connection is insecure when CAFile is empty
+type ClientConfig struct {
+	// CAFile is the PEM bundle used to verify the server certificate
+	CAFile string
+	// CertFile and KeyFile are the client certificate sent for mutual TLS
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name checked against the server certificate
+	ServerName string
+}
+
+// RegisterFlags adds the tls.ca, tls.cert, tls.key and tls.server-name flags to fs
+func (c *ClientConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.CAFile, "tls.ca", "", "PEM CA bundle to verify the server, enables TLS")
+	fs.StringVar(&c.CertFile, "tls.cert", "", "PEM client certificate for mutual TLS")
+	fs.StringVar(&c.KeyFile, "tls.key", "", "PEM private key file for tls.cert")
+	fs.StringVar(&c.ServerName, "tls.server-name", "", "server name to verify, defaults to the dial host")
+}
+
+// Enabled reports whether the client should use TLS
+func (c ClientConfig) Enabled() bool {
+	return c.CAFile != ""
+}
+
+// Validate checks the config is usable
+func (c ClientConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("tls.cert and tls.key must be set together")
+	}
+	if !c.Enabled() && c.CertFile != "" {
+		return fmt.Errorf("tls.cert needs tls.ca")
+	}
+	return nil
+}
+
+// DialOption returns the transport credentials for the config,
+// plain text when TLS is not enabled
+func (c ClientConfig) DialOption() (grpc.DialOption, error) {
+	if !c.Enabled() {
+		return grpc.WithInsecure(), nil
+	}
+
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// TLSConfig returns the tls.Config for the client
+func (c ClientConfig) TLSConfig() (*tls.Config, error) {
+	pool, err := loadPool(c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+		ServerName: c.ServerName,
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,188 @@
+// Package tlsutil builds TLS credentials for the gRPC servers and clients.
+// Servers reload their certificate and client CA bundle when the files change on disk,
+// so certificates can be rotated without a restart
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ServerConfig is the TLS setup of a server, TLS is off when CertFile is empty
+type ServerConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile turns on mutual TLS, client certificates must be signed by a CA in this bundle
+	ClientCAFile string
+}
+
+// RegisterFlags adds the tls.cert, tls.key and tls.client-ca flags to fs
+func (c *ServerConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.CertFile, "tls.cert", "", "PEM certificate file, enables TLS")
+	fs.StringVar(&c.KeyFile, "tls.key", "", "PEM private key file for tls.cert")
+	fs.StringVar(&c.ClientCAFile, "tls.client-ca", "", "PEM CA bundle, enables mutual TLS")
+}
+
+// Enabled reports whether the server should use TLS
+func (c ServerConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// Validate checks the config is usable
+func (c ServerConfig) Validate() error {
+	if !c.Enabled() {
+		if c.KeyFile != "" || c.ClientCAFile != "" {
+			return fmt.Errorf("tls.key and tls.client-ca need tls.cert")
+		}
+		return nil
+	}
+	if c.KeyFile == "" {
+		return fmt.Errorf("tls.cert needs tls.key")
+	}
+	return nil
+}
+
+// ServerOption returns the grpc.Creds option for the config.
+// The certificate files are loaded once here so a bad setup fails at startup
+func (c ServerConfig) ServerOption() (grpc.ServerOption, error) {
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Creds(credentials.NewTLS(tlsConfig)), nil
+}
+
+// TLSConfig returns a tls.Config that reloads the certificate and client CA
+// bundle when their files change
+func (c ServerConfig) TLSConfig() (*tls.Config, error) {
+	r := &reloader{config: c}
+	if _, err := r.current(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.configForClient,
+	}, nil
+}
+
+// reloadCheckInterval limits how often the files are stat'ed, tests shorten it
+var reloadCheckInterval = 5 * time.Second
+
+// reloader keeps the loaded certificate and CA pool, reloading them
+// when the modification time of any of the files changes
+type reloader struct {
+	config ServerConfig
+
+	mu        sync.Mutex
+	loaded    *tls.Config
+	modTimes  []time.Time
+	lastCheck time.Time
+}
+
+func (r *reloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return r.current()
+}
+
+func (r *reloader) current() (*tls.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loaded != nil && time.Since(r.lastCheck) < reloadCheckInterval {
+		return r.loaded, nil
+	}
+	r.lastCheck = time.Now()
+
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		if r.loaded != nil {
+			// keep serving the last good certificate while files are being replaced
+			return r.loaded, nil
+		}
+		return nil, err
+	}
+	if r.loaded != nil && sameTimes(modTimes, r.modTimes) {
+		return r.loaded, nil
+	}
+
+	loaded, err := r.load()
+	if err != nil {
+		if r.loaded != nil {
+			return r.loaded, nil
+		}
+		return nil, err
+	}
+	r.loaded = loaded
+	r.modTimes = modTimes
+	return r.loaded, nil
+}
+
+func (r *reloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load server certificate: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if r.config.ClientCAFile != "" {
+		pool, err := loadPool(r.config.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+func (r *reloader) fileModTimes() ([]time.Time, error) {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+
+	times := make([]time.Time, len(files))
+	for i, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot stat %v: %v", f, err)
+		}
+		times[i] = info.ModTime()
+	}
+	return times, nil
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadPool reads a PEM CA bundle
+func loadPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CA bundle: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %v", file)
+	}
+	return pool, nil
+}
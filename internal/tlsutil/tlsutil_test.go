@@ -0,0 +1,223 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/tlsutil/tlstest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// writeServerCert issues a certificate for localhost and writes it to <dir>/server.pem,
+// it returns the serial number of the certificate
+func writeServerCert(t *testing.T, ca *tlstest.CA, dir string) *big.Int {
+	t.Helper()
+	pair, err := ca.IssueServer("localhost", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile, err := pair.WriteFiles(dir, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the files must look changed even when written within the resolution of the file system clock
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	block, _ := pem.Decode(pair.CertPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.SerialNumber
+}
+
+// serveTLS accepts TLS connections with config and completes their handshake
+func serveTLS(t *testing.T, config *tls.Config) string {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+// handshake connects to addr and returns the serial number of the server certificate
+func handshake(t *testing.T, addr string, roots *x509.CertPool) *big.Int {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, ServerName: "localhost"})
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber
+}
+
+func TestServerReloadsCertificate(t *testing.T) {
+	defer func(d time.Duration) { reloadCheckInterval = d }(reloadCheckInterval)
+	reloadCheckInterval = 0
+
+	ca, err := tlstest.NewCA("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	dir := t.TempDir()
+	first := writeServerCert(t, ca, dir)
+
+	c := ServerConfig{CertFile: filepath.Join(dir, "server.pem"), KeyFile: filepath.Join(dir, "server-key.pem")}
+	config, err := c.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serveTLS(t, config)
+	if got := handshake(t, addr, roots); got.Cmp(first) != 0 {
+		t.Fatalf("server certificate serial = %v, want %v", got, first)
+	}
+
+	// a rotated certificate is picked up by the next handshakes
+	second := writeServerCert(t, ca, dir)
+	if got := handshake(t, addr, roots); got.Cmp(second) != 0 {
+		t.Errorf("after rotation, server certificate serial = %v, want the new %v", got, second)
+	}
+
+	// while the files are broken, e.g. half written, the last good certificate is served
+	if err := os.WriteFile(c.CertFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := handshake(t, addr, roots); got.Cmp(second) != 0 {
+		t.Errorf("with a broken file, server certificate serial = %v, want the last good %v", got, second)
+	}
+}
+
+func TestServerReloadIsThrottled(t *testing.T) {
+	ca, err := tlstest.NewCA("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	dir := t.TempDir()
+	first := writeServerCert(t, ca, dir)
+
+	c := ServerConfig{CertFile: filepath.Join(dir, "server.pem"), KeyFile: filepath.Join(dir, "server-key.pem")}
+	config, err := c.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serveTLS(t, config)
+
+	// the files are only checked every reloadCheckInterval
+	writeServerCert(t, ca, dir)
+	if got := handshake(t, addr, roots); got.Cmp(first) != 0 {
+		t.Errorf("right after rotation, server certificate serial = %v, want the old %v", got, first)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, err := tlstest.NewCA("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCA, err := tlstest.NewCA("other CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caFile, err := ca.WriteFile(dir, "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeServerCert(t, ca, dir)
+	client := func(ca *tlstest.CA, name string) (string, string) {
+		pair, err := ca.IssueClient(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certFile, keyFile, err := pair.WriteFiles(dir, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certFile, keyFile
+	}
+	trustedCert, trustedKey := client(ca, "trusted")
+	untrustedCert, untrustedKey := client(otherCA, "untrusted")
+
+	server := ServerConfig{
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server-key.pem"),
+		ClientCAFile: caFile,
+	}
+	creds, err := server.ServerOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(creds)
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	tests := []struct {
+		name     string
+		config   ClientConfig
+		wantCode codes.Code
+	}{
+		{"client certificate", ClientConfig{CAFile: caFile, CertFile: trustedCert, KeyFile: trustedKey, ServerName: "localhost"}, codes.OK},
+		{"no client certificate", ClientConfig{CAFile: caFile, ServerName: "localhost"}, codes.Unavailable},
+		{"certificate of another CA", ClientConfig{CAFile: caFile, CertFile: untrustedCert, KeyFile: untrustedKey, ServerName: "localhost"}, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			transport, err := tt.config.DialOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cc, err := grpc.NewClient(lis.Addr().String(), transport)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cc.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err = healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// StreamBatchInterceptor adds a child span to the RPC span for every batchSize messages
+// sent or received on a stream, so long streams show where their time went
+// without a span per message
+func StreamBatchInterceptor(batchSize int) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		s := &batchStream{
+			ServerStream: ss,
+			name:         info.FullMethod + "/batch",
+			batchSize:    batchSize,
+		}
+		err := handler(srv, s)
+		s.mu.Lock()
+		s.endBatch()
+		s.mu.Unlock()
+		return err
+	}
+}
+
+// batchStream keeps one span open per batch of messages. Handlers may send and
+// receive from different goroutines, so the batch is guarded by mu
+type batchStream struct {
+	grpc.ServerStream
+	name      string
+	batchSize int
+
+	mu       sync.Mutex
+	span     trace.Span
+	batch    int
+	received int
+	sent     int
+}
+
+func (s *batchStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.count(&s.received)
+	}
+	return err
+}
+
+func (s *batchStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	s.startBatch()
+	s.mu.Unlock()
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.count(&s.sent)
+	}
+	return err
+}
+
+func (s *batchStream) count(n *int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startBatch()
+	*n++
+	if s.received+s.sent >= s.batchSize {
+		s.endBatch()
+	}
+}
+
+func (s *batchStream) startBatch() {
+	if s.span != nil {
+		return
+	}
+	_, s.span = otel.Tracer(instrumentationName).Start(s.Context(), s.name,
+		trace.WithAttributes(attribute.Int("batch", s.batch)))
+}
+
+func (s *batchStream) endBatch() {
+	if s.span == nil {
+		return
+	}
+	s.span.SetAttributes(
+		attribute.Int("messages.received", s.received),
+		attribute.Int("messages.sent", s.sent),
+	)
+	s.span.SetName(fmt.Sprintf("%v %d", s.name, s.batch))
+	s.span.End()
+	s.span = nil
+	s.batch++
+	s.received, s.sent = 0, 0
+}
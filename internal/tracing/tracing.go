@@ -0,0 +1,130 @@
+// Package tracing sets up OpenTelemetry tracing for the servers and clients.
+// Trace context is propagated through gRPC metadata with the W3C traceparent header,
+// spans are exported over OTLP or written to stdout or a file for offline use
+package tracing
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/grpc"
+)
+
+// instrumentationName is the name of the tracer used for the spans created in this repo
+const instrumentationName = "github.com/angel/golang_api_microservice"
+
+// Config selects where spans go
+type Config struct {
+	// Exporter is one of none, stdout, file or otlp
+	Exporter string
+	// File is where the file exporter writes spans, one JSON object per span
+	File string
+	// OTLPEndpoint is the host:port of the OTLP gRPC collector
+	OTLPEndpoint string
+	// OTLPInsecure sends spans to the collector without TLS
+	OTLPInsecure bool
+	// SampleRatio is the fraction of new traces that are recorded, traces started
+	// by a caller follow the caller's sampling decision
+	SampleRatio float64
+}
+
+// RegisterFlags adds the tracing.* flags to fs
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Exporter, "tracing.exporter", "none", "where spans are exported: none, stdout, file or otlp")
+	fs.StringVar(&c.File, "tracing.file", "traces.json", "file written by the file exporter")
+	fs.StringVar(&c.OTLPEndpoint, "tracing.otlp-endpoint", "localhost:4317", "OTLP gRPC collector address")
+	fs.BoolVar(&c.OTLPInsecure, "tracing.otlp-insecure", true, "send spans to the collector without TLS")
+	fs.Float64Var(&c.SampleRatio, "tracing.sample-ratio", 1, "fraction of new traces that are recorded")
+}
+
+// Validate checks the config is usable
+func (c Config) Validate() error {
+	switch c.Exporter {
+	case "none", "stdout", "otlp":
+	case "file":
+		if c.File == "" {
+			return fmt.Errorf("tracing.file must be set for the file exporter")
+		}
+	default:
+		return fmt.Errorf("unknown tracing.exporter %q, must be none, stdout, file or otlp", c.Exporter)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample-ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+	return nil
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+// The propagator is installed even when the exporter is none, so trace context
+// still flows through this process. The returned func flushes and stops the exporter
+func Setup(ctx context.Context, serviceName string, c Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	var err error
+	switch c.Exporter {
+	case "none", "":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "file":
+		f, ferr := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if ferr != nil {
+			return nil, fmt.Errorf("cannot open trace file: %v", ferr)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.OTLPEndpoint)}
+		if c.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", c.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %v trace exporter: %v", c.Exporter, err)
+	}
+
+	res := resource.NewSchemaless(semconv.ServiceName(serviceName))
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// ServerOption creates a span for each incoming RPC, continuing the caller's trace
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// DialOption creates a span for each outgoing RPC and sends the trace context to the server
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// kinds of RPC, as reported
+const (
+	unary        = "unary"
+	serverStream = "server stream"
+	clientStream = "client stream"
+	bidiStream   = "bidi stream"
+)
+
+// caller makes one call of a method with the same requests every time
+type caller struct {
+	// method is the full method name, e.g. /calculator.CalculatorService/Sum
+	method   string
+	kind     string
+	output   protoreflect.MessageDescriptor
+	requests []proto.Message
+}
+
+// findMethod resolves calculator.CalculatorService/Sum, with or without the leading
+// slash, or calculator.CalculatorService.Sum
+func findMethod(name string) (protoreflect.MethodDescriptor, error) {
+	name = strings.TrimPrefix(name, "/")
+	i := strings.LastIndexAny(name, "/.")
+	if i < 0 {
+		return nil, fmt.Errorf("method %q must be service/method, e.g. calculator.CalculatorService/Sum", name)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name[:i]))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find service %v: %v", name[:i], err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a service", name[:i])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(name[i+1:]))
+	if md == nil {
+		return nil, fmt.Errorf("service %v has no method %v", name[:i], name[i+1:])
+	}
+	return md, nil
+}
+
+// newCaller builds the requests from data, a JSON request or, for client and bidi
+// streams, an array of them. Streams send them repeat times over
+func newCaller(md protoreflect.MethodDescriptor, data string, repeat int) (*caller, error) {
+	c := &caller{
+		method: fmt.Sprintf("/%v/%v", md.Parent().FullName(), md.Name()),
+		output: md.Output(),
+	}
+	switch {
+	case md.IsStreamingClient() && md.IsStreamingServer():
+		c.kind = bidiStream
+	case md.IsStreamingClient():
+		c.kind = clientStream
+	case md.IsStreamingServer():
+		c.kind = serverStream
+	default:
+		c.kind = unary
+	}
+
+	var raw []json.RawMessage
+	data = strings.TrimSpace(data)
+	if strings.HasPrefix(data, "[") {
+		if !md.IsStreamingClient() {
+			return nil, fmt.Errorf("%v takes one request, not an array", c.method)
+		}
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			return nil, fmt.Errorf("invalid requests: %v", err)
+		}
+	} else {
+		raw = []json.RawMessage{json.RawMessage(data)}
+	}
+	if !md.IsStreamingClient() {
+		repeat = 1
+	}
+	for i := 0; i < repeat; i++ {
+		for _, r := range raw {
+			m := dynamicpb.NewMessage(md.Input())
+			if err := protojson.Unmarshal(r, m); err != nil {
+				return nil, fmt.Errorf("invalid request %s for %v: %v", r, md.Input().FullName(), err)
+			}
+			c.requests = append(c.requests, m)
+		}
+	}
+	return c, nil
+}
+
+// call makes one call and returns how many responses came back
+func (c *caller) call(ctx context.Context, cc grpc.ClientConnInterface) (int, error) {
+	if c.kind == unary {
+		if err := cc.Invoke(ctx, c.method, c.requests[0], dynamicpb.NewMessage(c.output)); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	desc := &grpc.StreamDesc{
+		ClientStreams: c.kind == clientStream || c.kind == bidiStream,
+		ServerStreams: c.kind == serverStream || c.kind == bidiStream,
+	}
+	stream, err := cc.NewStream(ctx, desc, c.method)
+	if err != nil {
+		return 0, err
+	}
+
+	// bidi streams send while receiving, a send error shows up on the receiving side
+	send := func() error {
+		for _, req := range c.requests {
+			if err := stream.SendMsg(req); err != nil {
+				return err
+			}
+		}
+		return stream.CloseSend()
+	}
+	if c.kind == bidiStream {
+		go send()
+	} else if err := send(); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		err := stream.RecvMsg(dynamicpb.NewMessage(c.output))
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+		if !desc.ServerStreams {
+			return count, nil
+		}
+	}
+}
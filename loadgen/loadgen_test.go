@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+)
+
+func TestRun(t *testing.T) {
+	s := servertest.Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+	})
+	cc := s.Dial(t, []string{calcclient.ServiceName}, sdk.WithRetryPolicy(sdk.RetryPolicy{MaxAttempts: 1}), sdk.WithBreakerPolicy(sdk.BreakerPolicy{}))
+
+	tests := []struct {
+		name          string
+		method        string
+		data          string
+		repeat        int
+		wantKind      string
+		wantResponses int
+		wantCodes     map[string]int
+	}{
+		{"unary", "calculator.CalculatorService/Sum", `{"first_number": 3, "second_number": 4}`, 1, unary, 20, map[string]int{"OK": 20}},
+		{"server stream", "/calculator.CalculatorService/PrimeNumberDecomposition", `{"number": 120}`, 1, serverStream, 100, map[string]int{"OK": 20}},
+		{"client stream", "calculator.CalculatorService.ComputeAverage", `[{"number": 1}, {"number": 2}]`, 3, clientStream, 20, map[string]int{"OK": 20}},
+		{"bidi stream", "calculator.CalculatorService/FindMaximum", `[{"number": 1}, {"number": 5}, {"number": 3}]`, 2, bidiStream, 40, map[string]int{"OK": 20}},
+		{"errors", "calculator.CalculatorService/SquareRoot", `{"number": -4}`, 1, unary, 0, map[string]int{"InvalidArgument": 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := findMethod(tt.method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := newCaller(md, tt.data, tt.repeat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec, elapsed := run(context.Background(), c, []grpc.ClientConnInterface{cc}, runConfig{concurrency: 4, requests: 20, timeout: 5 * time.Second})
+			rep := rec.report(c, elapsed)
+
+			if rep.Kind != tt.wantKind {
+				t.Errorf("kind = %v, want %v", rep.Kind, tt.wantKind)
+			}
+			if rep.Calls != 20 || rep.Responses != tt.wantResponses {
+				t.Errorf("calls = %v, responses = %v, want 20 and %v", rep.Calls, rep.Responses, tt.wantResponses)
+			}
+			if len(rep.Codes) != len(tt.wantCodes) {
+				t.Errorf("codes = %v, want %v", rep.Codes, tt.wantCodes)
+			}
+			for code, n := range tt.wantCodes {
+				if rep.Codes[code] != n {
+					t.Errorf("codes = %v, want %v", rep.Codes, tt.wantCodes)
+				}
+			}
+			if rep.Errors == 0 && (rep.Latency.P50 <= 0 || rep.Latency.P50 > rep.Latency.P99 || rep.Latency.P99 > rep.Latency.Max) {
+				t.Errorf("latency percentiles out of order: %+v", rep.Latency)
+			}
+
+			var csv bytes.Buffer
+			if err := rec.writeCSV(&csv); err != nil {
+				t.Fatal(err)
+			}
+			if lines := strings.Count(csv.String(), "\n"); lines != 21 {
+				t.Errorf("csv has %v lines, want a header and 20 rows", lines)
+			}
+		})
+	}
+}
+
+func TestRunQPS(t *testing.T) {
+	cc := servertest.Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+	}).Dial(t, []string{calcclient.ServiceName})
+	md, err := findMethod("calculator.CalculatorService/Sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newCaller(md, "{}", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, elapsed := run(context.Background(), c, []grpc.ClientConnInterface{cc}, runConfig{concurrency: 8, qps: 100, duration: 500 * time.Millisecond})
+	rep := rec.report(c, elapsed)
+	// the limiter allows about 50 calls in half a second, whatever the concurrency
+	if rep.Calls < 30 || rep.Calls > 70 {
+		t.Errorf("made %v calls at 100 calls/s in %v, want about 50", rep.Calls, elapsed)
+	}
+}
+
+func TestNewCallerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		data   string
+	}{
+		{"unknown service", "nope.Service/Sum", "{}"},
+		{"unknown method", "calculator.CalculatorService/Nope", "{}"},
+		{"no method", "calculator", "{}"},
+		{"array for unary", "calculator.CalculatorService/Sum", "[{}]"},
+		{"unknown field", "calculator.CalculatorService/Sum", `{"nope": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := findMethod(tt.method)
+			if err == nil {
+				_, err = newCaller(md, tt.data, 1)
+			}
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// loadgen drives load against any method of the greet, calculator and blog services
+// and reports latency percentiles, throughput and the status codes of the calls:
+//
+//	loadgen --target localhost:50053 --method calculator.CalculatorService/Sum \
+//	    --data '{"first_number": 3, "second_number": 4}' --concurrency 50 --duration 30s
+//	loadgen --target localhost:50053 --method calculator.CalculatorService/FindMaximum \
+//	    --data '[{"number": 1}, {"number": 5}]' --repeat 100 --qps 200
+//
+// Client and bidi streams send the requests of --data, an array, --repeat times over
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	_ "github.com/angel/golang_api_microservice/blog/blogpb"
+	_ "github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	_ "github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/config"
+	"github.com/angel/golang_api_microservice/internal/tlsutil"
+	"github.com/angel/golang_api_microservice/sdk"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// runConfig is how hard and for how long a run goes
+type runConfig struct {
+	concurrency int
+	// qps caps the calls per second over all workers, 0 means as fast as they can
+	qps float64
+	// duration ends the run, 0 means when requests calls were made
+	duration time.Duration
+	// requests ends the run after that many calls, 0 means when duration is over
+	requests int64
+	// timeout is the deadline of every call, 0 for none
+	timeout time.Duration
+}
+
+func main() {
+	log.SetFlags(0)
+
+	target := flag.String("target", "localhost:50053", "address of the server, see sdk.Dial for targets with several backends")
+	method := flag.String("method", "", "method to call, e.g. calculator.CalculatorService/Sum")
+	data := flag.String("data", "{}", "JSON request, or an array of requests for client and bidi streams")
+	repeat := flag.Int("repeat", 1, "how many times client and bidi streams send the requests of --data in a call")
+	concurrency := flag.Int("concurrency", 10, "how many calls are in flight at once")
+	connections := flag.Int("connections", 1, "how many connections the calls are spread over")
+	qps := flag.Float64("qps", 0, "calls per second over all workers, 0 for as many as they can make")
+	duration := flag.Duration("duration", 10*time.Second, "how long the run lasts, 0 to stop after --requests calls")
+	requests := flag.Int64("requests", 0, "how many calls to make, 0 to stop after --duration")
+	timeout := flag.Duration("timeout", 10*time.Second, "deadline of every call, 0 for none")
+	format := flag.String("format", "text", "output: text or json for a summary, csv for one row per call")
+	output := flag.String("output", "-", "file the results are written to, - for stdout")
+	balancer := flag.String("balancer", sdk.RoundRobin, "how calls are spread over the backends of a target: pick_first, round_robin or least_request")
+	tlsCfg := tlsutil.ClientConfig{}
+	tlsCfg.RegisterFlags(flag.CommandLine)
+	creds := auth.ClientCredentials{}
+	creds.RegisterFlags(flag.CommandLine)
+	if err := config.Load(flag.CommandLine, "loadgen", os.Args[1:]); err != nil {
+		if err == config.ErrPrintConfig {
+			return
+		}
+		log.Fatalf("could not load config: %v", err)
+	}
+
+	cfg := runConfig{concurrency: *concurrency, qps: *qps, duration: *duration, requests: *requests, timeout: *timeout}
+	switch {
+	case *method == "":
+		log.Fatalf("--method is required")
+	case *format != "text" && *format != "json" && *format != "csv":
+		log.Fatalf("invalid format %q, must be text, json or csv", *format)
+	case cfg.concurrency < 1 || *connections < 1 || *repeat < 1:
+		log.Fatalf("--concurrency, --connections and --repeat must be at least 1")
+	case cfg.duration <= 0 && cfg.requests <= 0:
+		log.Fatalf("--duration or --requests must be set")
+	}
+	if err := tlsCfg.Validate(); err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+	transport, err := tlsCfg.DialOption()
+	if err != nil {
+		log.Fatalf("could not load TLS credentials: %v", err)
+	}
+
+	md, err := findMethod(*method)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := newCaller(md, *data, *repeat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// retries and circuit breakers would hide the errors the run is meant to count
+	dialOptions := []sdk.Option{
+		sdk.WithTransport(transport),
+		sdk.WithTimeout(0),
+		sdk.WithRetryPolicy(sdk.RetryPolicy{MaxAttempts: 1}),
+		sdk.WithBreakerPolicy(sdk.BreakerPolicy{}),
+		sdk.WithBalancer(*balancer),
+	}
+	if creds.Enabled() {
+		dialOptions = append(dialOptions, sdk.WithPerRPCCredentials(creds))
+	}
+	conns := []grpc.ClientConnInterface{}
+	for i := 0; i < *connections; i++ {
+		cc, err := sdk.Dial(*target, []string{string(md.Parent().FullName())}, dialOptions...)
+		if err != nil {
+			log.Fatalf("could not connect to %v: %v", *target, err)
+		}
+		defer cc.Close()
+		conns = append(conns, cc)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("could not create output: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	// Ctrl-C ends the run early, with the results so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Calling %v (%v) from %v workers...\n", c.method, c.kind, cfg.concurrency)
+	rec, elapsed := run(ctx, c, conns, cfg)
+
+	if *format == "csv" {
+		err = rec.writeCSV(w)
+	} else {
+		rep := rec.report(c, elapsed)
+		rep.Concurrency, rep.QPS = cfg.concurrency, cfg.qps
+		if *format == "json" {
+			err = writeJSON(w, rep)
+		} else {
+			err = writeText(w, rep)
+		}
+	}
+	if err != nil {
+		log.Fatalf("could not write the results: %v", err)
+	}
+}
+
+// run calls c from cfg.concurrency workers spread over conns until the duration is over,
+// the requests are made or ctx is done. Calls in flight then finish, they are not canceled
+func run(ctx context.Context, c *caller, conns []grpc.ClientConnInterface, cfg runConfig) (*recorder, time.Duration) {
+	if cfg.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.duration)
+		defer cancel()
+	}
+	var limiter *rate.Limiter
+	if cfg.qps > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.qps), 1)
+	}
+
+	rec := newRecorder()
+	var made atomic.Int64
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.concurrency; i++ {
+		cc := conns[i%len(conns)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if limiter != nil && limiter.Wait(ctx) != nil {
+					return
+				}
+				if cfg.requests > 0 && made.Add(1) > cfg.requests {
+					return
+				}
+
+				callCtx, cancel := context.Background(), context.CancelFunc(func() {})
+				if cfg.timeout > 0 {
+					callCtx, cancel = context.WithTimeout(callCtx, cfg.timeout)
+				}
+				callStart := time.Now()
+				responses, err := c.call(callCtx, cc)
+				latency := time.Since(callStart)
+				cancel()
+				if errors.Is(err, context.DeadlineExceeded) {
+					err = status.FromContextError(err).Err()
+				}
+				rec.add(result{
+					start:     callStart.Sub(start),
+					latency:   latency,
+					code:      status.Code(err),
+					responses: responses,
+				}, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return rec, time.Since(start)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// result is the outcome of one call
+type result struct {
+	// start is when the call started, from the start of the run
+	start     time.Duration
+	latency   time.Duration
+	code      codes.Code
+	responses int
+}
+
+// recorder collects the results of every call, it is safe for concurrent use
+type recorder struct {
+	mu       sync.Mutex
+	results  []result
+	examples map[codes.Code]string
+}
+
+func newRecorder() *recorder {
+	return &recorder{examples: map[codes.Code]string{}}
+}
+
+func (r *recorder) add(res result, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, res)
+	if err != nil {
+		if _, ok := r.examples[res.code]; !ok {
+			r.examples[res.code] = status.Convert(err).Message()
+		}
+	}
+}
+
+// Report is the summary of a run, latencies are those of the successful calls
+type Report struct {
+	Method      string  `json:"method"`
+	Kind        string  `json:"kind"`
+	Concurrency int     `json:"concurrency"`
+	QPS         float64 `json:"target_qps,omitempty"`
+	Calls       int     `json:"calls"`
+	Errors      int     `json:"errors"`
+	Responses   int     `json:"responses"`
+	Seconds     float64 `json:"duration_seconds"`
+	Throughput  float64 `json:"calls_per_second"`
+	Latency     Latency `json:"latency_ms"`
+	// Codes counts the calls by status code, ErrorExamples has the message of the first
+	// call that failed with each code
+	Codes         map[string]int    `json:"codes"`
+	ErrorExamples map[string]string `json:"error_examples,omitempty"`
+}
+
+// Latency is in milliseconds
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+func (r *recorder) report(c *caller, elapsed time.Duration) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := Report{
+		Method:  c.method,
+		Kind:    c.kind,
+		Calls:   len(r.results),
+		Seconds: elapsed.Seconds(),
+		Codes:   map[string]int{},
+	}
+	if elapsed > 0 {
+		rep.Throughput = float64(len(r.results)) / elapsed.Seconds()
+	}
+	ok := []time.Duration{}
+	var total time.Duration
+	for _, res := range r.results {
+		rep.Codes[res.code.String()]++
+		rep.Responses += res.responses
+		if res.code != codes.OK {
+			rep.Errors++
+			continue
+		}
+		ok = append(ok, res.latency)
+		total += res.latency
+	}
+	for code, msg := range r.examples {
+		if rep.ErrorExamples == nil {
+			rep.ErrorExamples = map[string]string{}
+		}
+		rep.ErrorExamples[code.String()] = msg
+	}
+	if len(ok) > 0 {
+		slices.Sort(ok)
+		rep.Latency = Latency{
+			Min:  ms(ok[0]),
+			Mean: ms(total / time.Duration(len(ok))),
+			P50:  ms(percentile(ok, 50)),
+			P90:  ms(percentile(ok, 90)),
+			P99:  ms(percentile(ok, 99)),
+			Max:  ms(ok[len(ok)-1]),
+		}
+	}
+	return rep
+}
+
+// percentile of sorted latencies, nearest rank
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(p/100*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func writeText(w io.Writer, rep Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Method:\t%v (%v)\n", rep.Method, rep.Kind)
+	load := fmt.Sprintf("%v workers", rep.Concurrency)
+	if rep.QPS > 0 {
+		load += fmt.Sprintf(", %v calls/s target", rep.QPS)
+	}
+	fmt.Fprintf(tw, "Load:\t%v\n", load)
+	fmt.Fprintf(tw, "Calls:\t%v in %.2fs, %.1f calls/s, %v failed\n", rep.Calls, rep.Seconds, rep.Throughput, rep.Errors)
+	fmt.Fprintf(tw, "Responses:\t%v\n", rep.Responses)
+	l := rep.Latency
+	fmt.Fprintf(tw, "Latency:\tmin %vms  mean %vms  p50 %vms  p90 %vms  p99 %vms  max %vms\n", l.Min, l.Mean, l.P50, l.P90, l.P99, l.Max)
+	fmt.Fprintf(tw, "Status codes:\t\n")
+	codeNames := make([]string, 0, len(rep.Codes))
+	for code := range rep.Codes {
+		codeNames = append(codeNames, code)
+	}
+	sort.Slice(codeNames, func(i, j int) bool { return rep.Codes[codeNames[i]] > rep.Codes[codeNames[j]] })
+	for _, code := range codeNames {
+		line := fmt.Sprintf("  %v\t%v", code, rep.Codes[code])
+		if msg, ok := rep.ErrorExamples[code]; ok {
+			line += fmt.Sprintf("\te.g. %v", msg)
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, rep Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// writeCSV writes one row per call, in the order they finished
+func (r *recorder) writeCSV(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"start_ms", "latency_ms", "code", "responses"})
+	for _, res := range r.results {
+		cw.Write([]string{
+			strconv.FormatFloat(ms(res.start), 'f', -1, 64),
+			strconv.FormatFloat(ms(res.latency), 'f', -1, 64),
+			res.code.String(),
+			strconv.Itoa(res.responses),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
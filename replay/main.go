@@ -0,0 +1,170 @@
+// replay sends the calls of a recording made with --record.file again, against a local
+// server for instance, and reports the ones whose answers differ from the recorded ones:
+//
+//	replay --target localhost:50053 --file calls.jsonl
+//	replay --target localhost:50052 --file calls.jsonl --method /blog.BlogService/ApproveContent --speed 1
+//
+// It exits with status 1 when any call differs or could not be replayed
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	_ "github.com/angel/golang_api_microservice/blog/blogpb"
+	_ "github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	_ "github.com/angel/golang_api_microservice/greet/greetpb"
+	"github.com/angel/golang_api_microservice/internal/auth"
+	"github.com/angel/golang_api_microservice/internal/config"
+	"github.com/angel/golang_api_microservice/internal/recording"
+	"github.com/angel/golang_api_microservice/internal/tlsutil"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	target := flag.String("target", "localhost:50053", "address of the server the calls are sent to")
+	file := flag.String("file", "", "recording to replay, - for stdin")
+	methods := flag.String("method", "", "only replay these full methods, comma separated, e.g. /calculator.CalculatorService/Sum")
+	speed := flag.Float64("speed", 0, "replay at this multiple of the recorded pace, calls overlapping as they did; 0 sends the calls one after another right away")
+	timeout := flag.Duration("timeout", 30*time.Second, "deadline of the calls recorded without one")
+	ignore := flag.String("ignore", "", "fields left out of the comparison, comma separated, e.g. id")
+	verbose := flag.Bool("v", false, "also list the calls that matched")
+	tlsCfg := tlsutil.ClientConfig{}
+	tlsCfg.RegisterFlags(flag.CommandLine)
+	creds := auth.ClientCredentials{}
+	creds.RegisterFlags(flag.CommandLine)
+	if err := config.Load(flag.CommandLine, "replay", os.Args[1:]); err != nil {
+		if err == config.ErrPrintConfig {
+			return
+		}
+		log.Fatalf("could not load config: %v", err)
+	}
+
+	switch {
+	case *file == "":
+		log.Fatalf("--file is required")
+	case *speed < 0:
+		log.Fatalf("--speed must not be negative")
+	}
+	if err := tlsCfg.Validate(); err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+	transport, err := tlsCfg.DialOption()
+	if err != nil {
+		log.Fatalf("could not load TLS credentials: %v", err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("could not open the recording: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	calls, err := recording.Read(in)
+	if err != nil {
+		log.Fatalf("could not read the recording: %v", err)
+	}
+	calls = filterMethods(calls, splitList(*methods))
+	if len(calls) == 0 {
+		log.Fatalf("no calls to replay in %v", *file)
+	}
+
+	// retries and circuit breakers would change what the server sees compared to the recording
+	dialOptions := []sdk.Option{
+		sdk.WithTransport(transport),
+		sdk.WithTimeout(0),
+		sdk.WithRetryPolicy(sdk.RetryPolicy{MaxAttempts: 1}),
+		sdk.WithBreakerPolicy(sdk.BreakerPolicy{}),
+	}
+	if creds.Enabled() {
+		dialOptions = append(dialOptions, sdk.WithPerRPCCredentials(creds))
+	}
+	cc, err := sdk.Dial(*target, nil, dialOptions...)
+	if err != nil {
+		log.Fatalf("could not connect to %v: %v", *target, err)
+	}
+	defer cc.Close()
+
+	r := &replayer{cc: cc, speed: *speed, timeout: *timeout, ignore: map[protoreflect.Name]bool{}}
+	for _, name := range splitList(*ignore) {
+		r.ignore[protoreflect.Name(name)] = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Replaying %v calls against %v...\n", len(calls), *target)
+	if !writeOutcomes(os.Stdout, r.replayAll(ctx, calls), *verbose) {
+		os.Exit(1)
+	}
+}
+
+// writeOutcomes lists the calls that differ or failed and a summary,
+// it returns true when every call matched
+func writeOutcomes(w io.Writer, outcomes []outcome, verbose bool) bool {
+	matched, differed, failed := 0, 0, 0
+	for _, o := range outcomes {
+		call := o.call.Method
+		if o.call.RequestID != "" {
+			call += " request_id=" + o.call.RequestID
+		}
+		switch {
+		case o.err != nil:
+			failed++
+			fmt.Fprintf(w, "FAIL %v: %v\n", call, o.err)
+		case len(o.diffs) > 0:
+			differed++
+			fmt.Fprintf(w, "DIFF %v in %v\n", call, o.latency.Round(time.Microsecond))
+			for _, d := range o.diffs {
+				fmt.Fprintf(w, "     %v\n", d)
+			}
+		default:
+			matched++
+			if verbose {
+				fmt.Fprintf(w, "ok   %v in %v\n", call, o.latency.Round(time.Microsecond))
+			}
+		}
+	}
+	fmt.Fprintf(w, "%v calls: %v matched, %v differed, %v failed\n", len(outcomes), matched, differed, failed)
+	return differed == 0 && failed == 0
+}
+
+func filterMethods(calls []recording.Call, methods []string) []recording.Call {
+	if len(methods) == 0 {
+		return calls
+	}
+	keep := map[string]bool{}
+	for _, m := range methods {
+		keep["/"+strings.TrimPrefix(m, "/")] = true
+	}
+	var out []recording.Call
+	for _, c := range calls {
+		if keep[c.Method] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/logging"
+	"github.com/angel/golang_api_microservice/internal/recording"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// skippedMetadata is recorded metadata that is not sent again: credentials come from the
+// replay's own flags, and the rest would mix the replayed calls up with the recorded ones
+var skippedMetadata = map[string]bool{
+	"user-agent":   true,
+	"x-request-id": true,
+	"traceparent":  true,
+	"tracestate":   true,
+}
+
+// replayer sends recorded calls again and compares the answers with the recorded ones
+type replayer struct {
+	cc grpc.ClientConnInterface
+	// speed scales the recorded time between calls and between stream messages,
+	// 0 sends everything right away, one call after another
+	speed float64
+	// timeout is the deadline of calls recorded without one
+	timeout time.Duration
+	// ignore are fields left out of the comparison, e.g. generated IDs
+	ignore map[protoreflect.Name]bool
+}
+
+// outcome is the result of replaying one call
+type outcome struct {
+	call    recording.Call
+	latency time.Duration
+	// diffs lists how the answer differs from the recorded one
+	diffs []string
+	// err is set when the call could not be replayed at all
+	err error
+}
+
+// replayAll replays calls in the order they started and returns their outcomes in that order
+func (r *replayer) replayAll(ctx context.Context, calls []recording.Call) []outcome {
+	calls = append([]recording.Call(nil), calls...)
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].Start.Before(calls[j].Start) })
+	out := make([]outcome, len(calls))
+	if r.speed <= 0 {
+		for i, c := range calls {
+			out[i] = r.replay(ctx, c)
+		}
+		return out
+	}
+
+	// calls start at their recorded time from the first one, so they overlap as they did
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, c := range calls {
+		at := r.scale(c.Start.Sub(calls[0].Start))
+		if !sleepUntil(ctx, start.Add(at)) {
+			out[i] = outcome{call: c, err: ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i] = r.replay(ctx, c)
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// replay sends one call again and compares the answer
+func (r *replayer) replay(ctx context.Context, c recording.Call) outcome {
+	o := outcome{call: c}
+	md, err := findMethod(c.Method)
+	if err != nil {
+		o.err = err
+		return o
+	}
+	requests, err := unmarshalAll(md.Input(), c.Requests)
+	if err != nil {
+		o.err = fmt.Errorf("invalid request: %v", err)
+		return o
+	}
+	want, err := unmarshalAll(md.Output(), c.Responses)
+	if err != nil {
+		o.err = fmt.Errorf("invalid response: %v", err)
+		return o
+	}
+
+	timeout := r.timeout
+	if c.Timeout > 0 {
+		timeout = c.Timeout
+	}
+	ctx, cancel := context.WithTimeout(outgoingMetadata(ctx, c.Metadata), timeout)
+	defer cancel()
+	start := time.Now()
+	got, err := r.call(ctx, md, c, requests)
+	o.latency = time.Since(start)
+	o.diffs = r.compare(c, want, got, err)
+	return o
+}
+
+// call makes the call, sending stream messages at their recorded offsets when the speed is set
+func (r *replayer) call(ctx context.Context, md protoreflect.MethodDescriptor, c recording.Call, requests []proto.Message) ([]proto.Message, error) {
+	if !md.IsStreamingClient() && !md.IsStreamingServer() {
+		if len(requests) != 1 {
+			return nil, fmt.Errorf("unary call recorded with %v requests", len(requests))
+		}
+		res := dynamicpb.NewMessage(md.Output())
+		if err := r.cc.Invoke(ctx, c.Method, requests[0], res); err != nil {
+			return nil, err
+		}
+		return []proto.Message{res}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	desc := &grpc.StreamDesc{ClientStreams: md.IsStreamingClient(), ServerStreams: md.IsStreamingServer()}
+	stream, err := r.cc.NewStream(ctx, desc, c.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	// requests are sent while the answers come in, a send error shows up on the receiving side
+	start := time.Now()
+	go func() {
+		for i, req := range requests {
+			if r.speed > 0 && !sleepUntil(ctx, start.Add(r.scale(c.Requests[i].Offset))) {
+				return
+			}
+			if stream.SendMsg(req) != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	var got []proto.Message
+	for {
+		res := dynamicpb.NewMessage(md.Output())
+		err := stream.RecvMsg(res)
+		if err == io.EOF {
+			return got, nil
+		}
+		if err != nil {
+			return got, err
+		}
+		got = append(got, res)
+		if !desc.ServerStreams {
+			return got, nil
+		}
+	}
+}
+
+// compare returns how the replayed answer differs from the recorded one
+func (r *replayer) compare(c recording.Call, want, got []proto.Message, err error) []string {
+	var diffs []string
+	st := status.Convert(err)
+	if st.Code().String() != c.Code || st.Message() != c.Message {
+		diffs = append(diffs, fmt.Sprintf("status: got %v %q, want %v %q", st.Code(), st.Message(), c.Code, c.Message))
+	}
+
+	// the recorded answer may have been redacted, the replayed one is redacted the same way
+	for _, m := range got {
+		if c.Redacted {
+			logging.Redact(m.ProtoReflect())
+		}
+		clearFields(m.ProtoReflect(), r.ignore)
+	}
+	for _, m := range want {
+		clearFields(m.ProtoReflect(), r.ignore)
+	}
+
+	// a truncated recording only has the first responses
+	if len(got) != len(want) && !(c.Truncated && len(got) > len(want)) {
+		diffs = append(diffs, fmt.Sprintf("got %v responses, want %v", len(got), len(want)))
+	}
+	for i := 0; i < len(got) && i < len(want); i++ {
+		if !proto.Equal(got[i], want[i]) {
+			diffs = append(diffs, fmt.Sprintf("response %v: got %s, want %s", i, compactJSON(got[i]), compactJSON(want[i])))
+		}
+	}
+	return diffs
+}
+
+// findMethod resolves a full method, e.g. /calculator.CalculatorService/Sum
+func findMethod(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid method %q", fullMethod)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find service %v: %v", service, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("service %v has no method %v", service, method)
+	}
+	return md, nil
+}
+
+func unmarshalAll(md protoreflect.MessageDescriptor, msgs []recording.Message) ([]proto.Message, error) {
+	out := make([]proto.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		m := dynamicpb.NewMessage(md)
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(msg.Body, m); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// outgoingMetadata adds the recorded metadata worth sending again to ctx
+func outgoingMetadata(ctx context.Context, recorded map[string][]string) context.Context {
+	md := metadata.MD{}
+	for k, v := range recorded {
+		if !skippedMetadata[k] && !recording.CredentialKeys[k] {
+			md[k] = v
+		}
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// clearFields clears the named fields of m and of every message nested in it
+func clearFields(m protoreflect.Message, names map[protoreflect.Name]bool) {
+	if len(names) == 0 {
+		return
+	}
+	// fields are only cleared after Range, a message must not be modified while ranging over it
+	var clear []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case names[fd.Name()]:
+			clear = append(clear, fd)
+		case fd.Message() != nil && fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				clearFields(list.Get(i).Message(), names)
+			}
+		case fd.Message() != nil && fd.IsMap():
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				if fd.MapValue().Message() != nil {
+					clearFields(mv.Message(), names)
+				}
+				return true
+			})
+		case fd.Message() != nil:
+			clearFields(v.Message(), names)
+		}
+		return true
+	})
+	for _, fd := range clear {
+		m.Clear(fd)
+	}
+}
+
+// compactJSON prints m on one line, protojson adds random spaces to keep its output unstable
+func compactJSON(m proto.Message) string {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return fmt.Sprintf("<unprintable: %v>", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return string(b)
+	}
+	return buf.String()
+}
+
+func (r *replayer) scale(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / r.speed)
+}
+
+// sleepUntil waits until t, it returns false when ctx is done first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/calculator/calculatorservice"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/recording"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// recordCalls records a call of every calculator method against s
+func recordCalls(t *testing.T, s *servertest.Server, file string) []recording.Call {
+	t.Helper()
+	c := calcclient.New(s.Dial(t, []string{calcclient.ServiceName}))
+	ctx := context.Background()
+	if _, err := c.Sum(ctx, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PrimeFactors(ctx, 120, func(int64) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Average(ctx, []int32{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := c.Maximum(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int32{1, 5, 3, 6} {
+		if err := stream.Send(n); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	stream.CloseSend()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	if _, err := c.SquareRoot(ctx, -4); err == nil {
+		t.Fatal("SquareRoot(-4) did not fail")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	calls, err := recording.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 5 {
+		t.Fatalf("recorded %v calls, want 5", len(calls))
+	}
+	return calls
+}
+
+func TestReplay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "calls.jsonl")
+	s := servertest.Start(t, bootstrap.Config{Record: recording.Config{File: file, SampleRate: 1, MaxMessages: 1000}}, func(s *bootstrap.Server) {
+		calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calculatorservice.NewServer())
+	})
+	calls := recordCalls(t, s, file)
+	cc := s.Dial(t, []string{calcclient.ServiceName}, sdk.WithRetryPolicy(sdk.RetryPolicy{MaxAttempts: 1}), sdk.WithBreakerPolicy(sdk.BreakerPolicy{}))
+
+	// edit changes the recording of the named method
+	edit := func(method string, fn func(c *recording.Call)) []recording.Call {
+		out := []recording.Call{}
+		for _, c := range calls {
+			if c.Method == method {
+				c.Responses = append([]recording.Message(nil), c.Responses...)
+				fn(&c)
+			}
+			out = append(out, c)
+		}
+		return out
+	}
+	body := func(s string) json.RawMessage { return json.RawMessage(s) }
+
+	tests := []struct {
+		name   string
+		calls  []recording.Call
+		speed  float64
+		ignore []protoreflect.Name
+		// wantDiffs has a part of the diffs of each call, "" for none
+		wantDiffs []string
+		wantFail  string
+	}{
+		{
+			name:      "same answers",
+			calls:     calls,
+			wantDiffs: []string{"", "", "", "", ""},
+		},
+		{
+			name:      "same answers at the recorded pace",
+			calls:     calls,
+			speed:     1,
+			wantDiffs: []string{"", "", "", "", ""},
+		},
+		{
+			name: "different response",
+			calls: edit("/calculator.CalculatorService/Sum", func(c *recording.Call) {
+				c.Responses[0].Body = body(`{"sum_result": 8}`)
+			}),
+			wantDiffs: []string{`response 0: got {"sum_result":7}, want {"sum_result":8}`, "", "", "", ""},
+		},
+		{
+			name: "ignored field",
+			calls: edit("/calculator.CalculatorService/Sum", func(c *recording.Call) {
+				c.Responses[0].Body = body(`{"sum_result": 8}`)
+			}),
+			ignore:    []protoreflect.Name{"sum_result"},
+			wantDiffs: []string{"", "", "", "", ""},
+		},
+		{
+			name: "missing stream responses",
+			calls: edit("/calculator.CalculatorService/PrimeNumberDecomposition", func(c *recording.Call) {
+				c.Responses = c.Responses[:2]
+			}),
+			wantDiffs: []string{"", "got 5 responses, want 2", "", "", ""},
+		},
+		{
+			name: "truncated recording",
+			calls: edit("/calculator.CalculatorService/PrimeNumberDecomposition", func(c *recording.Call) {
+				c.Responses, c.Truncated = c.Responses[:2], true
+			}),
+			wantDiffs: []string{"", "", "", "", ""},
+		},
+		{
+			name: "different status",
+			calls: edit("/calculator.CalculatorService/SquareRoot", func(c *recording.Call) {
+				c.Code, c.Message = "OK", ""
+				c.Responses = []recording.Message{{Body: body(`{"number_root": 2}`)}}
+			}),
+			wantDiffs: []string{"", "", "", "", `status: got InvalidArgument "received a negative number: -4", want OK ""`},
+		},
+		{
+			name: "unknown method",
+			calls: edit("/calculator.CalculatorService/Sum", func(c *recording.Call) {
+				c.Method = "/calculator.CalculatorService/Product"
+			}),
+			wantFail: "service calculator.CalculatorService has no method Product",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &replayer{cc: cc, speed: tt.speed, timeout: 5 * time.Second, ignore: map[protoreflect.Name]bool{}}
+			for _, name := range tt.ignore {
+				r.ignore[name] = true
+			}
+			outcomes := r.replayAll(context.Background(), tt.calls)
+
+			var out bytes.Buffer
+			ok := writeOutcomes(&out, outcomes, true)
+			if tt.wantFail != "" {
+				if ok || !strings.Contains(out.String(), tt.wantFail) {
+					t.Errorf("output = %v, want a call failing with %v", out.String(), tt.wantFail)
+				}
+				return
+			}
+			for i, o := range outcomes {
+				if o.err != nil {
+					t.Fatalf("%v could not be replayed: %v", o.call.Method, o.err)
+				}
+				got := strings.Join(o.diffs, "\n")
+				if (tt.wantDiffs[i] == "") != (got == "") || !strings.Contains(got, tt.wantDiffs[i]) {
+					t.Errorf("%v diffs = %q, want %q", o.call.Method, got, tt.wantDiffs[i])
+				}
+			}
+			if wantOK := strings.Join(tt.wantDiffs, "") == ""; ok != wantOK {
+				t.Errorf("writeOutcomes() = %v, want %v, output:\n%v", ok, wantOK, out.String())
+			}
+		})
+	}
+}
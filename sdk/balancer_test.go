@@ -0,0 +1,104 @@
+package sdk_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/calculator/calcclient"
+	"github.com/angel/golang_api_microservice/calculator/calculatorpb"
+	"github.com/angel/golang_api_microservice/internal/bootstrap"
+	"github.com/angel/golang_api_microservice/internal/servertest"
+	"github.com/angel/golang_api_microservice/sdk"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// namedCalculator answers Sum with its ID, to tell the backends apart
+type namedCalculator struct {
+	calculatorpb.UnimplementedCalculatorServiceServer
+	id int32
+}
+
+func (c namedCalculator) Sum(context.Context, *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	return &calculatorpb.SumResponse{SumResult: c.id}, nil
+}
+
+// backendsHit makes n calls and returns how many each backend answered
+func backendsHit(t *testing.T, c calculatorpb.CalculatorServiceClient, n int) map[int32]int {
+	t.Helper()
+	hits := map[int32]int{}
+	for i := 0; i < n; i++ {
+		res, err := c.Sum(context.Background(), &calculatorpb.SumRequest{})
+		if err != nil {
+			t.Fatalf("Sum() error = %v", err)
+		}
+		hits[res.GetSumResult()]++
+	}
+	return hits
+}
+
+// waitForHits calls the backends until ok accepts what a round of calls hit
+func waitForHits(t *testing.T, c calculatorpb.CalculatorServiceClient, what string, ok func(hits map[int32]int) bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		hits := backendsHit(t, c, 20)
+		if ok(hits) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("calls hit %v, want %v", hits, what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHealthCheckEjection(t *testing.T) {
+	for _, balancer := range []string{sdk.RoundRobin, sdk.LeastRequest} {
+		t.Run(balancer, func(t *testing.T) {
+			backends := map[string]*servertest.Server{}
+			for i, addr := range []string{"backend1", "backend2"} {
+				calc := namedCalculator{id: int32(i + 1)}
+				backends[addr] = servertest.Start(t, bootstrap.Config{}, func(s *bootstrap.Server) {
+					calculatorpb.RegisterCalculatorServiceServer(s.GRPC, calc)
+				})
+			}
+			dialer := grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				b, ok := backends[addr]
+				if !ok {
+					return nil, fmt.Errorf("unknown backend %v", addr)
+				}
+				return b.DialContext(ctx)
+			})
+			cc, err := sdk.Dial("static:///backend1,backend2", []string{calcclient.ServiceName}, sdk.WithBalancer(balancer), sdk.WithDialOptions(dialer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { cc.Close() })
+			c := calculatorpb.NewCalculatorServiceClient(cc)
+
+			both := func(hits map[int32]int) bool { return hits[1] > 0 && hits[2] > 0 }
+			waitForHits(t, c, "both backends", both)
+
+			// a backend that stops serving, e.g. while it drains, gets no new calls
+			backends["backend2"].Health.SetServingStatus(calcclient.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+			waitForHits(t, c, "only backend1", func(hits map[int32]int) bool { return hits[2] == 0 })
+			if hits := backendsHit(t, c, 50); hits[2] > 0 {
+				t.Errorf("calls hit %v after backend2 stopped serving, want only backend1", hits)
+			}
+
+			// and gets them again once it is back
+			backends["backend2"].Health.SetServingStatus(calcclient.ServiceName, healthpb.HealthCheckResponse_SERVING)
+			waitForHits(t, c, "both backends", both)
+		})
+	}
+}
+
+func TestDialUnknownBalancer(t *testing.T) {
+	if _, err := sdk.Dial("static:///backend1", []string{calcclient.ServiceName}, sdk.WithBalancer("random")); err == nil {
+		t.Error("Dial() with an unknown balancer succeeded")
+	}
+}
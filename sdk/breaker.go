@@ -0,0 +1,189 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerPolicy says when the circuit breaker of a method opens. Every method of a
+// connection has its own breaker:
+//   - closed, calls go through, and Failures of them in a row failing open it
+//   - open, calls fail fast with ErrCircuitOpen for OpenFor
+//   - half-open, one trial call goes through, closing the breaker if it succeeds and
+//     opening it again if it fails
+//
+// Calls fail when their code says the server is in trouble: Unavailable,
+// DeadlineExceeded, Internal, Unknown or DataLoss. A failed call is counted once,
+// after the retries of the retry policy
+type BreakerPolicy struct {
+	// Failures is how many calls in a row open the breaker, 0 turns it off
+	Failures int
+	OpenFor  time.Duration
+}
+
+// DefaultBreakerPolicy opens after 5 failures in a row, for 5s
+var DefaultBreakerPolicy = BreakerPolicy{
+	Failures: 5,
+	OpenFor:  5 * time.Second,
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker is the circuit breaker of one method
+type breaker struct {
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	// openedAt is when the breaker opened, or when the half-open trial started
+	openedAt time.Time
+}
+
+// breakers holds the breakers of the methods of a connection
+type breakers struct {
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu sync.Mutex
+	m  map[string]*breaker
+}
+
+func newBreakers(policy BreakerPolicy) *breakers {
+	return &breakers{policy: policy, now: time.Now, m: map[string]*breaker{}}
+}
+
+func (bs *breakers) get(method string) *breaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.m[method]
+	if !ok {
+		b = &breaker{}
+		bs.m[method] = b
+	}
+	return b
+}
+
+// allow says whether a call of method can go, and if it is the half-open trial.
+// A trial that never reports back, e.g. an abandoned stream, is replaced after OpenFor
+func (bs *breakers) allow(method string) (ok, trial bool, err error) {
+	b := bs.get(method)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := bs.now()
+	if b.state == breakerClosed {
+		return true, false, nil
+	}
+	if wait := b.openedAt.Add(bs.policy.OpenFor).Sub(now); wait > 0 {
+		return false, false, &Error{
+			Code:    codes.Unavailable,
+			Message: fmt.Sprintf("circuit breaker open for %v, next try in %v", method, wait.Round(time.Millisecond)),
+			open:    true,
+		}
+	}
+	if b.state == breakerOpen {
+		slog.Info("circuit breaker half-open, sending a trial call", "method", method)
+	}
+	b.state = breakerHalfOpen
+	b.openedAt = now
+	return true, true, nil
+}
+
+// done records the outcome of a call let through by allow
+func (bs *breakers) done(method string, trial bool, err error) {
+	b := bs.get(method)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := isFailure(err)
+	switch {
+	case trial && b.state == breakerHalfOpen && failed:
+		b.state = breakerOpen
+		b.openedAt = bs.now()
+		slog.Warn("circuit breaker trial call failed, open again", "method", method, "open_for", bs.policy.OpenFor.String(), "error", err)
+	case trial && b.state == breakerHalfOpen:
+		b.state = breakerClosed
+		b.failures = 0
+		slog.Info("circuit breaker closed", "method", method)
+	case b.state != breakerClosed:
+		// a call started before the breaker opened, the trial decides
+	case failed:
+		b.failures++
+		if b.failures >= bs.policy.Failures {
+			b.state = breakerOpen
+			b.openedAt = bs.now()
+			slog.Warn("circuit breaker open", "method", method, "failures", b.failures, "open_for", bs.policy.OpenFor.String(), "error", err)
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+// isFailure says whether err means the server is in trouble, rather than the call being wrong
+func isFailure(err error) bool {
+	if err == nil || err == io.EOF {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+func (bs *breakers) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ok, trial, err := bs.allow(method)
+	if !ok {
+		return err
+	}
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	bs.done(method, trial, err)
+	return err
+}
+
+func (bs *breakers) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ok, trial, err := bs.allow(method)
+	if !ok {
+		return nil, err
+	}
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		bs.done(method, trial, err)
+		return nil, err
+	}
+	return &breakerStream{
+		ClientStream: stream,
+		single:       !desc.ServerStreams,
+		report:       func(err error) { bs.done(method, trial, err) },
+	}, nil
+}
+
+// breakerStream reports the outcome of a stream when it ends: on the error ending it,
+// io.EOF on success, or on the one response of a client stream
+type breakerStream struct {
+	grpc.ClientStream
+	single bool
+	once   sync.Once
+	report func(err error)
+}
+
+func (s *breakerStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || s.single {
+		s.once.Do(func() { s.report(err) })
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/angel/golang_api_microservice/internal/clock/clocktest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreaker(t *testing.T) {
+	const method = "/calculator.CalculatorService/Sum"
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	clock := clocktest.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	bs := newBreakers(BreakerPolicy{Failures: 3, OpenFor: 5 * time.Second})
+	bs.now = clock.Now
+
+	// call makes one call through the breaker, failing with err
+	call := func(err error) error {
+		t.Helper()
+		ok, trial, openErr := bs.allow(method)
+		if !ok {
+			return openErr
+		}
+		bs.done(method, trial, err)
+		return err
+	}
+	wantState := func(want breakerState) {
+		t.Helper()
+		if got := bs.get(method).state; got != want {
+			t.Fatalf("breaker state = %v, want %v", got, want)
+		}
+	}
+
+	// closed: failures in a row open it, successes and errors of the caller, which say
+	// the server is answering, reset the count
+	call(unavailable)
+	call(unavailable)
+	call(nil)
+	call(unavailable)
+	call(status.Error(codes.InvalidArgument, "bad request"))
+	call(unavailable)
+	call(unavailable)
+	wantState(breakerClosed)
+	call(unavailable)
+	wantState(breakerOpen)
+
+	// open: calls fail fast until OpenFor has passed
+	clock.Advance(4 * time.Second)
+	if err := call(nil); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("call on an open breaker error = %v, want ErrCircuitOpen", err)
+	}
+	wantState(breakerOpen)
+
+	// half-open: one trial goes through, the others still fail fast while it runs
+	clock.Advance(time.Second)
+	ok, trial, _ := bs.allow(method)
+	if !ok || !trial {
+		t.Fatalf("allow() after OpenFor = %v, trial %v, want the trial call", ok, trial)
+	}
+	wantState(breakerHalfOpen)
+	if ok, _, err := bs.allow(method); ok || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() during the trial = %v, %v, want ErrCircuitOpen", ok, err)
+	}
+
+	// a failed trial opens it again for OpenFor
+	bs.done(method, true, unavailable)
+	wantState(breakerOpen)
+	clock.Advance(4 * time.Second)
+	if err := call(nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call after a failed trial error = %v, want ErrCircuitOpen", err)
+	}
+
+	// a trial that never reports back is replaced after OpenFor
+	clock.Advance(time.Second)
+	if ok, trial, _ := bs.allow(method); !ok || !trial {
+		t.Fatalf("allow() = %v, trial %v, want a trial", ok, trial)
+	}
+	clock.Advance(5 * time.Second)
+	ok, trial, _ = bs.allow(method)
+	if !ok || !trial {
+		t.Fatalf("allow() after a lost trial = %v, trial %v, want a new trial", ok, trial)
+	}
+
+	// calls started before the breaker opened don't decide, the trial does
+	bs.done(method, false, nil)
+	wantState(breakerHalfOpen)
+
+	// a successful trial closes it, with the failure count reset
+	bs.done(method, true, nil)
+	wantState(breakerClosed)
+	call(unavailable)
+	call(unavailable)
+	wantState(breakerClosed)
+
+	// each method has its own breaker
+	if ok, _, _ := bs.allow("/calculator.CalculatorService/SquareRoot"); !ok {
+		t.Error("the breaker of another method is not closed")
+	}
+}
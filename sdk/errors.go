@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is a failed call. It carries the gRPC status, so status.FromError keeps working,
+// and matches the Err sentinels of its code with errors.Is:
+//
+//	if errors.Is(err, sdk.ErrNotFound) { ... }
+type Error struct {
+	Code    codes.Code
+	Message string
+
+	status *status.Status
+	// open is set on the errors of calls an open circuit breaker stopped
+	open bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// GRPCStatus returns the status the server sent
+func (e *Error) GRPCStatus() *status.Status {
+	if e.status == nil {
+		return status.New(e.Code, e.Message)
+	}
+	return e.status
+}
+
+// Is matches the sentinel error of the same code, and ErrCircuitOpen
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if ok && t.open {
+		return e.open
+	}
+	return ok && t.status == nil && t.Message == "" && t.Code == e.Code
+}
+
+// Sentinels for the codes callers usually handle, for use with errors.Is
+var (
+	ErrCanceled           = &Error{Code: codes.Canceled}
+	ErrInvalidArgument    = &Error{Code: codes.InvalidArgument}
+	ErrDeadlineExceeded   = &Error{Code: codes.DeadlineExceeded}
+	ErrNotFound           = &Error{Code: codes.NotFound}
+	ErrAlreadyExists      = &Error{Code: codes.AlreadyExists}
+	ErrPermissionDenied   = &Error{Code: codes.PermissionDenied}
+	ErrResourceExhausted  = &Error{Code: codes.ResourceExhausted}
+	ErrFailedPrecondition = &Error{Code: codes.FailedPrecondition}
+	ErrUnimplemented      = &Error{Code: codes.Unimplemented}
+	ErrInternal           = &Error{Code: codes.Internal}
+	ErrUnavailable        = &Error{Code: codes.Unavailable}
+	ErrUnauthenticated    = &Error{Code: codes.Unauthenticated}
+
+	// ErrCircuitOpen is a call an open circuit breaker stopped, it also matches ErrUnavailable
+	ErrCircuitOpen = &Error{Code: codes.Unavailable, Message: "circuit breaker open", open: true}
+)
+
+// FromError turns the error of a gRPC call into an *Error. nil, io.EOF and
+// errors that do not come from gRPC are returned as they are
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		st := status.FromContextError(err)
+		return &Error{Code: st.Code(), Message: st.Message(), status: st}
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return &Error{Code: st.Code(), Message: st.Message(), status: st}
+}
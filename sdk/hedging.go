@@ -0,0 +1,213 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// HedgingPolicy says which unary methods are hedged: when the first attempt of a call
+// has not answered after the Percentile latency of its method, a second attempt is
+// sent, the first success wins and the other attempt is canceled.
+//
+// Both attempts can reach the server, so only list idempotent methods, e.g.
+// calcclient.IdempotentMethods. Methods are not hedged until minLatencySamples of their
+// calls succeeded, the delay is unknown before that
+type HedgingPolicy struct {
+	// Methods are full method names, e.g. /calculator.CalculatorService/Sum
+	Methods []string
+	// Percentile of the recent latencies of the method after which the second attempt
+	// is sent, between 50 and 100
+	Percentile float64
+	// MinDelay is the shortest wait before the second attempt, so that fast methods do
+	// not double their load over tiny latency differences
+	MinDelay time.Duration
+}
+
+// DefaultHedgingPolicy hedges no method, hedged methods get the second attempt after the
+// 95th percentile latency, and no sooner than 10ms
+var DefaultHedgingPolicy = HedgingPolicy{
+	Percentile: 95,
+	MinDelay:   10 * time.Millisecond,
+}
+
+const (
+	// latencyWindow is how many of the latest latencies of a method are kept
+	latencyWindow = 100
+	// minLatencySamples is how many latencies a method needs before it is hedged
+	minLatencySamples = 20
+)
+
+// latencies keeps the latencies of the latest successful attempts of a method
+type latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencies) add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.samples) < latencyWindow {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % latencyWindow
+}
+
+// percentile returns the p-th percentile latency, false without enough samples
+func (l *latencies) percentile(p float64) (time.Duration, bool) {
+	l.mu.Lock()
+	sorted := slices.Clone(l.samples)
+	l.mu.Unlock()
+	if len(sorted) < minLatencySamples {
+		return 0, false
+	}
+	slices.Sort(sorted)
+	i := int(p / 100 * float64(len(sorted)-1))
+	return sorted[i], true
+}
+
+// hedger hedges the unary calls of a connection
+type hedger struct {
+	policy  HedgingPolicy
+	methods map[string]*latencies
+}
+
+func newHedger(policy HedgingPolicy) (*hedger, error) {
+	if policy.Percentile < 50 || policy.Percentile > 100 {
+		return nil, fmt.Errorf("hedging percentile must be between 50 and 100")
+	}
+	if policy.MinDelay < 0 {
+		return nil, fmt.Errorf("hedging min delay must not be negative")
+	}
+	h := &hedger{policy: policy, methods: map[string]*latencies{}}
+	for _, m := range policy.Methods {
+		h.methods[m] = &latencies{}
+	}
+	return h, nil
+}
+
+// attempt is the outcome of one attempt of a hedged call
+type attempt struct {
+	reply   proto.Message
+	header  metadata.MD
+	trailer metadata.MD
+	peer    peer.Peer
+	err     error
+}
+
+func (h *hedger) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	lat, ok := h.methods[method]
+	replyMsg, isProto := messageV2(reply)
+	if !ok || !isProto {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	delay, ok := lat.percentile(h.policy.Percentile)
+	if !ok {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			lat.add(time.Since(start))
+		}
+		return err
+	}
+	delay = max(delay, h.policy.MinDelay)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan *attempt, 2)
+	send := func() {
+		a := &attempt{reply: replyMsg.ProtoReflect().New().Interface()}
+		start := time.Now()
+		a.err = invoker(ctx, method, req, a.reply, cc, attemptOptions(a, opts)...)
+		if a.err == nil {
+			lat.add(time.Since(start))
+		}
+		results <- a
+	}
+
+	go send()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	pending := 1
+	var first *attempt
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			go send()
+			pending++
+			continue
+		case a := <-results:
+			pending--
+			if a.err == nil {
+				return a.win(replyMsg, opts)
+			}
+			if first == nil {
+				first = a
+			}
+		}
+	}
+	// every attempt failed, or the first one did before the delay, which is for the
+	// retry policy to handle
+	return first.win(replyMsg, opts)
+}
+
+// messageV2 returns v as a proto message, whether its generated code is old or new
+func messageV2(v interface{}) (proto.Message, bool) {
+	switch m := v.(type) {
+	case proto.Message:
+		return m, true
+	case protoadapt.MessageV1:
+		return protoadapt.MessageV2Of(m), true
+	}
+	return nil, false
+}
+
+// attemptOptions gives an attempt its own header, trailer and peer to write to, the
+// winner copies them into the ones of the caller
+func attemptOptions(a *attempt, opts []grpc.CallOption) []grpc.CallOption {
+	out := make([]grpc.CallOption, 0, len(opts))
+	for _, o := range opts {
+		switch o.(type) {
+		case grpc.HeaderCallOption:
+			out = append(out, grpc.Header(&a.header))
+		case grpc.TrailerCallOption:
+			out = append(out, grpc.Trailer(&a.trailer))
+		case grpc.PeerCallOption:
+			out = append(out, grpc.Peer(&a.peer))
+		default:
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// win copies the outcome of a into the reply and call options of the caller
+func (a *attempt) win(reply proto.Message, opts []grpc.CallOption) error {
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = a.header
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = a.trailer
+		case grpc.PeerCallOption:
+			*o.PeerAddr = a.peer
+		}
+	}
+	if a.err != nil {
+		return a.err
+	}
+	proto.Reset(reply)
+	proto.Merge(reply, a.reply)
+	return nil
+}
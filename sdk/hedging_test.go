@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const hedgedMethod = "/calculator.CalculatorService/Sum"
+
+// warmHedger returns a hedger for hedgedMethod that already knows its latencies,
+// so calls get their second attempt after MinDelay
+func warmHedger(t *testing.T) *hedger {
+	t.Helper()
+	h, err := newHedger(HedgingPolicy{Methods: []string{hedgedMethod}, Percentile: 95, MinDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < minLatencySamples; i++ {
+		h.methods[hedgedMethod].add(time.Millisecond)
+	}
+	return h
+}
+
+func TestHedgeCancelsTheSlowAttempt(t *testing.T) {
+	h := warmHedger(t)
+
+	var attempts atomic.Int32
+	canceled := make(chan error, 1)
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if attempts.Add(1) == 1 {
+			// the first attempt hangs until the hedge wins
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		reply.(*wrapperspb.StringValue).Value = "second"
+		return nil
+	}
+
+	reply := &wrapperspb.StringValue{}
+	if err := h.unaryInterceptor(context.Background(), hedgedMethod, &wrapperspb.StringValue{}, reply, nil, invoker); err != nil {
+		t.Fatalf("hedged call error = %v", err)
+	}
+	if reply.GetValue() != "second" {
+		t.Errorf("reply = %q, want the answer of the second attempt", reply.GetValue())
+	}
+	select {
+	case err := <-canceled:
+		if err != context.Canceled {
+			t.Errorf("slow attempt ended with %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slow attempt was not canceled")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("%v attempts, want 2", n)
+	}
+}
+
+func TestHedgeCanceledByTheCaller(t *testing.T) {
+	h := warmHedger(t)
+
+	started := make(chan struct{}, 2)
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		// cancel once both attempts are in flight
+		<-started
+		<-started
+		cancel()
+	}()
+	err := h.unaryInterceptor(ctx, hedgedMethod, &wrapperspb.StringValue{}, &wrapperspb.StringValue{}, nil, invoker)
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("hedged call error = %v, want Canceled", err)
+	}
+}
+
+func TestHedgeNeedsLatencies(t *testing.T) {
+	h, err := newHedger(HedgingPolicy{Methods: []string{hedgedMethod}, Percentile: 95, MinDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts atomic.Int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}
+	for _, method := range []string{hedgedMethod, "/calculator.CalculatorService/PrimeNumberDecomposition"} {
+		if err := h.unaryInterceptor(context.Background(), method, &wrapperspb.StringValue{}, &wrapperspb.StringValue{}, nil, invoker); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("%v attempts for two calls, want no hedging before the latencies are known or for other methods", n)
+	}
+}
@@ -0,0 +1,201 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// DefaultRefreshInterval is how often file and srv targets are looked up again
+const DefaultRefreshInterval = 10 * time.Second
+
+// resolvers are the target schemes Dial adds to the ones gRPC knows (dns, unix, passthrough):
+//
+//	static:///host1:50053,host2:50053   a fixed list of backends
+//	file:///etc/calculator/endpoints    one backend per line, # starts a comment, read again every refresh
+//	srv:///_grpc._tcp.calculator.local  the targets of the DNS SRV records, looked up again every refresh
+func resolvers(refresh time.Duration) []resolver.Builder {
+	return []resolver.Builder{
+		staticBuilder{},
+		pollBuilder{scheme: "file", refresh: refresh, lookup: lookupFile},
+		pollBuilder{scheme: "srv", refresh: refresh, lookup: lookupSRV},
+	}
+}
+
+// targetPath is what follows the scheme, the path of file:///etc/x or the name of srv:///x
+func targetPath(t resolver.Target) string {
+	if t.URL.Opaque != "" {
+		return t.URL.Opaque
+	}
+	if t.URL.Host == "" && strings.HasPrefix(t.URL.Path, "/") && t.URL.Scheme != "file" {
+		return t.URL.Path[1:]
+	}
+	return t.URL.Path
+}
+
+// endpoints makes one endpoint per address, which the balancer picks from
+func endpoints(addrs []string) resolver.State {
+	state := resolver.State{}
+	for _, addr := range addrs {
+		state.Endpoints = append(state.Endpoints, resolver.Endpoint{Addresses: []resolver.Address{{Addr: addr}}})
+	}
+	return state
+}
+
+// splitAddresses splits on commas, spaces and new lines, dropping # comments
+func splitAddresses(s string) []string {
+	addrs := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		addrs = append(addrs, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})...)
+	}
+	return addrs
+}
+
+type staticBuilder struct{}
+
+func (staticBuilder) Scheme() string { return "static" }
+
+func (staticBuilder) Build(t resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	addrs := splitAddresses(targetPath(t))
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("static target %q has no addresses", t.URL.String())
+	}
+	if err := cc.UpdateState(endpoints(addrs)); err != nil {
+		return nil, err
+	}
+	return staticResolver{}, nil
+}
+
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+func (staticResolver) Close()                                {}
+
+// pollBuilder builds resolvers that look their target up every refresh, and when gRPC
+// asks after a backend goes away
+type pollBuilder struct {
+	scheme  string
+	refresh time.Duration
+	lookup  func(ctx context.Context, path string) ([]string, error)
+}
+
+func (b pollBuilder) Scheme() string { return b.scheme }
+
+func (b pollBuilder) Build(t resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	path := targetPath(t)
+	if path == "" {
+		return nil, fmt.Errorf("%v target %q has no path", b.scheme, t.URL.String())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &pollResolver{
+		cc:      cc,
+		lookup:  func(ctx context.Context) ([]string, error) { return b.lookup(ctx, path) },
+		refresh: b.refresh,
+		now:     make(chan struct{}, 1),
+		cancel:  cancel,
+	}
+	r.wg.Add(1)
+	go r.run(ctx)
+	return r, nil
+}
+
+type pollResolver struct {
+	cc      resolver.ClientConn
+	lookup  func(ctx context.Context) ([]string, error)
+	refresh time.Duration
+	now     chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// minResolveGap keeps gRPC asking for a new lookup every time a connection fails from
+// hammering the file system or the DNS server
+const minResolveGap = time.Second
+
+func (r *pollResolver) run(ctx context.Context) {
+	defer r.wg.Done()
+	t := time.NewTicker(r.refresh)
+	defer t.Stop()
+
+	var last []string
+	for {
+		lookupCtx, cancel := context.WithTimeout(ctx, r.refresh)
+		addrs, err := r.lookup(lookupCtx)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == nil && len(addrs) == 0:
+			err = fmt.Errorf("no addresses found")
+			fallthrough
+		case err != nil:
+			// keep the backends already known, a failed lookup does not mean they are gone
+			if last == nil {
+				r.cc.ReportError(err)
+			}
+		case !slices.Equal(addrs, last):
+			last = addrs
+			r.cc.UpdateState(endpoints(addrs))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-r.now:
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(minResolveGap):
+			}
+		}
+	}
+}
+
+func (r *pollResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *pollResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func lookupFile(_ context.Context, path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	addrs := splitAddresses(string(data))
+	slices.Sort(addrs)
+	return addrs, nil
+}
+
+func lookupSRV(ctx context.Context, name string) ([]string, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(records))
+	for _, rec := range records {
+		addrs = append(addrs, net.JoinHostPort(strings.TrimSuffix(rec.Target, "."), strconv.Itoa(int(rec.Port))))
+	}
+	slices.Sort(addrs)
+	return addrs, nil
+}
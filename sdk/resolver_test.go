@@ -0,0 +1,198 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+func TestSplitAddresses(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"host1:50053,host2:50053", []string{"host1:50053", "host2:50053"}},
+		{"host1:50053, host2:50053\thost3:50053", []string{"host1:50053", "host2:50053", "host3:50053"}},
+		{"# backends\nhost1:50053\r\n\nhost2:50053 # the new one\n", []string{"host1:50053", "host2:50053"}},
+		{"#host1:50053", []string{}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		if got := splitAddresses(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitAddresses(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTargetPath(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"static:///host1:50053,host2:50053", "host1:50053,host2:50053"},
+		{"static:host1:50053", "host1:50053"},
+		{"file:///etc/calculator/endpoints", "/etc/calculator/endpoints"},
+		{"file:endpoints", "endpoints"},
+		{"srv:///_grpc._tcp.calculator.local", "_grpc._tcp.calculator.local"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := targetPath(resolver.Target{URL: *u}); got != tt.want {
+			t.Errorf("targetPath(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+// fakeClientConn records what a resolver reports
+type fakeClientConn struct {
+	resolver.ClientConn
+
+	mu     sync.Mutex
+	events []string
+	// updated gets every address list sent with UpdateState
+	updated chan []string
+}
+
+func newFakeClientConn() *fakeClientConn {
+	return &fakeClientConn{updated: make(chan []string, 10)}
+}
+
+func (cc *fakeClientConn) UpdateState(s resolver.State) error {
+	var addrs []string
+	for _, e := range s.Endpoints {
+		for _, a := range e.Addresses {
+			addrs = append(addrs, a.Addr)
+		}
+	}
+	cc.mu.Lock()
+	cc.events = append(cc.events, "update "+strings.Join(addrs, ","))
+	cc.mu.Unlock()
+	cc.updated <- addrs
+	return nil
+}
+
+func (cc *fakeClientConn) ReportError(err error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.events = append(cc.events, "error "+err.Error())
+}
+
+// waitFor waits until the resolver sends want
+func (cc *fakeClientConn) waitFor(t *testing.T, want ...string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-cc.updated:
+			if slices.Equal(got, want) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("the resolver did not send %v", want)
+		}
+	}
+}
+
+func build(t *testing.T, b resolver.Builder, target string, cc resolver.ClientConn) {
+	t.Helper()
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := b.Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+}
+
+func TestStaticResolver(t *testing.T) {
+	cc := newFakeClientConn()
+	build(t, staticBuilder{}, "static:///host1:50053,host2:50053", cc)
+	cc.waitFor(t, "host1:50053", "host2:50053")
+
+	if _, err := (staticBuilder{}).Build(resolver.Target{URL: url.URL{Scheme: "static", Path: "/"}}, cc, resolver.BuildOptions{}); err == nil {
+		t.Error("Build() of a target without addresses succeeded")
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("# calculators\nhost2:50053\nhost1:50053\n")
+
+	cc := newFakeClientConn()
+	refresh := 10 * time.Millisecond
+	build(t, pollBuilder{scheme: "file", refresh: refresh, lookup: lookupFile}, "file://"+path, cc)
+	cc.waitFor(t, "host1:50053", "host2:50053")
+
+	// the edit is picked up by the next refresh
+	write("host1:50053\nhost3:50053\n")
+	start := time.Now()
+	cc.waitFor(t, "host1:50053", "host3:50053")
+	if d := time.Since(start); d > 50*refresh {
+		t.Errorf("the edit was seen after %v, want about one refresh of %v", d, refresh)
+	}
+}
+
+func TestPollResolverKeepsTheLastAddresses(t *testing.T) {
+	lookupErr := errors.New("lookup failed")
+	type result struct {
+		addrs []string
+		err   error
+	}
+	// every lookup takes the next result, the last one is repeated
+	results := []result{
+		{err: lookupErr},
+		{addrs: []string{}},
+		{addrs: []string{"host1:50053", "host2:50053"}},
+		{err: lookupErr},
+		{addrs: []string{}},
+		{addrs: []string{"host1:50053", "host2:50053"}},
+		{addrs: []string{"host3:50053"}},
+	}
+	var mu sync.Mutex
+	lookup := func(context.Context, string) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		r := results[0]
+		if len(results) > 1 {
+			results = results[1:]
+		}
+		return r.addrs, r.err
+	}
+
+	cc := newFakeClientConn()
+	build(t, pollBuilder{scheme: "srv", refresh: time.Millisecond, lookup: lookup}, "srv:///calculator", cc)
+	cc.waitFor(t, "host3:50053")
+
+	// errors are only reported while no address is known, and the same list is not sent twice
+	want := []string{
+		"error lookup failed",
+		"error no addresses found",
+		"update host1:50053,host2:50053",
+		"update host3:50053",
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if !slices.Equal(cc.events, want) {
+		t.Errorf("resolver events = %q, want %q", cc.events, want)
+	}
+}
@@ -0,0 +1,341 @@
+// Package sdk holds what the Go clients of the services (greetclient, calcclient and
+// blogclient) share: dialing with default deadlines and retries, and the errors they return.
+//
+// Deadlines and retries are set in the gRPC service config of the connection, so they
+// apply to every client sharing it. Unary calls get DefaultTimeout unless the context
+// already has an earlier deadline. Calls failing with Unavailable are retried with
+// exponential backoff, streams only until their first response arrives, but only for
+// the methods with an idempotency_level in their .proto: a retried write could be
+// applied twice.
+//
+// Every method also has a circuit breaker failing calls fast while its server is in
+// trouble, see BreakerPolicy, and idempotent unary methods can be hedged against slow
+// backends, see HedgingPolicy.
+//
+// A target can name several backends, see resolvers for the schemes. Calls are spread
+// over them with round robin by default, and backends whose health service stops
+// reporting SERVING, e.g. while they drain, get no new calls until it does again
+package sdk
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/health" // registers the client side health checks
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DefaultTimeout is the deadline of unary calls made without one
+const DefaultTimeout = 10 * time.Second
+
+// RetryPolicy says how calls failing with Unavailable are retried, for the methods
+// marked NO_SIDE_EFFECTS or IDEMPOTENT in their .proto
+type RetryPolicy struct {
+	// MaxAttempts counts the first call, gRPC caps it at 5. 1 turns retries off
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, each retry waits Multiplier
+	// times longer up to MaxBackoff. gRPC randomizes every wait between 0 and that value
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy retries up to 3 times, waiting up to 0.1s, 0.2s and 0.4s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+// Balancers Dial can spread calls over the backends of a target with
+const (
+	// PickFirst sends every call to the first backend that connects
+	PickFirst = "pick_first"
+	// RoundRobin sends calls to every backend in turn
+	RoundRobin = "round_robin"
+	// LeastRequest sends calls to the backend with the fewest in flight out of two
+	// picked at random, better for uneven calls such as long streams
+	LeastRequest = "least_request"
+)
+
+// balancerConfigs are the gRPC load balancing configs of the balancers
+var balancerConfigs = map[string]interface{}{
+	PickFirst:    map[string]interface{}{"pick_first": map[string]interface{}{}},
+	RoundRobin:   map[string]interface{}{"round_robin": map[string]interface{}{}},
+	LeastRequest: map[string]interface{}{leastrequest.Name: map[string]interface{}{"choiceCount": 2}},
+}
+
+type options struct {
+	timeout     time.Duration
+	retry       RetryPolicy
+	breaker     BreakerPolicy
+	hedging     HedgingPolicy
+	balancer    string
+	healthCheck bool
+	refresh     time.Duration
+	transport   grpc.DialOption
+	dialOptions []grpc.DialOption
+}
+
+// Option configures Dial
+type Option func(*options)
+
+// WithTimeout sets the deadline of unary calls made without one, 0 turns it off
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithBreakerPolicy replaces DefaultBreakerPolicy
+func WithBreakerPolicy(p BreakerPolicy) Option {
+	return func(o *options) {
+		o.breaker = p
+	}
+}
+
+// WithHedging hedges the methods of p, see HedgingPolicy. Zero Percentile and MinDelay
+// are taken from DefaultHedgingPolicy
+func WithHedging(p HedgingPolicy) Option {
+	return func(o *options) {
+		if p.Percentile == 0 {
+			p.Percentile = DefaultHedgingPolicy.Percentile
+		}
+		if p.MinDelay == 0 {
+			p.MinDelay = DefaultHedgingPolicy.MinDelay
+		}
+		o.hedging = p
+	}
+}
+
+// WithBalancer sets how calls are spread over the backends, RoundRobin by default
+func WithBalancer(name string) Option {
+	return func(o *options) {
+		o.balancer = name
+	}
+}
+
+// WithHealthCheck turns the checks of the backends health services on or off, on by default
+func WithHealthCheck(enabled bool) Option {
+	return func(o *options) {
+		o.healthCheck = enabled
+	}
+}
+
+// WithRefreshInterval sets how often file and srv targets are looked up again,
+// DefaultRefreshInterval by default
+func WithRefreshInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.refresh = d
+	}
+}
+
+// WithTLS connects over TLS, the connection is plain text by default
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.transport = grpc.WithTransportCredentials(credentials.NewTLS(cfg))
+	}
+}
+
+// WithTransport sets the transport credentials dial option, e.g. from tlsutil.ClientConfig
+func WithTransport(transport grpc.DialOption) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithPerRPCCredentials sends creds with every call, e.g. a JWT or an API key
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, grpc.WithPerRPCCredentials(creds))
+	}
+}
+
+// WithDialOptions adds raw gRPC dial options, e.g. interceptors or tracing
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// Dial connects to target for services, given by full name, e.g. greet.GreetService.
+// The connection can be shared by the clients of all of them, and is closed by the caller.
+//
+// Besides host:port and the gRPC schemes such as dns:///host:port, target can be
+// static:///host1:port,host2:port, file:///path or srv:///name, see resolvers
+func Dial(target string, services []string, opts ...Option) (*grpc.ClientConn, error) {
+	o := options{
+		timeout:     DefaultTimeout,
+		retry:       DefaultRetryPolicy,
+		breaker:     DefaultBreakerPolicy,
+		hedging:     DefaultHedgingPolicy,
+		balancer:    RoundRobin,
+		healthCheck: true,
+		refresh:     DefaultRefreshInterval,
+		transport:   grpc.WithInsecure(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.refresh <= 0 {
+		return nil, fmt.Errorf("refresh interval must be positive")
+	}
+
+	sc, err := serviceConfig(services, o)
+	if err != nil {
+		return nil, err
+	}
+	dialOptions := []grpc.DialOption{
+		o.transport,
+		grpc.WithDefaultServiceConfig(sc),
+		grpc.WithResolvers(resolvers(o.refresh)...),
+	}
+
+	// the breaker goes first, so it sees the outcome of the call and not of each attempt
+	if o.breaker.Failures > 0 {
+		if o.breaker.OpenFor <= 0 {
+			return nil, fmt.Errorf("circuit breaker open duration must be positive")
+		}
+		bs := newBreakers(o.breaker)
+		dialOptions = append(dialOptions,
+			grpc.WithChainUnaryInterceptor(bs.unaryInterceptor),
+			grpc.WithChainStreamInterceptor(bs.streamInterceptor))
+	}
+	if len(o.hedging.Methods) > 0 {
+		h, err := newHedger(o.hedging)
+		if err != nil {
+			return nil, err
+		}
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(h.unaryInterceptor))
+	}
+	return grpc.Dial(target, append(dialOptions, o.dialOptions...)...)
+}
+
+// the JSON form of the gRPC service config, see
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type healthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
+
+// serviceConfig puts the unary methods of services in method configs with the timeout
+// and the streams in others without it, a deadline would cut long streams off. Only the
+// configs of idempotent methods have the retry policy.
+// Health checks watch the status of the service when there is one, else the overall one
+func serviceConfig(services []string, o options) (string, error) {
+	lb, ok := balancerConfigs[o.balancer]
+	if !ok {
+		return "", fmt.Errorf("unknown balancer %q, must be %v, %v or %v", o.balancer, PickFirst, RoundRobin, LeastRequest)
+	}
+	sc := map[string]interface{}{"loadBalancingConfig": []interface{}{lb}}
+	if o.healthCheck {
+		hc := healthCheckConfig{}
+		if len(services) == 1 {
+			hc.ServiceName = services[0]
+		}
+		sc["healthCheckConfig"] = hc
+	}
+
+	var policy *retryPolicy
+	if o.retry.MaxAttempts > 1 {
+		if o.retry.InitialBackoff <= 0 || o.retry.MaxBackoff <= 0 || o.retry.Multiplier <= 0 {
+			return "", fmt.Errorf("retry backoffs and multiplier must be positive")
+		}
+		policy = &retryPolicy{
+			MaxAttempts:          o.retry.MaxAttempts,
+			InitialBackoff:       seconds(o.retry.InitialBackoff),
+			MaxBackoff:           seconds(o.retry.MaxBackoff),
+			BackoffMultiplier:    o.retry.Multiplier,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+
+	unary := methodConfig{RetryPolicy: policy}
+	unaryWrites := methodConfig{}
+	if o.timeout > 0 {
+		unary.Timeout = seconds(o.timeout)
+		unaryWrites.Timeout = unary.Timeout
+	}
+	// streams that aren't idempotent have nothing to configure
+	streams := methodConfig{RetryPolicy: policy}
+	for _, service := range services {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+		if err != nil {
+			return "", fmt.Errorf("cannot find service %v: %v", service, err)
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return "", fmt.Errorf("%v is not a service", service)
+		}
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			m := methods.Get(i)
+			name := methodName{Service: service, Method: string(m.Name())}
+			switch {
+			case m.IsStreamingClient() || m.IsStreamingServer():
+				if idempotent(m) {
+					streams.Name = append(streams.Name, name)
+				}
+			case idempotent(m):
+				unary.Name = append(unary.Name, name)
+			default:
+				unaryWrites.Name = append(unaryWrites.Name, name)
+			}
+		}
+	}
+
+	configs := []methodConfig{}
+	for _, mc := range []methodConfig{unary, unaryWrites, streams} {
+		if len(mc.Name) > 0 && (mc.Timeout != "" || mc.RetryPolicy != nil) {
+			configs = append(configs, mc)
+		}
+	}
+	sc["methodConfig"] = configs
+	data, err := json.Marshal(sc)
+	return string(data), err
+}
+
+// idempotent reports whether m has an idempotency_level, NO_SIDE_EFFECTS or IDEMPOTENT
+func idempotent(m protoreflect.MethodDescriptor) bool {
+	opts, ok := m.Options().(*descriptorpb.MethodOptions)
+	return ok && opts.GetIdempotencyLevel() != descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN
+}
+
+// seconds formats d the way the service config expects durations, e.g. 0.1s
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	_ "github.com/angel/golang_api_microservice/blog/blogpb" // registers the blog descriptors
+	_ "github.com/angel/golang_api_microservice/calculator/calculatorpb"
+)
+
+// parsedServiceConfig is the part of the service config the tests look at
+type parsedServiceConfig struct {
+	LoadBalancingConfig []map[string]json.RawMessage `json:"loadBalancingConfig"`
+	MethodConfig        []methodConfig               `json:"methodConfig"`
+}
+
+// methodConfigs returns the method config of every method of sc by its name
+func methodConfigs(t *testing.T, sc string) map[string]methodConfig {
+	t.Helper()
+	var parsed parsedServiceConfig
+	if err := json.Unmarshal([]byte(sc), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	configs := map[string]methodConfig{}
+	for _, mc := range parsed.MethodConfig {
+		for _, name := range mc.Name {
+			if _, ok := configs[name.Method]; ok {
+				t.Errorf("%v is in two method configs", name.Method)
+			}
+			configs[name.Method] = mc
+		}
+	}
+	return configs
+}
+
+func TestServiceConfigRetries(t *testing.T) {
+	o := options{timeout: DefaultTimeout, retry: DefaultRetryPolicy, balancer: RoundRobin}
+	sc, err := serviceConfig([]string{"blog.BlogService", "calculator.CalculatorService"}, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs := methodConfigs(t, sc)
+
+	tests := []struct {
+		method      string
+		wantTimeout bool
+		wantRetry   bool
+	}{
+		{"ReadBlog", true, true},
+		{"Sum", true, true},
+		{"SquareRoot", true, true},
+		// the writes are never retried, the server may have committed them
+		{"CreateBlog", true, false},
+		{"UpdateBlog", true, false},
+		{"DeleteBlog", true, false},
+		{"ApproveContent", true, false},
+		{"RejectContent", true, false},
+		// streams have no deadline
+		{"ListBlogs", false, true},
+		{"ListModerationQueue", false, true},
+		{"PrimeNumberDecomposition", false, true},
+		{"FindMaximum", false, true},
+	}
+	for _, tt := range tests {
+		mc, ok := configs[tt.method]
+		if !ok {
+			t.Errorf("%v has no method config", tt.method)
+			continue
+		}
+		if (mc.Timeout != "") != tt.wantTimeout {
+			t.Errorf("%v timeout = %q, want a timeout %v", tt.method, mc.Timeout, tt.wantTimeout)
+		}
+		if (mc.RetryPolicy != nil) != tt.wantRetry {
+			t.Errorf("%v retry policy = %+v, want a retry policy %v", tt.method, mc.RetryPolicy, tt.wantRetry)
+		}
+		if tt.wantRetry && (mc.RetryPolicy.MaxAttempts != 4 || len(mc.RetryPolicy.RetryableStatusCodes) != 1 || mc.RetryPolicy.RetryableStatusCodes[0] != "UNAVAILABLE") {
+			t.Errorf("%v retry policy = %+v, want 4 attempts on UNAVAILABLE", tt.method, mc.RetryPolicy)
+		}
+	}
+}
+
+func TestServiceConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     options
+		wantLB   string
+		wantErr  bool
+		wantNone bool
+	}{
+		{name: "round robin", opts: options{timeout: time.Second, retry: DefaultRetryPolicy, balancer: RoundRobin}, wantLB: `{"round_robin":{}}`},
+		{name: "least request", opts: options{timeout: time.Second, retry: DefaultRetryPolicy, balancer: LeastRequest}, wantLB: `{"least_request_experimental":{"choiceCount":2}}`},
+		{name: "no timeout or retries", opts: options{retry: RetryPolicy{MaxAttempts: 1}, balancer: PickFirst}, wantLB: `{"pick_first":{}}`, wantNone: true},
+		{name: "unknown balancer", opts: options{balancer: "random"}, wantErr: true},
+		{name: "invalid retry policy", opts: options{retry: RetryPolicy{MaxAttempts: 3}, balancer: RoundRobin}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := serviceConfig([]string{"calculator.CalculatorService"}, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("serviceConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var parsed parsedServiceConfig
+			if err := json.Unmarshal([]byte(sc), &parsed); err != nil {
+				t.Fatal(err)
+			}
+			lb, _ := json.Marshal(parsed.LoadBalancingConfig[0])
+			if string(lb) != tt.wantLB {
+				t.Errorf("loadBalancingConfig = %s, want %s", lb, tt.wantLB)
+			}
+			if tt.wantNone && len(parsed.MethodConfig) != 0 {
+				t.Errorf("methodConfig = %+v, want none", parsed.MethodConfig)
+			}
+		})
+	}
+}